@@ -0,0 +1,21 @@
+package main
+
+import (
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/validator"
+)
+
+// updateBaseline records the findings of a validation in the baseline of the package, as requested
+// by -update-baseline, and accepts them for the rest of the run
+func updateBaseline(path string, result *validator.ValidationResult) error {
+	baseline := validator.NewBaseline(result)
+	if err := baseline.Save(path); err != nil {
+		return err
+	}
+	accepted, _ := baseline.Filter(result)
+	result.Stats["baselined"] = accepted
+	result.Stats["errors"] = len(result.Errors)
+	result.Stats["warnings"] = len(result.Warnings)
+	logger.Success("Recorded %d findings in %s", len(baseline.Findings), path)
+	return nil
+}
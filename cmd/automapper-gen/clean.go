@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+)
+
+// runClean implements the clean command, deleting the files generated by automapper-gen in packages
+func runClean(args []string) error {
+	flags := flag.NewFlagSet("clean", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "List the generated files without deleting them")
+	flags.Usage = func() {
+		fmt.Println("Usage: automapper-gen clean [options] <package-path>... (a path ending in /... includes subdirectories)")
+		fmt.Println("\nOptions:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	pkgPaths := flags.Args()
+	if len(pkgPaths) == 0 {
+		pkgPaths = []string{"."}
+	}
+
+	removed := 0
+	for _, arg := range pkgPaths {
+		root, recursive := strings.CutSuffix(arg, "...")
+		root = strings.TrimSuffix(root, "/")
+		if root == "" {
+			root = "."
+		}
+
+		files, err := parser.FindGeneratedFiles(root, recursive)
+		if err != nil {
+			return fmt.Errorf("searching %s: %w", arg, err)
+		}
+		for _, file := range files {
+			if *dryRun {
+				logger.Info("Would remove %s", file)
+				continue
+			}
+			if err := os.Remove(file); err != nil {
+				return err
+			}
+			logger.Info("Removed %s", file)
+			removed++
+		}
+	}
+
+	if !*dryRun {
+		logger.Success("Removed %d generated files", removed)
+	}
+	return nil
+}
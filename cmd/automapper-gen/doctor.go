@@ -0,0 +1,247 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/generator"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+)
+
+// doctor collects the outcome of the checks of the doctor command
+type doctor struct {
+	failures int
+	warnings int
+}
+
+// ok reports a passing check
+func (d *doctor) ok(format string, args ...any) {
+	fmt.Printf("  ok    %s\n", fmt.Sprintf(format, args...))
+}
+
+// warn reports a check that passes with a caveat
+func (d *doctor) warn(fix, format string, args ...any) {
+	d.warnings++
+	fmt.Printf("  warn  %s\n", fmt.Sprintf(format, args...))
+	fmt.Printf("        fix: %s\n", fix)
+}
+
+// fail reports a failing check with the way to fix it
+func (d *doctor) fail(fix, format string, args ...any) {
+	d.failures++
+	fmt.Printf("  FAIL  %s\n", fmt.Sprintf(format, args...))
+	fmt.Printf("        fix: %s\n", fix)
+}
+
+// runDoctor implements the doctor command, checking the environment and the configuration of a package
+// and printing how to fix the problems found
+func runDoctor(args []string) error {
+	pkgPath := "."
+	if len(args) > 0 {
+		pkgPath = args[0]
+	}
+	d := &doctor{}
+
+	fmt.Println("Go environment")
+	d.checkGoEnv(pkgPath)
+
+	fmt.Println("\nConfiguration")
+	cfg := d.checkConfig(pkgPath)
+	if cfg == nil {
+		return d.result()
+	}
+	d.checkOutput(pkgPath, cfg)
+
+	if len(cfg.ExternalPackages) > 0 {
+		fmt.Println("\nExternal packages")
+		d.checkExternalPackages(pkgPath, cfg)
+	}
+
+	fmt.Println("\nPackage")
+	d.checkPackage(pkgPath, cfg)
+
+	return d.result()
+}
+
+// result summarizes the checks, failing when any of them failed
+func (d *doctor) result() error {
+	fmt.Println()
+	if d.failures > 0 {
+		return fmt.Errorf("%d checks failed, %d warnings", d.failures, d.warnings)
+	}
+	fmt.Printf("All checks passed, %d warnings\n", d.warnings)
+	return nil
+}
+
+// checkGoEnv checks the go command is available and runs in module mode
+func (d *doctor) checkGoEnv(pkgPath string) {
+	cmd := exec.Command("go", "env", "GOVERSION", "GOMOD", "GO111MODULE", "GOFLAGS")
+	cmd.Dir = pkgPath
+	out, err := cmd.Output()
+	if err != nil {
+		d.fail("install Go from https://go.dev/dl and make sure go is on the PATH", "go env failed: %v", err)
+		return
+	}
+
+	values := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	for len(values) < 4 {
+		values = append(values, "")
+	}
+	goVersion, goMod, goModule, goFlags := values[0], values[1], values[2], values[3]
+	d.ok("go %s", strings.TrimPrefix(goVersion, "go"))
+
+	switch {
+	case goModule == "off":
+		d.fail("unset GO111MODULE or set it to on, packages are only resolved in module mode", "GO111MODULE=off disables module mode")
+	case goMod == "" || goMod == os.DevNull:
+		d.fail("run go mod init in the root of the project", "%s is not inside a Go module", pkgPath)
+	default:
+		d.ok("module %s", goMod)
+	}
+
+	switch {
+	case strings.Contains(goFlags, "-mod=vendor"):
+		d.warn("run go mod vendor after adding external packages, or unset GOFLAGS",
+			"GOFLAGS=%s loads external packages from the vendor directory only", goFlags)
+	case goFlags != "":
+		d.ok("GOFLAGS=%s", goFlags)
+	}
+}
+
+// checkConfig checks the configuration of the package parses, returning it
+func (d *doctor) checkConfig(pkgPath string) *config.Config {
+	cfgPath := configPath(pkgPath)
+
+	cfg, err := config.Load(cfgPath)
+	if errors.Is(err, fs.ErrNotExist) && *configFile == "" {
+		if directives, _ := parser.FindConfigDirectives(pkgPath); len(directives) > 0 {
+			cfg, err := config.FromDirectives(directives)
+			if err != nil {
+				d.fail("correct the automapper-config directives of the package doc comment", "%v", err)
+				return nil
+			}
+			d.ok("automapper-config directives parse")
+			return cfg
+		}
+		d.warn("run automapper-gen init to write a starter configuration", "no %s, the default configuration is used", cfgPath)
+		return config.Default()
+	}
+	if err != nil {
+		d.fail(fmt.Sprintf("correct %s, see the Configuration Reference of the README", cfgPath), "%v", err)
+		return nil
+	}
+	d.ok("%s parses", cfgPath)
+	if *profile != "" {
+		if cfg, err = cfg.WithProfile(*profile); err != nil {
+			d.fail("correct the profile or pass one the configuration declares", "%v", err)
+			return nil
+		}
+		d.ok("profile %s applies", *profile)
+	}
+	if err := cfg.CheckVersion(generator.Version); err != nil {
+		d.fail("install a version of automapper-gen the configuration requires, or update requiresVersion", "%v", err)
+	}
+	return cfg
+}
+
+// checkOutput checks the output files can be written
+func (d *doctor) checkOutput(pkgPath string, cfg *config.Config) {
+	for _, output := range cfg.OutputFiles() {
+		d.checkOutputFile(filepath.Join(pkgPath, output))
+	}
+}
+
+// checkOutputFile checks an output file can be written
+func (d *doctor) checkOutputFile(outputPath string) {
+	if info, err := os.Stat(outputPath); err == nil {
+		if info.IsDir() {
+			d.fail("point output to a file", "output %s is a directory", outputPath)
+			return
+		}
+		file, err := os.OpenFile(outputPath, os.O_WRONLY, 0)
+		if err != nil {
+			d.fail(fmt.Sprintf("make %s writable", outputPath), "output %s can't be written: %v", outputPath, err)
+			return
+		}
+		file.Close()
+		d.ok("output %s is writable", outputPath)
+		return
+	}
+
+	dir := filepath.Dir(outputPath)
+	probe, err := os.CreateTemp(dir, ".automapper-gen-doctor-*")
+	if err != nil {
+		d.fail(fmt.Sprintf("create %s or make it writable", dir), "output %s can't be created: %v", outputPath, err)
+		return
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	d.ok("output %s can be created", outputPath)
+}
+
+// checkExternalPackages checks every external package resolves
+func (d *doctor) checkExternalPackages(pkgPath string, cfg *config.Config) {
+	for _, extPkg := range cfg.ExternalPackages {
+		if extPkg.LocalPath != "" {
+			localPath := extPkg.LocalPath
+			if !filepath.IsAbs(localPath) {
+				localPath = filepath.Join(pkgPath, localPath)
+			}
+			if _, err := parser.ResolvePackage(localPath, ".", cfg); err != nil {
+				d.warn("correct localPath or remove it to use the module cache",
+					"local path %s of %s doesn't load: %v", extPkg.LocalPath, extPkg.ImportPath, err)
+			} else {
+				d.ok("%s loads from %s", extPkg.ImportPath, extPkg.LocalPath)
+				continue
+			}
+		}
+
+		name, err := parser.ResolvePackage(pkgPath, extPkg.ImportPath, cfg)
+		if err != nil {
+			d.fail(fmt.Sprintf("run go get %s, or correct importPath", extPkg.ImportPath),
+				"%s doesn't resolve: %v", extPkg.ImportPath, err)
+			continue
+		}
+		d.ok("%s resolves (package %s)", extPkg.ImportPath, name)
+	}
+}
+
+// checkPackage checks the package parses and its converters have supported signatures
+func (d *doctor) checkPackage(pkgPath string, cfg *config.Config) {
+	dtos, _, functions, _, err := parser.ParsePackage(pkgPath, cfg, openCache(pkgPath))
+	if err != nil {
+		d.fail("fix the compile errors of the package, go vet lists them", "package doesn't parse: %v", err)
+		return
+	}
+	if len(dtos) == 0 {
+		d.warn("annotate DTOs with //automapper:from=Source", "no annotated DTOs found")
+	} else {
+		d.ok("%d annotated DTOs", len(dtos))
+	}
+
+	check := func(role, name, function string) {
+		fn, ok := functions[function]
+		switch {
+		case !ok:
+			d.fail(fmt.Sprintf("declare func %s in the package or correct the converter", function),
+				"%s %s of converter %s not found", role, function, name)
+		case parser.IsSafeConverterSignature(fn) || parser.IsErrorReturningConverterSignature(fn):
+			d.ok("%s %s of converter %s", role, function, name)
+		default:
+			d.fail(fmt.Sprintf("change %s to func(T) U or func(T) (U, error)", function),
+				"%s %s of converter %s has an unsupported signature", role, function, name)
+		}
+	}
+	for _, conv := range cfg.Converters {
+		check("function", conv.Name, conv.Function)
+		if conv.Inverter != "" {
+			check("inverter", conv.Name, conv.Inverter)
+		}
+	}
+}
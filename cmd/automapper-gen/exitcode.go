@@ -0,0 +1,40 @@
+package main
+
+import "errors"
+
+// Exit codes of the generation, so scripts can tell the classes of failures apart
+const (
+	exitFailure    = 1 // usage errors and failures of the other commands
+	exitConfig     = 2 // the configuration doesn't load
+	exitParse      = 3 // the package doesn't parse
+	exitValidation = 4 // validation errors, or warnings in strict mode
+	exitWrite      = 5 // the code can't be generated or written
+)
+
+// exitError is a failure ending the run with a given exit code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode classifies an error with the exit code it ends the run with
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// exitCode returns the exit code of an error, exitFailure for unclassified ones
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitFailure
+}
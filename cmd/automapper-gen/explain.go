@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/generator"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+)
+
+// runExplain implements the explain command, printing how the fields of a DTO are mapped
+// without generating code
+func runExplain(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: automapper-gen explain <DTO> [package-path]")
+	}
+
+	dtoName := args[0]
+	pkgPath := "."
+	if len(args) > 1 {
+		pkgPath = args[1]
+	}
+
+	cfg, err := loadConfig(pkgPath)
+	if err != nil {
+		return err
+	}
+
+	dtos, sources, functions, _, err := parser.ParsePackage(pkgPath, cfg, openCache(pkgPath))
+	if err != nil {
+		return fmt.Errorf("parsing package: %w", err)
+	}
+
+	index := slices.IndexFunc(dtos, func(dto types.DTOMapping) bool { return dto.Name == dtoName })
+	if index < 0 {
+		names := make([]string, len(dtos))
+		for i, dto := range dtos {
+			names[i] = dto.Name
+		}
+		return fmt.Errorf("DTO %s not found in %s, available: %s", dtoName, pkgPath, strings.Join(names, ", "))
+	}
+
+	lines, err := generator.Explain(dtos[index], dtos, sources, cfg, functions)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+)
+
+// packageJob is a package to generate
+type packageJob struct {
+	path   string
+	cfg    *config.Config // nil to load the configuration of the package
+	parent string         // package generating it as an external package, if any
+}
+
+// externalJobs returns the jobs generating the external packages a package marks generate, those
+// already queued left out. A package without a configuration of its own is generated with the
+// configuration of the package, itself left out of the external packages.
+func externalJobs(pkgPath string, cfg *config.Config, queued []packageJob) ([]packageJob, error) {
+	var jobs []packageJob
+	for i, extPkg := range cfg.ExternalPackages {
+		if !extPkg.Generate {
+			continue
+		}
+		dir := parser.LocalPackageDir(pkgPath, extPkg)
+		if dir == "" {
+			return jobs, fmt.Errorf("externalPackages[%d]: %s has no local copy to generate into, set its localPath", i, extPkg.ImportPath)
+		}
+		if isQueued(dir, queued) || isQueued(dir, jobs) {
+			continue
+		}
+
+		job := packageJob{path: dir, parent: pkgPath}
+		if !hasOwnConfig(dir) {
+			job.cfg = cfg.ForExternal(extPkg.ImportPath)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// isQueued reports whether a directory is the package of one of the jobs
+func isQueued(dir string, jobs []packageJob) bool {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for _, job := range jobs {
+		if jobAbs, err := filepath.Abs(job.path); err == nil && jobAbs == abs {
+			return true
+		}
+	}
+	return false
+}
+
+// hasOwnConfig reports whether a package is configured by a file or automapper-config directives,
+// or by the configuration given with -config
+func hasOwnConfig(dir string) bool {
+	if *configFile != "" || parser.FindConfigFile(dir) != "" {
+		return true
+	}
+	directives, _ := parser.FindConfigDirectives(dir)
+	return len(directives) > 0
+}
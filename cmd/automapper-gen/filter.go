@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+)
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// selectDTOs keeps the DTOs named with -only or declared in the files given with -file, along with
+// the DTOs their mappings call: nested DTOs, type cases, via DTOs and sources that are DTOs
+func selectDTOs(pkgPath string, dtos []types.DTOMapping, names, files []string) ([]types.DTOMapping, error) {
+	byName := make(map[string]types.DTOMapping)
+	for _, dto := range dtos {
+		byName[dto.Name] = dto
+	}
+
+	for _, name := range names {
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("DTO %s not found, available: %s", name, strings.Join(dtoNames(dtos), ", "))
+		}
+	}
+
+	selected := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		dto, ok := byName[name]
+		if !ok || selected[name] {
+			return
+		}
+		selected[name] = true
+		for _, source := range dto.Sources {
+			visit(source)
+		}
+		for _, field := range dto.Fields {
+			visit(field.NestedDTO)
+			for _, typeCase := range field.TypeCases {
+				visit(typeCase.DTO)
+			}
+		}
+	}
+
+	for _, name := range names {
+		visit(name)
+	}
+	for _, file := range files {
+		found := false
+		for _, dto := range dtos {
+			if declaredIn(pkgPath, dto, file) {
+				visit(dto.Name)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no DTOs declared in %s", file)
+		}
+	}
+
+	var kept []types.DTOMapping
+	for _, dto := range dtos {
+		if selected[dto.Name] {
+			kept = append(kept, dto)
+		}
+	}
+	return kept, nil
+}
+
+// declaredIn reports whether a DTO is declared in a file, given by name or by path relative to the package
+func declaredIn(pkgPath string, dto types.DTOMapping, file string) bool {
+	if !dto.Position.IsValid() {
+		return false
+	}
+	if filepath.Base(dto.Position.File) == file {
+		return true
+	}
+	path, err := filepath.Abs(filepath.Join(pkgPath, file))
+	return err == nil && path == dto.Position.File
+}
+
+// dtoNames lists the names of DTOs
+func dtoNames(dtos []types.DTOMapping) []string {
+	names := make([]string, len(dtos))
+	for i, dto := range dtos {
+		names[i] = dto.Name
+	}
+	return names
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+)
+
+// runGoGenerate runs the go:generate directives of automapper-gen found in the given directories,
+// each in the directory of its file with its inline flags, like go generate would. It returns the
+// number of directives that failed.
+func runGoGenerate(args []string) (int, error) {
+	var directives []parser.GenerateDirective
+	for _, arg := range args {
+		root, recursive := strings.CutSuffix(arg, "...")
+		root = strings.TrimSuffix(root, "/")
+		if root == "" {
+			root = "."
+		}
+
+		found, err := parser.FindGenerateDirectives(root, recursive)
+		if err != nil {
+			return 0, fmt.Errorf("searching %s: %w", arg, err)
+		}
+		directives = append(directives, found...)
+	}
+	if len(directives) == 0 {
+		logger.Warning("No //go:generate automapper-gen directives found")
+		return 0, nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("locating automapper-gen: %w", err)
+	}
+
+	failed := 0
+	for i, directive := range directives {
+		logger.Section(fmt.Sprintf("[%d/%d] %s:%d", i+1, len(directives), directive.File, directive.Line))
+		if err := runDirective(executable, directive); err != nil {
+			logger.Error("%s:%d: %v", directive.File, directive.Line, err)
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+// runDirective runs a go:generate directive in the directory of its file, with the variables
+// of go generate set and expanded in its arguments
+func runDirective(executable string, directive parser.GenerateDirective) error {
+	if slices.ContainsFunc(directive.Args, isGoGenerateFlag) {
+		return fmt.Errorf("directives can't use -go-generate themselves")
+	}
+
+	vars := map[string]string{
+		"GOFILE":    filepath.Base(directive.File),
+		"GOLINE":    strconv.Itoa(directive.Line),
+		"GOPACKAGE": directive.Package,
+		"GOARCH":    runtime.GOARCH,
+		"GOOS":      runtime.GOOS,
+		"DOLLAR":    "$",
+	}
+	lookup := func(name string) string {
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return os.Getenv(name)
+	}
+
+	args := make([]string, len(directive.Args))
+	for i, arg := range directive.Args {
+		args[i] = os.Expand(arg, lookup)
+	}
+	logger.Info("automapper-gen %s", strings.Join(args, " "))
+
+	cmd := exec.Command(executable, args...)
+	cmd.Dir = filepath.Dir(directive.File)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	for name, value := range vars {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+	return cmd.Run()
+}
+
+// isGoGenerateFlag reports whether an argument is the -go-generate flag, which would run the directives again
+func isGoGenerateFlag(arg string) bool {
+	name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+	return strings.HasPrefix(arg, "-") && name == "go-generate"
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/graph"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+)
+
+// runGraph implements the graph command, printing the DTOs of a package with their sources,
+// nested DTOs and converters as a DOT or Mermaid graph
+func runGraph(args []string) error {
+	flags := flag.NewFlagSet("graph", flag.ExitOnError)
+	format := flags.String("format", graph.FormatDOT, "Graph format: dot or mermaid")
+	flags.Usage = func() {
+		fmt.Println("Usage: automapper-gen graph [options] [package-path]")
+		fmt.Println("\nOptions:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	pkgPath := "."
+	if flags.NArg() > 0 {
+		pkgPath = flags.Arg(0)
+	}
+
+	cfg, err := loadConfig(pkgPath)
+	if err != nil {
+		return err
+	}
+
+	dtos, _, _, _, err := parser.ParsePackage(pkgPath, cfg, openCache(pkgPath))
+	if err != nil {
+		return fmt.Errorf("parsing package: %w", err)
+	}
+
+	rendered, err := graph.Build(dtos, cfg).Render(*format)
+	if err != nil {
+		return err
+	}
+	fmt.Print(rendered)
+	return nil
+}
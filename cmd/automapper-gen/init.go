@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+)
+
+// starterConfig is the configuration written by init, limited to the fields worth editing first
+type starterConfig struct {
+	Output           string                   `json:"output"`
+	Converters       []config.ConverterDef    `json:"converters"`
+	ExternalPackages []starterExternalPackage `json:"externalPackages,omitempty"`
+}
+
+// starterExternalPackage is an external package of the starter configuration
+type starterExternalPackage struct {
+	Alias      string `json:"alias"`
+	ImportPath string `json:"importPath"`
+}
+
+// runInit implements the init command, writing a starter configuration for a package
+func runInit(args []string) error {
+	flags := flag.NewFlagSet("init", flag.ExitOnError)
+	interactive := flags.Bool("interactive", false, "Ask which packages to map from and the output file")
+	overwrite := flags.Bool("force", false, "Overwrite an existing configuration")
+	flags.Usage = func() {
+		fmt.Println("Usage: automapper-gen init [options] [package-path]")
+		fmt.Println("\nOptions:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	pkgPath := "."
+	if flags.NArg() > 0 {
+		pkgPath = flags.Arg(0)
+	}
+
+	cfgPath := configPath(pkgPath)
+	if _, err := os.Stat(cfgPath); err == nil && !*overwrite {
+		return fmt.Errorf("%s already exists, pass -force to overwrite it", cfgPath)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	logger.Info("Inspecting package: %s", pkgPath)
+	local, module, err := parser.FindSourceCandidates(pkgPath)
+	if err != nil {
+		return fmt.Errorf("inspecting package: %w", err)
+	}
+
+	if len(local.Structs) > 0 {
+		logger.Info("Structs of %s: %s", local.Name, strings.Join(local.Structs, ", "))
+	}
+	if len(module) == 0 {
+		logger.Info("No other package of the module declares structs")
+	}
+
+	cfg := starterConfig{Output: "automappers.go", Converters: []config.ConverterDef{}}
+	prompt := newPrompter(os.Stdin, os.Stdout, *interactive)
+
+	// The first candidate source illustrates the annotation to add
+	example := "Source"
+	if len(local.Structs) > 0 {
+		example = local.Structs[0]
+	}
+
+	// Packages already imported are selected by default, the others are only offered
+	for _, candidates := range module {
+		logger.Info("Candidate sources in %s: %s", candidates.ImportPath, strings.Join(candidates.Structs, ", "))
+		if !prompt.confirm(fmt.Sprintf("Map from %s", candidates.ImportPath), candidates.Imported) {
+			continue
+		}
+		if len(cfg.ExternalPackages) == 0 {
+			example = candidates.Name + "." + candidates.Structs[0]
+		}
+		cfg.ExternalPackages = append(cfg.ExternalPackages, starterExternalPackage{
+			Alias:      candidates.Name,
+			ImportPath: candidates.ImportPath,
+		})
+	}
+	cfg.Output = prompt.ask("Output file", cfg.Output)
+
+	data, err := json.MarshalIndent(cfg, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(cfgPath, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", cfgPath, err)
+	}
+
+	logger.Success("Wrote %s", cfgPath)
+	logger.Info("Annotate your DTOs with //automapper:from=%s, then run: automapper-gen %s", example, pkgPath)
+	return nil
+}
+
+// prompter asks the questions of the interactive init, answering with the defaults otherwise
+type prompter struct {
+	in          *bufio.Reader
+	out         io.Writer
+	interactive bool
+}
+
+// newPrompter creates a prompter reading the answers from in
+func newPrompter(in io.Reader, out io.Writer, interactive bool) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out, interactive: interactive}
+}
+
+// readAnswer prints a question and reads the trimmed answer
+func (p *prompter) readAnswer(question string) string {
+	fmt.Fprint(p.out, question)
+	answer, _ := p.in.ReadString('\n')
+	return strings.TrimSpace(answer)
+}
+
+// ask returns the answer to a question, or the default for an empty answer
+func (p *prompter) ask(question, def string) string {
+	if !p.interactive {
+		return def
+	}
+	if answer := p.readAnswer(fmt.Sprintf("%s [%s]: ", question, def)); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// confirm returns the answer to a yes or no question, or the default for an empty answer
+func (p *prompter) confirm(question string, def bool) bool {
+	if !p.interactive {
+		return def
+	}
+
+	choices := "y/N"
+	if def {
+		choices = "Y/n"
+	}
+	switch strings.ToLower(p.readAnswer(fmt.Sprintf("%s? [%s]: ", question, choices))) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
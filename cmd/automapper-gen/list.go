@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+)
+
+// runList implements the list command, printing the DTOs of a package with their sources and
+// the converters and inverters of the configuration
+func runList(args []string) error {
+	pkgPath := "."
+	if len(args) > 0 {
+		pkgPath = args[0]
+	}
+
+	cfg, err := loadConfig(pkgPath)
+	if err != nil {
+		return err
+	}
+
+	dtos, _, functions, _, err := parser.ParsePackage(pkgPath, cfg, openCache(pkgPath))
+	if err != nil {
+		return fmt.Errorf("parsing package: %w", err)
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "DTOs (%d)\n", len(dtos))
+	if len(dtos) > 0 {
+		fmt.Fprintln(w, "  DTO\tSources\tBidirectional\tModes\tNotes")
+	}
+	for _, dto := range dtos {
+		bidirectional := "no"
+		if dto.HasMode(types.ModeBidirectional) {
+			bidirectional = "yes"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", dto.Name, orDash(strings.Join(dto.Sources, ", ")),
+			bidirectional, orDash(strings.Join(dto.Modes, ", ")), strings.Join(dtoListNotes(dto), "; "))
+	}
+
+	fmt.Fprintf(w, "\nConverters (%d)\n", len(cfg.Converters))
+	if len(cfg.Converters) > 0 {
+		fmt.Fprintln(w, "  Name\tFunction\tSignature\tInverter\tSignature")
+	}
+	for _, conv := range cfg.Converters {
+		inverter, inverterSignature := "-", "-"
+		if conv.Inverter != "" {
+			inverter, inverterSignature = conv.Inverter, describeConverter(conv.Inverter, functions)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", conv.Name, conv.Function,
+			describeConverter(conv.Function, functions), inverter, inverterSignature)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// Rows without notes end with the padding of the empty column
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+	return nil
+}
+
+// dtoListNotes lists the annotations of a DTO that change how it is mapped
+func dtoListNotes(dto types.DTOMapping) []string {
+	var notes []string
+	if len(dto.PrioritySources) > 1 {
+		notes = append(notes, "falls back through "+strings.Join(dto.PrioritySources, " > "))
+	}
+	if dto.Via != "" {
+		notes = append(notes, "via "+dto.Via)
+	}
+	if dto.Style != "" {
+		notes = append(notes, "style "+dto.Style)
+	}
+	if dto.Transform != "" {
+		notes = append(notes, "transform "+dto.Transform)
+	}
+	return notes
+}
+
+// describeConverter returns the signature of a converter function and whether it may fail
+func describeConverter(name string, functions map[string]types.FunctionInfo) string {
+	fn, ok := functions[name]
+	if !ok {
+		return "not found in the package"
+	}
+
+	signature := fmt.Sprintf("func(%s) %s", strings.Join(fn.ParamTypes, ", "), strings.Join(fn.ReturnTypes, ", "))
+	if len(fn.ReturnTypes) > 1 {
+		signature = fmt.Sprintf("func(%s) (%s)", strings.Join(fn.ParamTypes, ", "), strings.Join(fn.ReturnTypes, ", "))
+	}
+
+	switch {
+	case parser.IsSafeConverterSignature(fn):
+		return signature + ", cannot fail"
+	case parser.IsErrorReturningConverterSignature(fn):
+		return signature + ", may fail"
+	}
+	return signature + ", unsupported"
+}
+
+// orDash returns s, or a dash for an empty column
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -1,23 +1,53 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/cache"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/diff"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/generator"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/validator"
+	"github.com/dave/jennifer/jen"
 )
 
 var (
 	verbose      = flag.Bool("verbose", false, "Enable verbose logging")
+	verboseShort = flag.Bool("v", false, "Shorthand for -verbose")
 	debug        = flag.Bool("debug", false, "Enable debug logging")
+	debugShort   = flag.Bool("vv", false, "Shorthand for -debug")
+	quiet        = flag.Bool("quiet", false, "Only log errors")
+	quietShort   = flag.Bool("q", false, "Shorthand for -quiet")
+	noColor      = flag.Bool("no-color", false, "Disable colored output")
+	configFile   = flag.String("config", "", "Configuration file shared by the packages, instead of their own "+parser.ConfigFileName)
+	profile      = flag.String("profile", "", "Apply a profile of the configuration")
+	only         = flag.String("only", "", "Generate only the comma-separated DTOs and the DTOs they depend on")
+	onlyFiles    = flag.String("file", "", "Generate only the DTOs declared in the comma-separated files and their dependencies")
+	strict       = flag.Bool("strict", false, "Fail on validation warnings")
 	skipValidate = flag.Bool("skip-validation", false, "Skip validation phase (not recommended)")
+	force        = flag.Bool("force", false, "Rewrite the output even when its inputs are unchanged")
+	dryRun       = flag.Bool("dry-run", false, "Print a diff of the output changes instead of writing them")
+	cpuProfile   = flag.String("cpuprofile", "", "Write a CPU profile of the run to a file")
+	memProfile   = flag.String("memprofile", "", "Write a memory profile of the run to a file")
+	goGenerate   = flag.Bool("go-generate", false, "Run the //go:generate automapper-gen directives found in the packages instead")
+	noCache      = flag.Bool("no-cache", false, "Parse every package again instead of reusing the "+cache.DirName+" of the module")
+	output       = flag.String("output", "", "Output file overriding the configuration, - writes the code to the standard output")
+	color        = flag.String("color", "auto", "Color the dry-run diff: auto, always or never")
+	reportFormat = flag.String("report", "", "Write a machine-readable validation report: json")
+	reportFile   = flag.String("report-file", "", "Write the report to a file instead of the standard output")
+	rebaseline   = flag.Bool("update-baseline", false, "Record the current validation findings in "+validator.BaselineFileName+" of the packages")
 )
 
 func main() {
@@ -25,33 +55,256 @@ func main() {
 	args := flag.Args()
 
 	if len(args) < 1 {
-		fmt.Println("Usage: automapper-gen [options] <package-path>")
+		fmt.Println("Usage: automapper-gen [options] <package-path>... (a path ending in /... includes subdirectories)")
+		fmt.Println("       automapper-gen init [options] [package-path]")
+		fmt.Println("       automapper-gen explain <DTO> [package-path]")
+		fmt.Println("       automapper-gen list [package-path]")
+		fmt.Println("       automapper-gen graph [-format=dot|mermaid] [package-path]")
+		fmt.Println("       automapper-gen stats [-format=table|json] [package-path]")
+		fmt.Println("       automapper-gen doctor [package-path]")
+		fmt.Println("       automapper-gen clean [-dry-run] <package-path>...")
+		fmt.Println("       automapper-gen schema")
+		fmt.Println("       automapper-gen runtime [-name=package] <package-path>")
+		fmt.Println("       automapper-gen version")
 		fmt.Println("\nOptions:")
 		flag.PrintDefaults()
-		os.Exit(1)
+		exit(1)
 	}
 
 	// Configure logging
+	*verbose = *verbose || *verboseShort
+	*debug = *debug || *debugShort
+	*quiet = *quiet || *quietShort
+	if *quiet && (*verbose || *debug) {
+		fmt.Println("-quiet can't be combined with -verbose or -debug")
+		exit(1)
+	}
+
 	if *debug {
 		logger.SetLevel(logger.LogLevelDebug)
 	} else if *verbose {
 		logger.SetLevel(logger.LogLevelVerbose)
+	} else if *quiet {
+		logger.SetLevel(logger.LogLevelQuiet)
+	}
+	if *noColor {
+		logger.SetColors(false)
+	}
+
+	if err := startProfiles(*cpuProfile, *memProfile); err != nil {
+		logger.Error("%v", err)
+		exit(1)
+	}
+	defer stopProfiles()
+
+	switch args[0] {
+	case "init":
+		if err := runInit(args[1:]); err != nil {
+			logger.Error("Init failed: %v", err)
+			exit(1)
+		}
+		return
+	case "version":
+		runVersion()
+		return
+	case "list":
+		if err := runList(args[1:]); err != nil {
+			logger.Error("List failed: %v", err)
+			exit(1)
+		}
+		return
+	case "graph":
+		if err := runGraph(args[1:]); err != nil {
+			logger.Error("Graph failed: %v", err)
+			exit(1)
+		}
+		return
+	case "doctor":
+		if err := runDoctor(args[1:]); err != nil {
+			logger.Error("Doctor: %v", err)
+			exit(1)
+		}
+		return
+	case "clean":
+		if err := runClean(args[1:]); err != nil {
+			logger.Error("Clean failed: %v", err)
+			exit(1)
+		}
+		return
+	case "stats":
+		if err := runStats(args[1:]); err != nil {
+			logger.Error("Stats failed: %v", err)
+			exit(1)
+		}
+		return
+	case "runtime":
+		if err := runRuntime(args[1:]); err != nil {
+			logger.Error("Runtime failed: %v", err)
+			exit(1)
+		}
+		return
+	case "schema":
+		if err := runSchema(); err != nil {
+			logger.Error("Schema failed: %v", err)
+			exit(1)
+		}
+		return
+	case "explain":
+		if err := runExplain(args[1:]); err != nil {
+			logger.Error("Explain failed: %v", err)
+			exit(1)
+		}
+		return
 	}
 
-	pkgPath := args[0]
-	startTime := time.Now()
+	switch *color {
+	case "auto", "always", "never":
+	default:
+		fmt.Printf("Invalid -color value %q, expected auto, always or never\n", *color)
+		exit(1)
+	}
+
+	switch *reportFormat {
+	case "", "json":
+	default:
+		fmt.Printf("Invalid -report value %q, expected json\n", *reportFormat)
+		exit(1)
+	}
+	if *output == "-" && (*dryRun || (*reportFormat != "" && *reportFile == "")) {
+		fmt.Println("-output - can't be combined with -dry-run or a report on the standard output")
+		exit(1)
+	}
+
+	// The report or the generated code owns the standard output, the log moves to the standard error
+	if (*reportFormat != "" && *reportFile == "") || *output == "-" {
+		logger.SetOutput(os.Stderr)
+	}
 
-	logger.Section("automapper-gen v0.0.1 | MIT License | git.weirdcat.su/weirdcat/automapper-gen")
-	logger.Info("Package: %s", pkgPath)
+	logger.Section("automapper-gen " + generator.Version + " | MIT License | git.weirdcat.su/weirdcat/automapper-gen")
 	logger.Info("Verbose mode: %v", *verbose || *debug)
 
-	if err := run(pkgPath, startTime); err != nil {
-		logger.Error("Generation failed: %v", err)
-		os.Exit(1)
+	if *goGenerate {
+		failed, err := runGoGenerate(args)
+		if err != nil {
+			logger.Error("Finding go:generate directives failed: %v", err)
+			exit(1)
+		}
+		if failed > 0 {
+			logger.Error("%d go:generate directives failed", failed)
+			exit(1)
+		}
+		return
+	}
+
+	pkgPaths, err := expandPackagePaths(args)
+	if err != nil {
+		logger.Error("Finding packages failed: %v", err)
+		exit(1)
+	}
+	if len(pkgPaths) == 0 {
+		logger.Warning("No packages with %s or annotated DTOs found", parser.ConfigFileName)
+		if *reportFormat != "" {
+			if err := writeReport(*reportFormat, *reportFile, []packageReport{}); err != nil {
+				logger.Error("Writing report failed: %v", err)
+				exit(1)
+			}
+		}
+		return
+	}
+
+	jobs := make([]packageJob, len(pkgPaths))
+	for i, pkgPath := range pkgPaths {
+		jobs[i] = packageJob{path: pkgPath}
+	}
+
+	failed, code := 0, 0
+	var reports []packageReport
+	// External packages generated by a package are appended to the jobs as they are found
+	for i := 0; i < len(jobs); i++ {
+		job := jobs[i]
+		if len(jobs) > 1 {
+			logger.Section(fmt.Sprintf("[%d/%d] %s", i+1, len(jobs), job.path))
+		}
+		logger.Info("Package: %s", job.path)
+
+		report := newPackageReport(job.path)
+		cfg, err := run(job, time.Now(), report)
+		if err != nil {
+			logger.Error("Generation failed: %v", err)
+			report.Error = err.Error()
+			failed++
+			// The first failure decides the exit code
+			if code == 0 {
+				code = exitCode(err)
+			}
+		}
+		reports = append(reports, *report)
+
+		if cfg != nil {
+			externals, err := externalJobs(job.path, cfg, jobs)
+			if err != nil {
+				logger.Error("Generation failed: %v", err)
+				failed++
+				if code == 0 {
+					code = exitConfig
+				}
+			}
+			jobs = append(jobs, externals...)
+		}
+	}
+
+	if *reportFormat != "" {
+		if err := writeReport(*reportFormat, *reportFile, reports); err != nil {
+			logger.Error("Writing report failed: %v", err)
+			exit(1)
+		}
+	}
+
+	if failed > 0 {
+		if len(jobs) > 1 {
+			logger.Error("Generation failed for %d of %d packages", failed, len(jobs))
+		}
+		exit(code)
 	}
 }
 
-func run(pkgPath string, startTime time.Time) error {
+// expandPackagePaths resolves the package arguments: plain paths are kept as is, paths ending
+// in /... are replaced by every package below them that has a configuration or annotated DTOs
+func expandPackagePaths(args []string) ([]string, error) {
+	var pkgPaths []string
+	seen := make(map[string]bool)
+	add := func(pkgPath string) {
+		if clean := filepath.Clean(pkgPath); !seen[clean] {
+			seen[clean] = true
+			pkgPaths = append(pkgPaths, pkgPath)
+		}
+	}
+
+	for _, arg := range args {
+		root, recursive := strings.CutSuffix(arg, "...")
+		if !recursive {
+			add(arg)
+			continue
+		}
+
+		root = strings.TrimSuffix(root, "/")
+		if root == "" {
+			root = "."
+		}
+		found, err := parser.FindPackages(root)
+		if err != nil {
+			return nil, fmt.Errorf("searching %s: %w", arg, err)
+		}
+		logger.Verbose("Found %d packages in %s", len(found), arg)
+		for _, pkgPath := range found {
+			add(pkgPath)
+		}
+	}
+	return pkgPaths, nil
+}
+
+func run(job packageJob, startTime time.Time, report *packageReport) (*config.Config, error) {
+	pkgPath := job.path
 	totalSteps := 5
 	currentStep := 1
 
@@ -60,12 +313,23 @@ func run(pkgPath string, startTime time.Time) error {
 	currentStep++
 	stepStart := time.Now()
 
-	cfgPath := filepath.Join(pkgPath, "automapper.json")
-	logger.Verbose("Config file: %s", cfgPath)
-
-	cfg, err := config.Load(cfgPath)
+	cfg, err := job.cfg, error(nil)
+	if cfg == nil {
+		cfg, err = loadConfig(pkgPath)
+	} else {
+		logger.Verbose("No configuration of its own, generated with the configuration of %s", job.parent)
+	}
 	if err != nil {
-		return fmt.Errorf("loading config: %w", err)
+		return nil, withExitCode(exitConfig, err)
+	}
+
+	// The configured output stays the file skipped when parsing, even when the code goes to the standard output
+	toStdout := *output == "-"
+	if *output != "" && !toStdout {
+		cfg.Output = *output
+	}
+	if toStdout && (len(cfg.Outputs) > 0 || cfg.OutputPattern != "") {
+		return nil, withExitCode(exitConfig, errors.New("outputs split the code into several files, which can't go to the standard output"))
 	}
 
 	logger.Progress(stepStart, "Config loaded")
@@ -74,7 +338,7 @@ func run(pkgPath string, startTime time.Time) error {
 
 	if len(cfg.ExternalPackages) > 0 {
 		for _, pkg := range cfg.ExternalPackages {
-			logger.Verbose("  - %s (alias: %s)", pkg.ImportPath, pkg.Alias)
+			logger.Verbose("  - %s (alias: %s)", pkg.ImportPath, pkg.Name())
 		}
 	}
 
@@ -90,9 +354,9 @@ func run(pkgPath string, startTime time.Time) error {
 	currentStep++
 	stepStart = time.Now()
 
-	dtos, sources, functions, pkgName, err := parser.ParsePackage(pkgPath, cfg)
+	dtos, sources, functions, pkgName, err := parser.ParsePackage(pkgPath, cfg, openCache(pkgPath))
 	if err != nil {
-		return fmt.Errorf("parsing package: %w", err)
+		return nil, withExitCode(exitParse, fmt.Errorf("parsing package: %w", err))
 	}
 
 	logger.Progress(stepStart, "Parsing complete")
@@ -101,6 +365,31 @@ func run(pkgPath string, startTime time.Time) error {
 	logger.Verbose("Found %d source structs", len(sources))
 	logger.Verbose("Found %d functions", len(functions))
 
+	if len(cfg.DTOs) > 0 {
+		var selected []string
+		for _, dto := range dtos {
+			if cfg.SelectsDTO(dto.Name) {
+				selected = append(selected, dto.Name)
+			}
+		}
+		total := len(dtos)
+		dtos, err = selectDTOs(pkgPath, dtos, selected, nil)
+		if err != nil {
+			return nil, err
+		}
+		logger.Verbose("The configuration selects %d of %d DTOs", len(dtos), total)
+	}
+
+	if *only != "" || *onlyFiles != "" {
+		total := len(dtos)
+		dtos, err = selectDTOs(pkgPath, dtos, splitList(*only), splitList(*onlyFiles))
+		if err != nil {
+			return nil, err
+		}
+		logger.Warning("Generating %d of %d DTOs (%s), the output only holds their mappings",
+			len(dtos), total, strings.Join(dtoNames(dtos), ", "))
+	}
+
 	// List DTOs found
 	for _, dto := range dtos {
 		logger.Debug("DTO: %s (sources: %v, fields: %d)", dto.Name, dto.Sources, len(dto.Fields))
@@ -133,7 +422,16 @@ func run(pkgPath string, startTime time.Time) error {
 		logger.Info("      ID   int64")
 		logger.Info("      Name string")
 		logger.Info("  }")
-		return nil
+		return cfg, nil
+	}
+
+	// Strict mode turns missing source fields and pointer conversions into validation errors
+	cfg.Strict = cfg.Strict || *strict
+	if cfg.Strict && *skipValidate {
+		return nil, withExitCode(exitConfig, errors.New("-skip-validation can't be combined with strict mode"))
+	}
+	if *rebaseline && *skipValidate {
+		return nil, withExitCode(exitConfig, errors.New("-skip-validation can't be combined with -update-baseline"))
 	}
 
 	// Step 3: Validation
@@ -143,14 +441,32 @@ func run(pkgPath string, startTime time.Time) error {
 		stepStart = time.Now()
 
 		v := validator.NewValidator(cfg, dtos, sources, functions)
+		baselinePath := filepath.Join(pkgPath, validator.BaselineFileName)
+		if !*rebaseline {
+			baseline, err := validator.LoadBaseline(baselinePath)
+			if err != nil {
+				return nil, withExitCode(exitConfig, fmt.Errorf("loading baseline: %w", err))
+			}
+			v.SetBaseline(baseline)
+		}
 		validationResult := v.Validate()
+		if *rebaseline {
+			if err := updateBaseline(baselinePath, validationResult); err != nil {
+				return nil, withExitCode(exitWrite, fmt.Errorf("updating baseline: %w", err))
+			}
+		}
+		report.setResult(validationResult)
 
 		logger.Progress(stepStart, "Validation complete")
 
 		if !validationResult.IsValid() {
-			return fmt.Errorf("validation failed with %d errors", len(validationResult.Errors))
+			return nil, withExitCode(exitValidation, fmt.Errorf("validation failed with %d errors", len(validationResult.Errors)))
 		}
 
+		if cfg.Strict && len(validationResult.Warnings) > 0 {
+			return nil, withExitCode(exitValidation,
+				fmt.Errorf("validation failed with %d warnings in strict mode", len(validationResult.Warnings)))
+		}
 		if len(validationResult.Warnings) > 0 {
 			logger.Warning("Proceeding with %d warnings", len(validationResult.Warnings))
 		}
@@ -158,42 +474,268 @@ func run(pkgPath string, startTime time.Time) error {
 		logger.Warning("Skipping validation (not recommended)")
 	}
 
+	inputHash, err := generator.InputHash(cfg, dtos, sources, functions, pkgName)
+	if err != nil {
+		return nil, withExitCode(exitWrite, fmt.Errorf("hashing inputs: %w", err))
+	}
+	logger.Debug("Input hash: %s", inputHash)
+
+	// Outputs generated from the same inputs are left untouched, keeping their mtime stable
+	upToDate := !*force && !toStdout
+	for _, output := range cfg.OutputFiles() {
+		upToDate = upToDate && generator.ReadInputHash(filepath.Join(pkgPath, output)) == inputHash
+	}
+	if upToDate && len(cfg.OutputFiles()) == 1 {
+		logger.Success("%s is up to date, nothing to generate", cfg.Output)
+		return cfg, nil
+	}
+	if upToDate {
+		logger.Success("%s are up to date, nothing to generate", strings.Join(cfg.OutputFiles(), ", "))
+		return cfg, nil
+	}
+
 	// Step 4: Generate code
 	logger.Step(currentStep, totalSteps, "Generating mapper code")
 	currentStep++
 	stepStart = time.Now()
 
-	file, err := generator.Generate(dtos, sources, cfg, pkgName, functions)
+	files, err := generator.Generate(dtos, sources, cfg, pkgName, functions, inputHash)
 	if err != nil {
-		return fmt.Errorf("generating code: %w", err)
+		return nil, withExitCode(exitWrite, fmt.Errorf("generating code: %w", err))
 	}
 
 	logger.Progress(stepStart, "Code generation complete")
 
-	// Step 5: Write output
-	logger.Step(currentStep, totalSteps, "Writing output file")
+	// Step 5: Write output, or only show its changes in dry-run mode
+	if toStdout {
+		logger.Step(currentStep, totalSteps, "Writing code to the standard output")
+		if err := files[cfg.Output].Render(os.Stdout); err != nil {
+			return nil, withExitCode(exitWrite, fmt.Errorf("writing output: %w", err))
+		}
+		logger.Verbose("Test files are only written next to an output file")
+		logger.Success("Generation completed successfully in %v", time.Since(startTime).Round(time.Millisecond))
+		return cfg, nil
+	}
+
+	if *dryRun {
+		logger.Step(currentStep, totalSteps, "Comparing output file (dry run)")
+	} else {
+		logger.Step(currentStep, totalSteps, "Writing output file")
+	}
 	stepStart = time.Now()
 
-	outputPath := filepath.Join(pkgPath, cfg.Output)
-	logger.Verbose("Output path: %s", outputPath)
+	for _, output := range cfg.OutputFiles() {
+		outputPath := filepath.Join(pkgPath, output)
+		logger.Verbose("Output path: %s", outputPath)
 
-	if err := file.Save(outputPath); err != nil {
-		return fmt.Errorf("writing output: %w", err)
+		if err := saveFile("Output", files[output], outputPath); err != nil {
+			return nil, withExitCode(exitWrite, err)
+		}
+	}
+	if err := removeStaleOutputs(pkgPath, cfg); err != nil {
+		return nil, withExitCode(exitWrite, err)
 	}
 
-	logger.Progress(stepStart, "File written")
+	if err := writeTestFiles(pkgPath, cfg, dtos, sources, pkgName); err != nil {
+		return nil, withExitCode(exitWrite, err)
+	}
+
+	if *dryRun {
+		logger.Progress(stepStart, "Dry run complete, nothing written")
+	} else {
+		logger.Progress(stepStart, "File written")
+	}
 
 	// Final statistics
 	logger.Stats("Generation Summary", map[string]any{
 		"DTOs mapped":       len(dtos),
 		"Source structs":    len(sources),
 		"External packages": len(cfg.ExternalPackages),
-		"Output file":       cfg.Output,
+		"Output files":      strings.Join(cfg.OutputFiles(), ", "),
 	})
 
 	// Calculate total time
 	elapsed := time.Since(startTime)
 	logger.Success("Generation completed successfully in %v", elapsed.Round(time.Millisecond))
 
+	return cfg, nil
+}
+
+// openCache returns the parse cache of the module of a package, or nil when caching is disabled
+func openCache(pkgPath string) *cache.Cache {
+	if *noCache {
+		return nil
+	}
+	c := cache.Open(pkgPath, generator.Version)
+	logger.Debug("Cache directory: %s", c.Dir())
+	return c
+}
+
+// configPath returns the configuration file of a package, the one given with -config if any
+func configPath(pkgPath string) string {
+	if *configFile != "" {
+		return *configFile
+	}
+	if path := parser.FindConfigFile(pkgPath); path != "" {
+		return path
+	}
+	return filepath.Join(pkgPath, parser.ConfigFileName)
+}
+
+// loadConfig loads the configuration of a package with the profile given with -profile applied.
+// The running generator has to satisfy its requiresVersion.
+func loadConfig(pkgPath string) (*config.Config, error) {
+	cfg, err := readConfig(pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	if *profile != "" {
+		logger.Verbose("Profile: %s", *profile)
+		if cfg, err = cfg.WithProfile(*profile); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, cfg.CheckVersion(generator.Version)
+}
+
+// readConfig reads the configuration of a package, from its automapper-config directives when it
+// has no configuration file, or the default one when it has neither. A configuration given with
+// -config must exist.
+func readConfig(pkgPath string) (*config.Config, error) {
+	cfgPath := configPath(pkgPath)
+	logger.Verbose("Config file: %s", cfgPath)
+
+	directives, err := parser.FindConfigDirectives(pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading config directives: %w", err)
+	}
+
+	cfg, err := config.Load(cfgPath)
+	if errors.Is(err, fs.ErrNotExist) && *configFile == "" {
+		if len(directives) > 0 {
+			logger.Verbose("No %s, using the automapper-config directives of the package", cfgPath)
+			cfg, err := config.FromDirectives(directives)
+			if err != nil {
+				return nil, fmt.Errorf("loading config directives: %w", err)
+			}
+			return cfg, nil
+		}
+		logger.Verbose("No %s, using the default configuration", cfgPath)
+		return config.Default(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	if len(directives) > 0 {
+		logger.Warning("%s takes precedence, the automapper-config directives of the package are ignored", cfgPath)
+	}
+	return cfg, nil
+}
+
+// writeTestFiles writes the test files enabled in the configuration next to the generated code
+func writeTestFiles(
+	pkgPath string,
+	cfg *config.Config,
+	dtos []types.DTOMapping,
+	sources map[string]types.SourceStruct,
+	pkgName string,
+) error {
+	save := func(kind string, file *jen.File, name string) error {
+		path := filepath.Join(pkgPath, name)
+		logger.Verbose("%s path: %s", kind, path)
+
+		return saveFile(kind, file, path)
+	}
+
+	if cfg.FuzzTests {
+		if fuzzFile := generator.GenerateFuzzTests(dtos, sources, cfg, pkgName); fuzzFile != nil {
+			if err := save("Fuzz tests", fuzzFile, generator.FuzzTestFileName(cfg.Output)); err != nil {
+				return err
+			}
+		} else {
+			logger.Warning("No bidirectional DTO has fields to fuzz, skipping fuzz tests")
+		}
+	}
+
+	// Coverage tests and benchmarks share the populated sources
+	if cfg.CoverageTests || cfg.Benchmarks {
+		fixtures := generator.GenerateFixtures(dtos, sources, cfg, pkgName)
+		if err := save("Test fixtures", fixtures, generator.FixturesTestFileName(cfg.Output)); err != nil {
+			return err
+		}
+	}
+
+	if cfg.CoverageTests {
+		coverage := generator.GenerateCoverageTests(dtos, sources, cfg, pkgName)
+		if err := save("Coverage tests", coverage, generator.CoverageTestFileName(cfg.Output)); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Benchmarks {
+		benchmarks := generator.GenerateBenchmarks(dtos, sources, cfg, pkgName)
+		if err := save("Benchmarks", benchmarks, generator.BenchmarkTestFileName(cfg.Output)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeStaleOutputs deletes the generated files outputPattern gave DTOs that are gone, their
+// mappings no longer compile. In dry-run mode they are only listed.
+func removeStaleOutputs(pkgPath string, cfg *config.Config) error {
+	if cfg.OutputPattern == "" {
+		return nil
+	}
+	files, err := parser.FindGeneratedFiles(pkgPath, false)
+	if err != nil {
+		return fmt.Errorf("searching stale outputs: %w", err)
+	}
+
+	outputs := cfg.OutputFiles()
+	for _, file := range files {
+		name := filepath.Base(file)
+		if !cfg.IsOutputFile(name) || slices.Contains(outputs, name) {
+			continue
+		}
+		if *dryRun {
+			logger.Info("Would remove stale %s", file)
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+		logger.Info("Removed stale %s", file)
+	}
+	return nil
+}
+
+// saveFile writes a generated file, or prints the diff against the existing one in dry-run mode
+func saveFile(kind string, file *jen.File, path string) error {
+	if !*dryRun {
+		if err := file.Save(path); err != nil {
+			return fmt.Errorf("writing %s: %w", strings.ToLower(kind), err)
+		}
+		return nil
+	}
+
+	var rendered bytes.Buffer
+	if err := file.Render(&rendered); err != nil {
+		return fmt.Errorf("rendering %s: %w", strings.ToLower(kind), err)
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("reading %s: %w", strings.ToLower(kind), err)
+	}
+
+	colored := *color == "always" || (*color == "auto" && !*noColor && logger.StdoutSupportsColors())
+	name := filepath.ToSlash(path)
+	if changes := diff.Unified("a/"+name, "b/"+name, existing, rendered.Bytes(), colored); changes != "" {
+		fmt.Print(changes)
+	} else {
+		logger.Info("%s: no changes", path)
+	}
 	return nil
 }
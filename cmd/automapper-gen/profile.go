@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+)
+
+// stopProfiles finishes the profiles started by startProfiles
+var stopProfiles = func() {}
+
+// startProfiles starts the CPU profile and arranges for the memory profile to be written
+// when stopProfiles is called, as requested by -cpuprofile and -memprofile
+func startProfiles(cpuPath, memPath string) error {
+	var cpuFile *os.File
+	if cpuPath != "" {
+		file, err := os.Create(cpuPath)
+		if err != nil {
+			return fmt.Errorf("creating CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return fmt.Errorf("starting CPU profile: %w", err)
+		}
+		cpuFile = file
+	}
+
+	stopProfiles = func() {
+		stopProfiles = func() {}
+
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				logger.Error("Writing CPU profile failed: %v", err)
+			} else {
+				logger.Info("CPU profile written to %s", cpuPath)
+			}
+		}
+
+		if memPath != "" {
+			if err := writeHeapProfile(memPath); err != nil {
+				logger.Error("Writing memory profile failed: %v", err)
+			} else {
+				logger.Info("Memory profile written to %s", memPath)
+			}
+		}
+	}
+	return nil
+}
+
+// writeHeapProfile writes the allocations of the run, after a collection so the profile is up to date
+func writeHeapProfile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// exit finishes the profiles before exiting, deferred calls don't run on os.Exit
+func exit(code int) {
+	stopProfiles()
+	os.Exit(code)
+}
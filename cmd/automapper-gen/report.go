@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/generator"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/validator"
+)
+
+// report is the machine-readable outcome of a run, written with -report=json
+type report struct {
+	Version  string          `json:"version"`
+	Packages []packageReport `json:"packages"`
+}
+
+// packageReport is the validation outcome of a package
+type packageReport struct {
+	Package   string                      `json:"package"`
+	Validated bool                        `json:"validated"` // false when the package failed before validation or it was skipped
+	Valid     bool                        `json:"valid"`
+	Error     string                      `json:"error,omitempty"`
+	Errors    []validator.ValidationError `json:"errors"`
+	Warnings  []validator.ValidationError `json:"warnings"`
+	Info      []validator.ValidationError `json:"info,omitempty"`
+	Stats     map[string]int              `json:"stats,omitempty"`
+}
+
+// newPackageReport creates the report of a package that wasn't validated yet
+func newPackageReport(pkgPath string) *packageReport {
+	return &packageReport{
+		Package:  pkgPath,
+		Errors:   []validator.ValidationError{},
+		Warnings: []validator.ValidationError{},
+	}
+}
+
+// setResult records the validation result of the package
+func (r *packageReport) setResult(result *validator.ValidationResult) {
+	r.Validated = true
+	r.Valid = result.IsValid()
+	r.Errors = result.Errors
+	r.Warnings = result.Warnings
+	r.Info = result.Info
+	r.Stats = result.Stats
+}
+
+// writeReport writes the report in the requested format to a file, or to the standard output for an empty path
+func writeReport(format, path string, packages []packageReport) error {
+	if format != "json" {
+		return fmt.Errorf("unknown report format %q", format)
+	}
+
+	if path == "" {
+		return encodeReport(os.Stdout, packages)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating report: %w", err)
+	}
+	if err := encodeReport(file, packages); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// encodeReport writes the report as indented JSON
+func encodeReport(w io.Writer, packages []packageReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(report{Version: generator.Version, Packages: packages})
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/generator"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+)
+
+// runRuntime implements the runtime command, writing the infrastructure shared through runtimePackage
+// into a package of its own
+func runRuntime(args []string) error {
+	flags := flag.NewFlagSet("runtime", flag.ExitOnError)
+	name := flags.String("name", "", "Package name, the directory name by default")
+	flags.Usage = func() {
+		fmt.Println("Usage: automapper-gen runtime [options] <package-path>")
+		fmt.Println("\nOptions:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		exit(1)
+	}
+	pkgPath := flags.Arg(0)
+
+	pkgName := *name
+	if pkgName == "" {
+		abs, err := filepath.Abs(pkgPath)
+		if err != nil {
+			return err
+		}
+		pkgName = filepath.Base(abs)
+	}
+	if !token.IsIdentifier(pkgName) {
+		return fmt.Errorf("%q is not a valid package name, pass -name", pkgName)
+	}
+
+	if err := os.MkdirAll(pkgPath, 0o755); err != nil {
+		return err
+	}
+	cfg, err := loadConfig(pkgPath)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(pkgPath, generator.RuntimeFileName)
+	if err := saveFile("Runtime", generator.GenerateRuntime(pkgName, cfg), path); err != nil {
+		return err
+	}
+	if !*dryRun {
+		logger.Success("Runtime package written to %s", path)
+	}
+	return nil
+}
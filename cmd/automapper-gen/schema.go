@@ -0,0 +1,17 @@
+package main
+
+import (
+	"os"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+)
+
+// runSchema implements the schema command, printing the JSON Schema of automapper.json
+func runSchema() error {
+	data, err := config.MarshalSchema()
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
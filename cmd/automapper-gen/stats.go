@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/generator"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+)
+
+// runStats implements the stats command, printing the mapping coverage of the DTOs of a package
+func runStats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	format := flags.String("format", "table", "Output format: table or json")
+	flags.Usage = func() {
+		fmt.Println("Usage: automapper-gen stats [options] [package-path]")
+		fmt.Println("\nOptions:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *format != "table" && *format != "json" {
+		return fmt.Errorf("unknown format %q, expected table or json", *format)
+	}
+
+	pkgPath := "."
+	if flags.NArg() > 0 {
+		pkgPath = flags.Arg(0)
+	}
+
+	cfg, err := loadConfig(pkgPath)
+	if err != nil {
+		return err
+	}
+
+	dtos, sources, _, _, err := parser.ParsePackage(pkgPath, cfg, openCache(pkgPath))
+	if err != nil {
+		return fmt.Errorf("parsing package: %w", err)
+	}
+
+	stats, err := generator.Stats(dtos, sources, cfg)
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+	return printStats(stats)
+}
+
+// printStats prints the mapping statistics as tables
+func printStats(stats generator.PackageStats) error {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Mappings (%d)\n", len(stats.Mappings))
+	if len(stats.Mappings) > 0 {
+		fmt.Fprintln(w, "  DTO\tSource\tThrough\tCoverage\tMapped\tConverted\tIgnored\tZero-valued\tUnused source fields")
+	}
+	for _, m := range stats.Mappings {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%.0f%% (%d/%d)\t%d/%d\t%d\t%d\t%d\t%s\n",
+			m.DTO, m.Source, orDash(m.Through), m.Coverage(), m.Consumed, m.SourceFields, m.Mapped, m.DTOFields,
+			m.Converted, m.Ignored, m.ZeroValued, orDash(strings.Join(m.Unused, ", ")))
+	}
+
+	names := make([]string, 0, len(stats.Converters))
+	for name := range stats.Converters {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	fmt.Fprintf(w, "\nConverters (%d)\n", len(names))
+	if len(names) > 0 {
+		fmt.Fprintln(w, "  Converter\tFields")
+	}
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\t%d\n", name, stats.Converters[name])
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Print(buf.String())
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	runtimedebug "runtime/debug"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/generator"
+)
+
+// runVersion implements the version command, printing the generator version and build metadata
+func runVersion() {
+	fmt.Printf("automapper-gen %s\n", generator.Version)
+
+	commit, modified, built := "unknown", false, ""
+	goVersion := runtime.Version()
+	if info, ok := runtimedebug.ReadBuildInfo(); ok {
+		goVersion = info.GoVersion
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				commit = setting.Value
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			case "vcs.time":
+				built = setting.Value
+			}
+		}
+	}
+
+	if modified {
+		commit += " (modified)"
+	}
+	fmt.Printf("commit:  %s\n", commit)
+	if built != "" {
+		fmt.Printf("date:    %s\n", built)
+	}
+	fmt.Printf("go:      %s\n", goVersion)
+}
@@ -1,6 +1,7 @@
 /*
-Code generated by automapper-gen. DO NOT EDIT.
+Code generated by automapper-gen v0.0.1. DO NOT EDIT.
 Learn more: https://git.weirdcat.su/weirdcat/automapper-gen
+Input hash: 31f5c6e89ee2fc1d769b4d01769fbe86bd11f975b6700dda8642e2cf3fb24e35
 */
 
 package dtos
@@ -12,6 +13,17 @@ import (
 )
 
 // MapFromUserDB maps from db.UserDB to UserDTO
+//
+//	DTO field            Source field         Mapping
+//	ID                   ID                   direct
+//	Username             Username             direct
+//	Role                 Role                 converter RoleEnum
+//	About                About                direct
+//	Pets                 Pets                 nested PetDTO
+//	FeaturedAchievement  FeaturedAchievement  nested AchievementDTO
+//	Interests            Interests            converter InterestEnums
+//	Birthday             Birthday             converter TimeToString
+//	CreatedAt            CreatedAt            converter TimeToString
 func (d *UserDTO) MapFromUserDB(src *db.UserDB) error {
 	if src == nil {
 		return errors.New("source is nil")
@@ -23,7 +35,7 @@ func (d *UserDTO) MapFromUserDB(src *db.UserDB) error {
 		var err error
 		d.Role, err = StrRoleToEnum(src.Role)
 		if err != nil {
-			return fmt.Errorf("converting field Role: %w", err)
+			return &MappingError{DTO: "UserDTO", Source: "UserDB", Field: "Role", SourceField: "Role", Converter: "StrRoleToEnum", Err: err}
 		}
 	}
 	if src.About != nil {
@@ -54,7 +66,7 @@ func (d *UserDTO) MapFromUserDB(src *db.UserDB) error {
 		var err error
 		d.Interests, err = StrInterestsToEnums(src.Interests)
 		if err != nil {
-			return fmt.Errorf("converting field Interests: %w", err)
+			return &MappingError{DTO: "UserDTO", Source: "UserDB", Field: "Interests", SourceField: "Interests", Converter: "StrInterestsToEnums", Err: err}
 		}
 	}
 	if src.Birthday != nil {
@@ -68,6 +80,13 @@ func (d *UserDTO) MapFromUserDB(src *db.UserDB) error {
 }
 
 // MapFromPetDB maps from db.PetDB to PetDTO
+//
+//	DTO field  Source field  Mapping
+//	ID         ID            direct
+//	Name       Name          direct
+//	Interests  Interests     converter InterestEnums
+//	Birthday   Birthday      converter TimeToString
+//	CreatedAt  CreatedAt     converter TimeToString
 func (d *PetDTO) MapFromPetDB(src *db.PetDB) error {
 	if src == nil {
 		return errors.New("source is nil")
@@ -79,7 +98,7 @@ func (d *PetDTO) MapFromPetDB(src *db.PetDB) error {
 		var err error
 		d.Interests, err = StrInterestsToEnums(src.Interests)
 		if err != nil {
-			return fmt.Errorf("converting field Interests: %w", err)
+			return &MappingError{DTO: "PetDTO", Source: "PetDB", Field: "Interests", SourceField: "Interests", Converter: "StrInterestsToEnums", Err: err}
 		}
 	}
 	if src.Birthday != nil {
@@ -93,6 +112,11 @@ func (d *PetDTO) MapFromPetDB(src *db.PetDB) error {
 }
 
 // MapFromAchievementDB maps from db.AchievementDB to AchievementDTO
+//
+//	DTO field    Source field  Mapping
+//	ID           ID            direct
+//	Title        Title         direct
+//	Description  Description   converter ToLower
 func (d *AchievementDTO) MapFromAchievementDB(src *db.AchievementDB) error {
 	if src == nil {
 		return errors.New("source is nil")
@@ -104,3 +128,21 @@ func (d *AchievementDTO) MapFromAchievementDB(src *db.AchievementDB) error {
 
 	return nil
 }
+
+// MappingError describes a field whose converter failed during a mapping
+type MappingError struct {
+	DTO         string // name of the DTO type
+	Source      string // name of the source type
+	Field       string // name of the DTO field
+	SourceField string // name of the source field
+	Converter   string // name of the failed converter function
+	Err         error  // error returned by the converter
+}
+
+func (e *MappingError) Error() string {
+	return fmt.Sprintf("converting field %s: %v", e.Field, e.Err)
+}
+
+func (e *MappingError) Unwrap() error {
+	return e.Err
+}
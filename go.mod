@@ -2,10 +2,13 @@ module git.weirdcat.su/weirdcat/automapper-gen
 
 go 1.25.5
 
-require github.com/dave/jennifer v1.7.1
+require (
+	github.com/BurntSushi/toml v1.5.0
+	github.com/dave/jennifer v1.7.1
+)
 
 require (
-	golang.org/x/mod v0.31.0 // indirect
+	golang.org/x/mod v0.31.0
 	golang.org/x/sync v0.19.0 // indirect
 	golang.org/x/tools v0.40.0
 )
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+)
+
+// DirName is the cache directory, created next to the go.mod of the module
+const DirName = ".automapper-cache"
+
+// Cache stores parsed packages on disk as JSON, keyed by a hash of everything they were parsed from.
+// JSON keeps empty and nil slices apart, so cached results hash like freshly parsed ones.
+// A nil cache is disabled, lookups miss and nothing is stored.
+type Cache struct {
+	dir     string
+	version string
+}
+
+// Open returns the cache of the module containing a directory, or nil outside of a module.
+// The version of the generator is part of every key, a new generator never reads older entries.
+func Open(dir, version string) *Cache {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(root, "go.mod")); err == nil {
+			return &Cache{dir: filepath.Join(root, DirName), version: version}
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return nil
+		}
+		root = parent
+	}
+}
+
+// Dir returns the directory of the cache
+func (c *Cache) Dir() string {
+	if c == nil {
+		return ""
+	}
+	return c.dir
+}
+
+// Key hashes the parts identifying a cache entry
+func (c *Cache) Key(parts ...string) string {
+	if c == nil {
+		return ""
+	}
+	h := sha256.New()
+	for _, part := range append([]string{c.version}, parts...) {
+		// Parts are length-prefixed so their boundaries are part of the hash
+		fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get decodes the entry stored under a key into value, reporting whether it was found
+func (c *Cache) Get(key string, value any) bool {
+	if c == nil || key == "" {
+		return false
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			logger.Debug("Reading cache entry %s: %v", key, err)
+		}
+		return false
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		logger.Debug("Decoding cache entry %s: %v", key, err)
+		return false
+	}
+	return true
+}
+
+// Put stores value under a key, a cache that can't be written only costs the next run its speed
+func (c *Cache) Put(key string, value any) {
+	if c == nil || key == "" {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		logger.Debug("Encoding cache entry %s: %v", key, err)
+		return
+	}
+	if err := c.ensureDir(); err != nil {
+		logger.Debug("Creating cache directory: %v", err)
+		return
+	}
+
+	// Entries are renamed into place, a concurrent run never reads a partial entry
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		logger.Debug("Writing cache entry %s: %v", key, err)
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		logger.Debug("Writing cache entry %s: %v", key, err)
+	}
+}
+
+// ensureDir creates the cache directory, ignored by git so it never ends up in commits
+func (c *Cache) ensureDir() error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+	gitignore := filepath.Join(c.dir, ".gitignore")
+	if _, err := os.Stat(gitignore); errors.Is(err, fs.ErrNotExist) {
+		return os.WriteFile(gitignore, []byte("*\n"), 0o644)
+	}
+	return nil
+}
+
+// path returns the file of an entry
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// HashFiles hashes the names and contents of files, in any order
+func HashFiles(paths []string) (string, error) {
+	sorted := slices.Clone(paths)
+	slices.Sort(sorted)
+
+	h := sha256.New()
+	for _, path := range sorted {
+		sum, err := HashFile(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s %s\n", filepath.Base(path), sum)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashFile hashes the content of a file
+func HashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -2,7 +2,78 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
+	"go/build/constraint"
+	"maps"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// Field matching modes
+const (
+	// FieldMatchExact only matches identical field names
+	FieldMatchExact = "exact"
+	// FieldMatchInsensitive matches field names ignoring case (ID, Id, id)
+	FieldMatchInsensitive = "insensitive"
+	// FieldMatchFuzzy matches field names ignoring case, underscores and dashes (user_id, UserID)
+	FieldMatchFuzzy = "fuzzy"
+)
+
+// Field name sources
+const (
+	// FieldNameSourceName looks up source fields by the DTO field's Go name
+	FieldNameSourceName = "name"
+	// FieldNameSourceJSON looks up source fields by the DTO field's json tag
+	FieldNameSourceJSON = "json"
+)
+
+// Field name transforms
+const (
+	// TransformSnakeToCamel turns created_at into CreatedAt
+	TransformSnakeToCamel = "snake_to_camel"
+	// TransformNone leaves names untouched
+	TransformNone = "none"
+	// TransformIdentity leaves names untouched, like none
+	TransformIdentity = "identity"
+	// TransformCamelToSnake turns CreatedAt into created_at
+	TransformCamelToSnake = "camel_to_snake"
+	// TransformPascal turns created_at, created-at or createdAt into CreatedAt
+	TransformPascal = "pascal"
+	// TransformKebab turns CreatedAt into created-at
+	TransformKebab = "kebab"
+	// TransformScreamingSnake turns CreatedAt into CREATED_AT
+	TransformScreamingSnake = "screaming_snake"
+	// TransformFuncPrefix prefixes custom transforms calling a Go function, e.g. func:LegacyName
+	TransformFuncPrefix = "func:"
+)
+
+// transforms lists the built-in field name transforms
+var transforms = []string{
+	TransformSnakeToCamel, TransformCamelToSnake, TransformPascal, TransformKebab,
+	TransformScreamingSnake, TransformIdentity, TransformNone,
+}
+
+// Output styles
+const (
+	// StyleMethod generates MapFrom methods on the DTOs
+	StyleMethod = "method"
+	// StyleFunction generates package-level functions returning new DTOs
+	StyleFunction = "function"
+)
+
+// Pointer conversion policies
+const (
+	// PointerAllow maps the fields silently
+	PointerAllow = "allow"
+	// PointerWarn reports the fields as validation warnings, errors in strict mode
+	PointerWarn = "warn"
+	// PointerError reports the fields as validation errors
+	PointerError = "error"
 )
 
 // Config represents the automapper configuration
@@ -11,37 +82,601 @@ type Config struct {
 	Converters         []ConverterDef    `json:"converters"`
 	NilPointersForNull bool              `json:"nilPointersForNull"`
 	ExternalPackages   []ExternalPackage `json:"externalPackages"`
+	FieldMatch         string            `json:"fieldMatch"`
+	NameTransforms     NameTransforms    `json:"nameTransforms"`
+	FieldNameSource    string            `json:"fieldNameSource"`
+	FieldNameTransform string            `json:"fieldNameTransform"`
+	Initialisms        []string          `json:"initialisms"` // initialisms spelled as given by the transforms, besides ID, URL...
+	MaxDepth           int               `json:"maxDepth"`
+	Style              string            `json:"style"`
+	Registry           bool              `json:"registry"`
+	Options            bool              `json:"options"`
+	GenericHelpers     bool              `json:"genericHelpers"` // map nested DTO slices through shared generic helpers
+	SafeVariants       bool              `json:"safeVariants"`   // add MapFrom variants without an error return where no mapping can fail
+	MapperInterfaces   []MapperInterface `json:"mapperInterfaces"`
+	ErrorMessages      ErrorMessages     `json:"errorMessages"`
+	Metrics            bool              `json:"metrics"`
+	Tracing            bool              `json:"tracing"`
+	FuzzTests          bool              `json:"fuzzTests"`
+	CoverageTests      bool              `json:"coverageTests"`
+	Benchmarks         bool              `json:"benchmarks"`
+	BuildTags          string            `json:"buildTags"`         // build constraint of the generated files, e.g. !codegen_off
+	Header             string            `json:"header"`            // license or ownership text put atop the generated files
+	Strict             bool              `json:"strict"`            // fail on validation warnings, like the -strict flag
+	Mappings           []Mapping         `json:"mappings"`          // DTOs declared without annotations
+	IgnoreFields       []string          `json:"ignoreFields"`      // DTO fields never mapped, by glob or /regex/
+	ConverterPackages  []string          `json:"converterPackages"` // import paths of packages declaring converters
+	TypeConverters     []TypeConverter   `json:"typeConverters"`    // converters applied to every field of a type pair
+	MapFromName        string            `json:"mapFromName"`       // template of MapFrom method names, e.g. From{{.Source}}
+	MapToName          string            `json:"mapToName"`         // template of MapTo method names, e.g. To{{.Target}}
+	RuntimePackage     string            `json:"runtimePackage"`    // import path of the package sharing MappingError, FieldDiff and MapObserver
+	RequiresVersion    string            `json:"requiresVersion"`   // generator versions the configuration is meant for, e.g. >=0.5 <0.7
+	Outputs            []OutputGroup     `json:"outputs"`           // DTOs generated into files of their own instead of output
+	OutputPattern      string            `json:"outputPattern"`     // file of every DTO outside the outputs, e.g. {{snake .DTO}}_mapper.gen.go
+	DTOs               []string          `json:"dtos"`              // DTOs generated with their dependencies, all by default
+	Profiles           Profiles          `json:"profiles"`          // top-level keys replaced by a profile selected with -profile
+	ImportAliases      map[string]string `json:"importAliases"`     // names of the packages imported by the generated code, by import path
+	PointerConversions PointerPolicy     `json:"pointerConversions"`
+	GoVersion          string            `json:"goVersion"`            // oldest Go version the generated code compiles with, e.g. 1.20
+	Severities         map[string]string `json:"severities"`           // severity of the validation findings by rule, e.g. pointer-conversion: error
+	Load               LoadSettings      `json:"load"`                 // build tags, flags and environment the packages are loaded with
+	IncludeGenerated   bool              `json:"includeGenerated"`     // load sources from the files other tools generate too
+	TestFiles          bool              `json:"testFiles"`            // parse the _test.go files too, their DTOs generated into the test output
+	NumericWidening    bool              `json:"allowNumericWidening"` // cast numeric fields to wider DTO types, e.g. int32 to int64
+
+	ignorePatterns []*regexp.Regexp             // compiled IgnoreFields
+	customNames    map[string]map[string]string // names rewritten by custom transforms, by transform
+	methodNames    MethodNames                  // compiled MapFromName and MapToName
+	dtoPatterns    []*regexp.Regexp             // compiled DTOs
+	outputPattern  *template.Template           // compiled OutputPattern
+	outputAffixes  [2]string                    // prefix and suffix of the files OutputPattern gives
+	outputNames    map[string]string            // files of the DTOs rendered from OutputPattern
+	document       []byte                       // JSON document the configuration is parsed from
+}
+
+// SetCustomNames records the names a custom transform gives, resolved once the DTOs are parsed
+func (c *Config) SetCustomNames(transform string, names map[string]string) {
+	if c.customNames == nil {
+		c.customNames = make(map[string]map[string]string)
+	}
+	c.customNames[transform] = names
+}
+
+// CustomNames returns the names a custom transform gives, by original name
+func (c *Config) CustomNames(transform string) map[string]string {
+	return c.customNames[transform]
+}
+
+// IsIgnoredField reports whether a DTO field matches one of the ignoreFields patterns,
+// by its Go name or its json name
+func (c *Config) IsIgnoredField(name, jsonName string) bool {
+	for _, pattern := range c.ignorePatterns {
+		if pattern.MatchString(name) || (jsonName != "" && pattern.MatchString(jsonName)) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileNamePattern compiles an ignoreFields, dtos, outputs or external package pattern: a regular expression between slashes,
+// e.g. /^internal_/, or else a glob matching whole names where * and ? are wildcards, e.g. *Secret
+func compileNamePattern(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return regexp.Compile(pattern[1 : len(pattern)-1])
+	}
+	glob := regexp.QuoteMeta(pattern)
+	glob = strings.ReplaceAll(glob, `\*`, ".*")
+	glob = strings.ReplaceAll(glob, `\?`, ".")
+	return regexp.Compile("^" + glob + "$")
+}
+
+// Mapping declares a DTO of the package in the configuration, equivalent to its automapper annotations,
+// for structs that can't be annotated such as generated ones. Values use the syntax of the annotations.
+type Mapping struct {
+	DTO       string            `json:"dto"`
+	From      string            `json:"from"`    // like automapper:from, comma-separated sources
+	Sources   string            `json:"sources"` // like automapper:sources, sources separated by > in priority order
+	Via       string            `json:"via"`
+	Mode      string            `json:"mode"`
+	Transform string            `json:"transform"`
+	Style     string            `json:"style"`
+	Fields    map[string]string `json:"fields"` // automapper tags by DTO field, e.g. "converter=RoleEnum" or "-"
+}
+
+// Directive returns the value of a mapping directive by its annotation key, e.g. from or mode
+func (m Mapping) Directive(key string) string {
+	switch key {
+	case "from":
+		return m.From
+	case "sources":
+		return m.Sources
+	case "via":
+		return m.Via
+	case "mode":
+		return m.Mode
+	case "transform":
+		return m.Transform
+	case "style":
+		return m.Style
+	}
+	return ""
+}
+
+// MappingOf returns the mapping declared for a DTO in the configuration
+func (c *Config) MappingOf(dto string) (Mapping, bool) {
+	for _, mapping := range c.Mappings {
+		if mapping.DTO == dto {
+			return mapping, true
+		}
+	}
+	return Mapping{}, false
+}
+
+// Error message placeholders
+const (
+	PlaceholderDTO         = "dto"         // DTO type name
+	PlaceholderSource      = "source"      // source type name
+	PlaceholderField       = "field"       // DTO field name
+	PlaceholderSourceField = "sourceField" // source field name
+	PlaceholderConverter   = "converter"   // converter function name
+	PlaceholderErr         = "err"         // error returned by the converter
+)
+
+// ErrorMessages defines templates for the errors returned by the generated code.
+// Placeholders are written in braces, e.g. "mapping {source} to {dto}: source is nil".
+type ErrorMessages struct {
+	NilSource  string `json:"nilSource"`  // returned for a nil source, supports {dto} and {source}
+	Conversion string `json:"conversion"` // returned by MappingError, supports every placeholder
+}
+
+// placeholderPattern matches the placeholders of an error message template
+var placeholderPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// SplitTemplate splits an error message template into its literal text and placeholders.
+// Literal parts are at even indices, placeholder names at odd ones.
+func SplitTemplate(template string) []string {
+	var parts []string
+	last := 0
+	for _, loc := range placeholderPattern.FindAllStringSubmatchIndex(template, -1) {
+		parts = append(parts, template[last:loc[0]], template[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	return append(parts, template[last:])
+}
+
+// validateTemplate checks that a template only uses the allowed placeholders
+func validateTemplate(name, template string, allowed ...string) error {
+	parts := SplitTemplate(template)
+	for i := 1; i < len(parts); i += 2 {
+		if placeholder := parts[i]; !slices.Contains(allowed, placeholder) {
+			return fmt.Errorf("errorMessages.%s: unknown placeholder {%s}", name, placeholder)
+		}
+	}
+	return nil
+}
+
+// NameTransforms defines how field names are rewritten before DTO and source fields are matched
+type NameTransforms struct {
+	Source NameStripping `json:"source"`
+	DTO    NameStripping `json:"dto"`
+}
+
+// NameStripping lists prefixes and suffixes removed from field names, e.g. "DB" in UserNameDB
+type NameStripping struct {
+	StripPrefixes []string `json:"stripPrefixes"`
+	StripSuffixes []string `json:"stripSuffixes"`
 }
 
 // ExternalPackage defines an external package to include in parsing
 type ExternalPackage struct {
-	Alias      string `json:"alias"`
-	ImportPath string `json:"importPath"`
-	LocalPath  string `json:"localPath"`
+	Alias      string   `json:"alias"`
+	ImportPath string   `json:"importPath"`
+	LocalPath  string   `json:"localPath"`
+	Include    []string `json:"include"`  // structs loaded as sources, all by default, by name, glob or /regex/
+	Exclude    []string `json:"exclude"`  // structs left out even when included
+	Generate   bool     `json:"generate"` // generate the mappers of its annotated DTOs into the package as well
+
+	includes []*regexp.Regexp // compiled Include
+	excludes []*regexp.Regexp // compiled Exclude
+	name     string           // resolved alias, see Name
+}
+
+// Includes reports whether a struct of the package is loaded as a source
+func (p ExternalPackage) Includes(structName string) bool {
+	matches := func(pattern *regexp.Regexp) bool { return pattern.MatchString(structName) }
+	if len(p.includes) > 0 && !slices.ContainsFunc(p.includes, matches) {
+		return false
+	}
+	return !slices.ContainsFunc(p.excludes, matches)
+}
+
+// MapperInterface defines an interface the DTOs are asserted to implement at compile time
+type MapperInterface struct {
+	Name       string   `json:"name"`       // interface name, e.g. FromDB
+	ImportPath string   `json:"importPath"` // package declaring the interface, empty for the generated package
+	Generic    bool     `json:"generic"`    // instantiated with the source type, e.g. FromDB[db.UserDB]
+	DTOs       []string `json:"dtos"`       // DTOs to check, every method-style DTO when empty
+}
+
+// OutputGroup generates the mappings of a group of DTOs into a file of its own, e.g. the user
+// related DTOs into user_mappers.go, so regenerating one domain leaves the files of the others alone
+type OutputGroup struct {
+	File string   `json:"file"`
+	DTOs []string `json:"dtos"` // DTO names, globs or /regex/ like ignoreFields
+
+	patterns []*regexp.Regexp // compiled DTOs
+}
+
+// TestOutput returns the file the mappings of the DTOs of _test.go files are generated into with
+// testFiles, the output file with a _test suffix, e.g. automappers_test.go
+func (c *Config) TestOutput() string {
+	return strings.TrimSuffix(c.Output, ".go") + "_test.go"
+}
+
+// OutputOf returns the file the mappings of a DTO are generated into: the first output group
+// listing it, the file outputPattern gives it, or else the output file
+func (c *Config) OutputOf(dto string) string {
+	if file, grouped := c.groupOf(dto); grouped {
+		return file
+	}
+	if file, ok := c.outputNames[dto]; ok {
+		return file
+	}
+	return c.Output
+}
+
+// groupOf returns the file of the first output group listing a DTO
+func (c *Config) groupOf(dto string) (string, bool) {
+	for _, group := range c.Outputs {
+		for _, pattern := range group.patterns {
+			if pattern.MatchString(dto) {
+				return group.File, true
+			}
+		}
+	}
+	return "", false
+}
+
+// OutputFiles returns the output file followed by the files of the output groups, the files
+// outputPattern gives the DTOs, in name order, and the test output with testFiles
+func (c *Config) OutputFiles() []string {
+	files := []string{c.Output}
+	for _, group := range c.Outputs {
+		files = append(files, group.File)
+	}
+	for _, file := range slices.Sorted(maps.Values(c.outputNames)) {
+		if !slices.Contains(files, file) {
+			files = append(files, file)
+		}
+	}
+	if c.TestFiles {
+		files = append(files, c.TestOutput())
+	}
+	return files
+}
+
+// IsOutputFile reports whether a file name is one of the generated output files, or may be
+// one outputPattern gives
+func (c *Config) IsOutputFile(name string) bool {
+	return slices.Contains(c.OutputFiles(), name) || c.isPatternOutput(name)
+}
+
+// PointerPolicy sets how fields mapped between a pointer and a value are validated, the generated
+// code giving the zero value for a nil *T mapped to T and the address of a copy for T mapped to *T
+type PointerPolicy struct {
+	FromPointer string `json:"fromPointer"` // *T to T, allow, warn or error, warn by default
+	ToPointer   string `json:"toPointer"`   // T to *T, allow, warn or error, warn by default
+}
+
+// TypeConverter applies a converter function wherever a source field of type From maps to a DTO
+// field of type To, ignoring pointers, e.g. time.Time to string, unless the field sets its own
+type TypeConverter struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Function string `json:"function"`
+	Inverter string `json:"inverter"`
 }
 
 // ConverterDef defines a converter function registration
 type ConverterDef struct {
-	Name     string `json:"name"`
-	Function string `json:"function"`
+	Name     string          `json:"name"`
+	Function string          `json:"function"`
+	Inverter string          `json:"inverter"`
+	Lossless bool            `json:"lossless"` // the inverter restores every converted value, checked by fuzz tests
+	Sample   json.RawMessage `json:"sample"`   // JSON value the converter accepts, used by coverage tests
 }
 
-// Load reads and parses the configuration file
+// Load reads and parses the configuration file, in JSON or in TOML for a .toml extension
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	data, err = decoderFor(path)(data)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Default returns the configuration of packages without a configuration file
+func Default() *Config {
+	cfg, err := Parse([]byte("{}"))
+	if err != nil {
+		panic(err) // the defaults are always valid
+	}
+	return cfg
+}
+
+// Parse parses a configuration, setting defaults and validating it
+func Parse(data []byte) (*Config, error) {
+	if err := validateDocument(data); err != nil {
+		return nil, err
+	}
 
 	var cfg Config
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
+	cfg.document = data
 
 	// Set defaults
 	if cfg.Output == "" {
 		cfg.Output = "automappers.go"
 	}
+	if cfg.FieldMatch == "" {
+		cfg.FieldMatch = FieldMatchExact
+	}
+
+	if cfg.FieldNameSource == "" {
+		cfg.FieldNameSource = FieldNameSourceName
+	}
+	if cfg.FieldNameTransform == "" {
+		cfg.FieldNameTransform = TransformSnakeToCamel
+	}
+	if cfg.Style == "" {
+		cfg.Style = StyleMethod
+	}
+
+	switch cfg.FieldMatch {
+	case FieldMatchExact, FieldMatchInsensitive, FieldMatchFuzzy:
+	default:
+		return nil, fmt.Errorf("unknown fieldMatch %q (expected %s, %s or %s)",
+			cfg.FieldMatch, FieldMatchExact, FieldMatchInsensitive, FieldMatchFuzzy)
+	}
+
+	switch cfg.FieldNameSource {
+	case FieldNameSourceName, FieldNameSourceJSON:
+	default:
+		return nil, fmt.Errorf("unknown fieldNameSource %q (expected %s or %s)",
+			cfg.FieldNameSource, FieldNameSourceName, FieldNameSourceJSON)
+	}
+
+	if !IsKnownTransform(cfg.FieldNameTransform) {
+		return nil, fmt.Errorf("unknown fieldNameTransform %q (expected %s or %sFunction)",
+			cfg.FieldNameTransform, strings.Join(transforms, ", "), TransformFuncPrefix)
+	}
+
+	for i, word := range cfg.Initialisms {
+		if word == "" || strings.IndexFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) >= 0 {
+			return nil, fmt.Errorf("initialisms[%d]: %q is not a word of letters and digits", i, word)
+		}
+	}
+
+	if err := checkPointerPolicy("fromPointer", &cfg.PointerConversions.FromPointer); err != nil {
+		return nil, err
+	}
+	if err := checkPointerPolicy("toPointer", &cfg.PointerConversions.ToPointer); err != nil {
+		return nil, err
+	}
+	if err := cfg.checkSeverities(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Load.check(); err != nil {
+		return nil, err
+	}
+
+	if !IsKnownStyle(cfg.Style) {
+		return nil, fmt.Errorf("unknown style %q (expected %s or %s)", cfg.Style, StyleMethod, StyleFunction)
+	}
+
+	if cfg.MaxDepth < 0 {
+		return nil, fmt.Errorf("maxDepth must not be negative, got %d", cfg.MaxDepth)
+	}
+
+	if err := validateTemplate("nilSource", cfg.ErrorMessages.NilSource,
+		PlaceholderDTO, PlaceholderSource); err != nil {
+		return nil, err
+	}
+	if err := validateTemplate("conversion", cfg.ErrorMessages.Conversion,
+		PlaceholderDTO, PlaceholderSource, PlaceholderField, PlaceholderSourceField,
+		PlaceholderConverter, PlaceholderErr); err != nil {
+		return nil, err
+	}
+
+	if cfg.BuildTags != "" {
+		if _, err := constraint.Parse("//go:build " + cfg.BuildTags); err != nil {
+			return nil, fmt.Errorf("invalid buildTags %q: %w", cfg.BuildTags, err)
+		}
+	}
+
+	for i, pattern := range cfg.IgnoreFields {
+		compiled, err := compileNamePattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("ignoreFields[%d]: invalid pattern %q: %w", i, pattern, err)
+		}
+		cfg.ignorePatterns = append(cfg.ignorePatterns, compiled)
+	}
+
+	if cfg.RequiresVersion != "" {
+		if _, err := parseVersionConstraint(cfg.RequiresVersion); err != nil {
+			return nil, fmt.Errorf("requiresVersion: %w", err)
+		}
+	}
+
+	if cfg.MapFromName != "" {
+		tmpl, err := compileMethodName("mapFromName", cfg.MapFromName, "Source")
+		if err != nil {
+			return nil, err
+		}
+		cfg.methodNames.mapFrom = tmpl
+	}
+	if cfg.MapToName != "" {
+		tmpl, err := compileMethodName("mapToName", cfg.MapToName, "Target")
+		if err != nil {
+			return nil, err
+		}
+		cfg.methodNames.mapTo = tmpl
+	}
+
+	for i, pattern := range cfg.DTOs {
+		compiled, err := compileNamePattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("dtos[%d]: invalid pattern %q: %w", i, pattern, err)
+		}
+		cfg.dtoPatterns = append(cfg.dtoPatterns, compiled)
+	}
+
+	for i := range cfg.ExternalPackages {
+		extPkg := &cfg.ExternalPackages[i]
+		for j, pattern := range extPkg.Include {
+			compiled, err := compileNamePattern(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("externalPackages[%d].include[%d]: invalid pattern %q: %w", i, j, pattern, err)
+			}
+			extPkg.includes = append(extPkg.includes, compiled)
+		}
+		for j, pattern := range extPkg.Exclude {
+			compiled, err := compileNamePattern(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("externalPackages[%d].exclude[%d]: invalid pattern %q: %w", i, j, pattern, err)
+			}
+			extPkg.excludes = append(extPkg.excludes, compiled)
+		}
+	}
+
+	if err := cfg.checkGoVersion(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.resolveAliases(); err != nil {
+		return nil, err
+	}
+
+	if cfg.TestFiles && strings.HasSuffix(cfg.Output, "_test.go") {
+		return nil, fmt.Errorf("testFiles: output %s must be a non-test file, the test output is named after it", cfg.Output)
+	}
+
+	files := map[string]bool{cfg.Output: true}
+	for i := range cfg.Outputs {
+		group := &cfg.Outputs[i]
+		switch {
+		case group.File == "" || len(group.DTOs) == 0:
+			return nil, fmt.Errorf("outputs[%d]: file and dtos are required", i)
+		case filepath.Base(group.File) != group.File || !strings.HasSuffix(group.File, ".go") || strings.HasSuffix(group.File, "_test.go"):
+			return nil, fmt.Errorf("outputs[%d]: file %q must be a non-test .go file of the package", i, group.File)
+		case files[group.File]:
+			return nil, fmt.Errorf("outputs[%d]: file %s is already an output", i, group.File)
+		}
+		files[group.File] = true
+
+		for j, pattern := range group.DTOs {
+			compiled, err := compileNamePattern(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("outputs[%d].dtos[%d]: invalid pattern %q: %w", i, j, pattern, err)
+			}
+			group.patterns = append(group.patterns, compiled)
+		}
+	}
+
+	if cfg.OutputPattern != "" {
+		tmpl, affixes, err := compileOutputPattern(cfg.OutputPattern)
+		if err != nil {
+			return nil, err
+		}
+		cfg.outputPattern, cfg.outputAffixes = tmpl, affixes
+	}
+
+	// Type converters register their function as a converter of the same name
+	for i, tc := range cfg.TypeConverters {
+		if tc.From == "" || tc.To == "" || tc.Function == "" {
+			return nil, fmt.Errorf("typeConverters[%d]: from, to and function are required", i)
+		}
+		j := slices.IndexFunc(cfg.Converters, func(conv ConverterDef) bool { return conv.Name == tc.Function })
+		switch {
+		case j < 0:
+			cfg.Converters = append(cfg.Converters, ConverterDef{Name: tc.Function, Function: tc.Function, Inverter: tc.Inverter})
+		case cfg.Converters[j].Function != tc.Function:
+			return nil, fmt.Errorf("typeConverters[%d]: converter %s already calls %s", i, tc.Function, cfg.Converters[j].Function)
+		}
+	}
+
+	declared := make(map[string]bool)
+	for i, mapping := range cfg.Mappings {
+		if mapping.DTO == "" {
+			return nil, fmt.Errorf("mappings[%d]: dto is required", i)
+		}
+		if declared[mapping.DTO] {
+			return nil, fmt.Errorf("mappings[%d]: %s is declared twice", i, mapping.DTO)
+		}
+		declared[mapping.DTO] = true
+	}
+
+	for i, iface := range cfg.MapperInterfaces {
+		if iface.Name == "" {
+			return nil, fmt.Errorf("mapperInterfaces[%d]: name is required", i)
+		}
+	}
 
 	return &cfg, nil
 }
+
+// TypeConverterFor returns the converter applied from a source field type to a DTO field type,
+// pointers aside, or an empty string when no type converter covers the pair
+func (c *Config) TypeConverterFor(sourceType, dtoType string) string {
+	sourceType, dtoType = strings.TrimPrefix(sourceType, "*"), strings.TrimPrefix(dtoType, "*")
+	for _, tc := range c.TypeConverters {
+		if tc.From == sourceType && tc.To == dtoType {
+			return tc.Function
+		}
+	}
+	return ""
+}
+
+// IsKnownTransform reports whether a field name transform is supported, built-in or custom
+func IsKnownTransform(transform string) bool {
+	return slices.Contains(transforms, transform) || IsCustomTransform(transform)
+}
+
+// IsCustomTransform reports whether a field name transform calls a Go function, e.g. func:LegacyName
+func IsCustomTransform(transform string) bool {
+	function, ok := strings.CutPrefix(transform, TransformFuncPrefix)
+	return ok && function != ""
+}
+
+// TransformNames lists the built-in field name transforms
+func TransformNames() []string {
+	return slices.Clone(transforms)
+}
+
+// checkPointerPolicy checks a pointer conversion policy, defaulting to warn
+func checkPointerPolicy(key string, policy *string) error {
+	switch *policy {
+	case "":
+		*policy = PointerWarn
+	case PointerAllow, PointerWarn, PointerError:
+	default:
+		return fmt.Errorf("unknown pointerConversions.%s %q (expected %s, %s or %s)",
+			key, *policy, PointerAllow, PointerWarn, PointerError)
+	}
+	return nil
+}
+
+// IsKnownStyle reports whether an output style is supported
+func IsKnownStyle(style string) bool {
+	switch style {
+	case StyleMethod, StyleFunction:
+		return true
+	}
+	return false
+}
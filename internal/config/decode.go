@@ -0,0 +1,46 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// decoder turns a configuration file into the JSON form Parse reads, so every format shares
+// the same defaults and validation
+type decoder func(data []byte) ([]byte, error)
+
+// decoders lists the supported configuration formats by file extension
+var decoders = map[string]decoder{
+	".json": decodeJSON,
+	".toml": decodeTOML,
+}
+
+// Extensions lists the extensions of the supported configuration formats, JSON first
+var Extensions = []string{".json", ".toml"}
+
+// decoderFor returns the decoder of a configuration file, JSON for unknown extensions
+func decoderFor(path string) decoder {
+	if decode, ok := decoders[strings.ToLower(filepath.Ext(path))]; ok {
+		return decode
+	}
+	return decodeJSON
+}
+
+// decodeJSON passes JSON through, Parse reads it as is
+func decodeJSON(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+// decodeTOML converts a TOML document to JSON, keeping its keys, e.g. output = "automappers.go".
+// Converter samples are written as TOML values and become their JSON equivalent.
+func decodeTOML(data []byte) ([]byte, error) {
+	var document map[string]any
+	if err := toml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("parsing TOML: %w", err)
+	}
+	return json.Marshal(document)
+}
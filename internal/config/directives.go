@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// FromDirectives builds a configuration from the automapper-config directives of a package doc comment,
+// given as key=value strings. Keys are those of automapper.json; converter=Name:Function[:Inverter]
+// and external=[alias:]importPath may be repeated.
+func FromDirectives(directives []string) (*Config, error) {
+	document := make(map[string]any)
+	var converters, externalPackages []map[string]string
+
+	for _, directive := range directives {
+		key, value, ok := strings.Cut(directive, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("malformed directive automapper-config:%s, expected key=value", directive)
+		}
+
+		switch key {
+		case "converter":
+			parts := strings.Split(value, ":")
+			if len(parts) < 2 || len(parts) > 3 {
+				return nil, fmt.Errorf("malformed converter directive %q, expected Name:Function[:Inverter]", value)
+			}
+			converter := map[string]string{"name": parts[0], "function": parts[1]}
+			if len(parts) == 3 {
+				converter["inverter"] = parts[2]
+			}
+			converters = append(converters, converter)
+		case "external":
+			external := map[string]string{"importPath": value}
+			if alias, importPath, hasAlias := strings.Cut(value, ":"); hasAlias {
+				external = map[string]string{"alias": alias, "importPath": importPath}
+			}
+			externalPackages = append(externalPackages, external)
+		default:
+			if _, duplicate := document[key]; duplicate {
+				return nil, fmt.Errorf("directive %s is set twice", key)
+			}
+			// Booleans, numbers and quoted strings are read as JSON, anything else is a plain string
+			var decoded any
+			if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+				decoded = value
+			}
+			document[key] = decoded
+		}
+	}
+
+	if converters != nil {
+		document["converters"] = converters
+	}
+	if externalPackages != nil {
+		document["externalPackages"] = externalPackages
+	}
+
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Go versions gating the constructs of the generated code
+const (
+	// GoMinimum is the oldest version the generated code compiles with, the first wrapping errors with %w
+	GoMinimum = "1.13"
+	// GoGenerics adds generics and any
+	GoGenerics = "1.18"
+	// GoComparable lets interfaces, and the structs and arrays holding them, satisfy comparable
+	GoComparable = "1.20"
+	// GoSlicesMaps adds the slices and maps packages
+	GoSlicesMaps = "1.21"
+	// GoRangeInt adds ranging over integers and loop variables scoped to their iteration
+	GoRangeInt = "1.22"
+)
+
+// goSemver turns a Go version such as 1.20 into a semantic version
+func goSemver(version string) string {
+	return "v" + strings.TrimPrefix(version, "go")
+}
+
+// GoAtLeast reports whether the generated code may use the constructs of a Go version,
+// always true without goVersion
+func (c *Config) GoAtLeast(version string) bool {
+	return c.GoVersion == "" || semver.Compare(goSemver(c.GoVersion), goSemver(version)) >= 0
+}
+
+// checkGoVersion checks goVersion and the options its Go version can't compile
+func (c *Config) checkGoVersion() error {
+	if c.GoVersion == "" {
+		return nil
+	}
+	if !semver.IsValid(goSemver(c.GoVersion)) || !strings.HasPrefix(goSemver(c.GoVersion), "v1.") {
+		return fmt.Errorf("goVersion: invalid Go version %q, e.g. 1.20", c.GoVersion)
+	}
+	if !c.GoAtLeast(GoMinimum) {
+		return fmt.Errorf("goVersion: the generated code needs Go %s or later", GoMinimum)
+	}
+
+	var generic string
+	switch {
+	case c.GenericHelpers:
+		generic = "genericHelpers"
+	case c.Registry:
+		generic = "registry"
+	case c.FuzzTests:
+		generic = "fuzzTests"
+	}
+	if generic != "" && !c.GoAtLeast(GoGenerics) {
+		return fmt.Errorf("%s needs goVersion %s or later, it generates generic code", generic, GoGenerics)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"go/token"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// versionSuffix matches the major version element of a module path, e.g. v2 in example.com/models/v2
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// DefaultAlias returns the alias of a package derived from its import path: the last element, or the
+// one before a major version, lowercased and stripped of the characters an identifier can't hold,
+// e.g. yaml for gopkg.in/yaml.v3 and gomodels for example.com/go-models/v2. Keywords get a pkg suffix.
+func DefaultAlias(importPath string) string {
+	elems := strings.Split(strings.Trim(importPath, "/"), "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && versionSuffix.MatchString(name) {
+		name = elems[len(elems)-2]
+	}
+	name, _, _ = strings.Cut(name, ".")
+
+	var alias strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || r == '_' || (r >= '0' && r <= '9' && alias.Len() > 0) {
+			alias.WriteRune(r)
+		}
+	}
+	switch {
+	case alias.Len() == 0:
+		return "pkg"
+	case token.IsKeyword(alias.String()):
+		return alias.String() + "pkg"
+	}
+	return alias.String()
+}
+
+// Name returns the alias the structs of the package are referred to by, e.g. in automapper:from=db.UserDB,
+// and the package is imported under: the configured alias, or else the default alias of its import path,
+// numbered when another package already has it
+func (p ExternalPackage) Name() string {
+	if p.name == "" {
+		return DefaultAlias(p.ImportPath)
+	}
+	return p.name
+}
+
+// checkImportName checks that an alias can name an import
+func checkImportName(alias string) error {
+	if !token.IsIdentifier(alias) || alias == "_" {
+		return fmt.Errorf("%q is not a valid package name", alias)
+	}
+	return nil
+}
+
+// resolveAliases gives every external package its name, checking the configured aliases,
+// and checks the import aliases
+func (c *Config) resolveAliases() error {
+	taken := make(map[string]string) // import paths by alias
+	for i := range c.ExternalPackages {
+		extPkg := &c.ExternalPackages[i]
+		if extPkg.Alias == "" {
+			continue
+		}
+		if err := checkImportName(extPkg.Alias); err != nil {
+			return fmt.Errorf("externalPackages[%d].alias: %w", i, err)
+		}
+		if other, ok := taken[extPkg.Alias]; ok && other != extPkg.ImportPath {
+			return fmt.Errorf("externalPackages[%d].alias: %s is already the alias of %s", i, extPkg.Alias, other)
+		}
+		taken[extPkg.Alias] = extPkg.ImportPath
+		extPkg.name = extPkg.Alias
+	}
+
+	// Default aliases colliding with another package are numbered, e.g. models and models2
+	for i := range c.ExternalPackages {
+		extPkg := &c.ExternalPackages[i]
+		if extPkg.Alias != "" {
+			continue
+		}
+		base := DefaultAlias(extPkg.ImportPath)
+		name := base
+		for n := 2; taken[name] != "" && taken[name] != extPkg.ImportPath; n++ {
+			name = base + strconv.Itoa(n)
+		}
+		taken[name] = extPkg.ImportPath
+		extPkg.name = name
+	}
+
+	paths := make(map[string]string) // import paths by import alias
+	for _, path := range slices.Sorted(maps.Keys(c.ImportAliases)) {
+		alias := c.ImportAliases[path]
+		if err := checkImportName(alias); err != nil {
+			return fmt.Errorf("importAliases[%s]: %w", path, err)
+		}
+		if other, ok := paths[alias]; ok {
+			return fmt.Errorf("importAliases[%s]: %s is already the alias of %s", path, alias, other)
+		}
+		paths[alias] = path
+	}
+	return nil
+}
+
+// ForExternal returns the configuration generating the DTOs of an external package with the
+// settings of this one, the package itself left out of the external packages. The converters and
+// mappings declared by this package are left out too, their functions and DTOs living here.
+func (c *Config) ForExternal(importPath string) *Config {
+	external := *c
+	external.ExternalPackages = slices.DeleteFunc(slices.Clone(c.ExternalPackages), func(p ExternalPackage) bool {
+		return p.ImportPath == importPath
+	})
+	external.Converters = nil
+	external.TypeConverters = nil
+	external.Mappings = nil
+	external.customNames = nil
+	external.outputNames = nil
+	return &external
+}
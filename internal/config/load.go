@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// LoadSettings selects the files the packages are loaded from, like the flags and environment of
+// the go command, so structs guarded by build constraints are parsed whatever the host platform
+type LoadSettings struct {
+	Tags  []string          `json:"tags"`  // build tags satisfied, e.g. integration
+	Flags []string          `json:"flags"` // other build flags, e.g. -mod=vendor
+	Env   map[string]string `json:"env"`   // environment variables set, e.g. GOOS: linux
+}
+
+// check checks the build tags, flags and environment variables
+func (l LoadSettings) check() error {
+	for i, tag := range l.Tags {
+		if tag == "" || strings.ContainsAny(tag, ", \t") {
+			return fmt.Errorf("load.tags[%d]: %q is not a build tag", i, tag)
+		}
+	}
+	for i, flag := range l.Flags {
+		if !strings.HasPrefix(flag, "-") {
+			return fmt.Errorf("load.flags[%d]: %q is not a flag", i, flag)
+		}
+		if strings.HasPrefix(flag, "-tags") || strings.HasPrefix(flag, "--tags") {
+			return fmt.Errorf("load.flags[%d]: set build tags with load.tags", i)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(l.Env)) {
+		if name == "" || strings.ContainsAny(name, "= \t") {
+			return fmt.Errorf("load.env: %q is not an environment variable", name)
+		}
+	}
+	return nil
+}
+
+// BuildFlags returns the build flags the packages are loaded with
+func (l LoadSettings) BuildFlags() []string {
+	var flags []string
+	if len(l.Tags) > 0 {
+		flags = append(flags, "-tags="+strings.Join(l.Tags, ","))
+	}
+	return append(flags, l.Flags...)
+}
+
+// Environ returns the environment the packages are loaded in, the one of the generator with the
+// configured variables set, or nil for the environment of the generator
+func (l LoadSettings) Environ() []string {
+	if len(l.Env) == 0 {
+		return nil
+	}
+	env := os.Environ()
+	for _, name := range slices.Sorted(maps.Keys(l.Env)) {
+		env = append(env, name+"="+l.Env[name])
+	}
+	return env
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// MethodNames renders the names of the MapFrom and MapTo methods from the mapFromName and
+// mapToName templates, falling back to MapFrom<Source> and MapTo<Target>
+type MethodNames struct {
+	mapFrom *template.Template
+	mapTo   *template.Template
+}
+
+// methodNameData is the data the method name templates are executed with
+type methodNameData struct {
+	DTO    string // name of the DTO
+	Source string // source type name without its package, mapFromName only
+	Target string // target type name without its package, mapToName only
+}
+
+// MethodNames returns the renderer of the configured method names
+func (c *Config) MethodNames() *MethodNames {
+	return &c.methodNames
+}
+
+// HasMapFrom reports whether MapFrom method names come from a template
+func (n *MethodNames) HasMapFrom() bool {
+	return n != nil && n.mapFrom != nil
+}
+
+// HasMapTo reports whether MapTo method names come from a template
+func (n *MethodNames) HasMapTo() bool {
+	return n != nil && n.mapTo != nil
+}
+
+// MapFrom returns the name of the method mapping a source type into a DTO, e.g. MapFromUserDB
+func (n *MethodNames) MapFrom(dto, source string) string {
+	if !n.HasMapFrom() {
+		return "MapFrom" + source
+	}
+	return render(n.mapFrom, methodNameData{DTO: dto, Source: source})
+}
+
+// MapTo returns the name of the method mapping a DTO back into a target type, e.g. MapToUserDB
+func (n *MethodNames) MapTo(dto, target string) string {
+	if !n.HasMapTo() {
+		return "MapTo" + target
+	}
+	return render(n.mapTo, methodNameData{DTO: dto, Target: target})
+}
+
+// render executes a method name template validated by compileMethodName
+func render(tmpl *template.Template, data methodNameData) string {
+	var name strings.Builder
+	if err := tmpl.Execute(&name, data); err != nil {
+		panic(err) // the templates are checked when the configuration is parsed
+	}
+	return name.String()
+}
+
+// compileMethodName compiles a method name template, checking that it gives exported identifiers
+// that tell the type named by the placeholder apart, e.g. From{{.Source}} for the Source placeholder
+func compileMethodName(key, text, placeholder string) (*template.Template, error) {
+	tmpl, err := template.New(key).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+
+	var names []string
+	for _, typeName := range []string{"UserDB", "OrderDB"} {
+		data := methodNameData{DTO: "UserDTO"}
+		if placeholder == "Source" {
+			data.Source = typeName
+		} else {
+			data.Target = typeName
+		}
+
+		var name strings.Builder
+		if err := tmpl.Execute(&name, data); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if !token.IsIdentifier(name.String()) || !unicode.IsUpper([]rune(name.String())[0]) {
+			return nil, fmt.Errorf("%s: %q gives %q, not an exported Go identifier", key, text, name.String())
+		}
+		names = append(names, name.String())
+	}
+
+	if names[0] == names[1] {
+		return nil, fmt.Errorf("%s: %q must use {{.%s}} to tell the types apart", key, text, placeholder)
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// OutputPatternFuncs lists the functions of outputPattern templates, rewriting the DTO name like
+// the field name transforms of the same name
+var OutputPatternFuncs = []string{"snake", "kebab", "pascal", "screaming", "lower", "upper"}
+
+// outputPatternData is the data outputPattern templates are executed with
+type outputPatternData struct {
+	DTO string // name of the DTO
+}
+
+// compileOutputPattern compiles an outputPattern template, checking that it names distinct
+// non-test .go files of the package. Its functions are stand-ins until the DTO names are rendered
+// by RenderOutputNames, the text around the DTO name identifies the files it names.
+func compileOutputPattern(text string) (*template.Template, [2]string, error) {
+	funcs := template.FuncMap{}
+	for _, name := range OutputPatternFuncs {
+		funcs[name] = func(s string) string { return s }
+	}
+	tmpl, err := template.New("outputPattern").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, [2]string{}, fmt.Errorf("outputPattern: %w", err)
+	}
+
+	// UserDTO and OrderDTO check the file names, X and Z only differ by the DTO name
+	var names []string
+	for _, dto := range []string{"UserDTO", "OrderDTO", "X", "Z"} {
+		var name strings.Builder
+		if err := tmpl.Execute(&name, outputPatternData{DTO: dto}); err != nil {
+			return nil, [2]string{}, fmt.Errorf("outputPattern: %w", err)
+		}
+		if filepath.Base(name.String()) != name.String() || !strings.HasSuffix(name.String(), ".go") ||
+			strings.HasSuffix(name.String(), "_test.go") {
+			return nil, [2]string{}, fmt.Errorf("outputPattern: %q gives %q, not a non-test .go file of the package", text, name.String())
+		}
+		names = append(names, name.String())
+	}
+	if names[0] == names[1] {
+		return nil, [2]string{}, fmt.Errorf("outputPattern: %q must use {{.DTO}} to tell the files apart", text)
+	}
+
+	// The prefix and suffix the files share, which must say more than .go not to claim every file
+	names = names[2:]
+	prefix, suffix := names[0], names[0]
+	for !strings.HasPrefix(names[1], prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	for !strings.HasSuffix(names[1], suffix) {
+		suffix = suffix[1:]
+	}
+	if len(prefix)+len(suffix) <= len(".go") {
+		return nil, [2]string{}, fmt.Errorf("outputPattern: %q needs text around {{.DTO}} telling its files apart, e.g. {{snake .DTO}}_mapper.gen.go", text)
+	}
+	return tmpl, [2]string{prefix, suffix}, nil
+}
+
+// RenderOutputNames names the files of the DTOs outside the output groups after outputPattern,
+// with the given implementations of its functions. Without outputPattern the DTOs stay in output.
+func (c *Config) RenderOutputNames(dtos []string, funcs template.FuncMap) error {
+	c.outputNames = nil
+	if c.outputPattern == nil {
+		return nil
+	}
+
+	tmpl, err := c.outputPattern.Clone()
+	if err != nil {
+		return err
+	}
+	tmpl.Funcs(funcs)
+
+	c.outputNames = make(map[string]string)
+	for _, dto := range dtos {
+		if _, grouped := c.groupOf(dto); grouped {
+			continue
+		}
+		var name strings.Builder
+		if err := tmpl.Execute(&name, outputPatternData{DTO: dto}); err != nil {
+			return fmt.Errorf("outputPattern of %s: %w", dto, err)
+		}
+		c.outputNames[dto] = name.String()
+	}
+	return nil
+}
+
+// isPatternOutput reports whether a file name may be one outputPattern gives
+func (c *Config) isPatternOutput(name string) bool {
+	if c.outputPattern == nil {
+		return false
+	}
+	prefix, suffix := c.outputAffixes[0], c.outputAffixes[1]
+	return len(name) > len(prefix)+len(suffix) && strings.HasPrefix(name, prefix) && strings.HasSuffix(name, suffix) &&
+		!strings.HasSuffix(name, "_test.go")
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Profiles holds named sets of top-level configuration keys, by profile name
+type Profiles map[string]json.RawMessage
+
+// ProfileNames returns the names of the profiles of the configuration, sorted
+func (c *Config) ProfileNames() []string {
+	return slices.Sorted(maps.Keys(c.Profiles))
+}
+
+// WithProfile returns the configuration with the keys of a profile replacing the top-level keys of
+// the same name, e.g. a profile setting converters replaces the whole converters list
+func (c *Config) WithProfile(name string) (*Config, error) {
+	raw, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return nil, fmt.Errorf("profile %s not found, the configuration declares no profiles", name)
+		}
+		return nil, fmt.Errorf("profile %s not found, available: %s", name, strings.Join(c.ProfileNames(), ", "))
+	}
+
+	var document, overrides map[string]json.RawMessage
+	if err := json.Unmarshal(c.document, &document); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &overrides); err != nil || overrides == nil {
+		return nil, fmt.Errorf("profiles.%s: expected object", name)
+	}
+	if _, ok := overrides["profiles"]; ok {
+		return nil, fmt.Errorf("profiles.%s: profiles can't declare profiles", name)
+	}
+
+	maps.Copy(document, overrides)
+	delete(document, "profiles")
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", name, err)
+	}
+	return cfg, nil
+}
+
+// SelectsDTO reports whether the dtos of the configuration select a DTO, all DTOs being selected
+// when it lists none
+func (c *Config) SelectsDTO(name string) bool {
+	if len(c.dtoPatterns) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(c.dtoPatterns, func(pattern *regexp.Regexp) bool { return pattern.MatchString(name) })
+}
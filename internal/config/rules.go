@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Rules of the validation findings, the checks severities and baselines apply to
+const (
+	RuleNotMappedBack      = "not-mapped-back"      // interface, redacted, getter and widened fields of bidirectional DTOs
+	RuleGetterNotApplied   = "getter-not-applied"   // getter fields of patch DTOs
+	RuleRedactedNotApplied = "redacted-not-applied" // redacted fields of patch DTOs
+	RuleNestedNotApplied   = "nested-not-applied"   // nested DTO fields of patch DTOs
+	RuleRedundantConverter = "redundant-converter"  // converters between identical types
+	RuleMissingSource      = "missing-source-field" // DTO fields without source field
+	RulePointerConversion  = "pointer-conversion"   // fields mapped between a pointer and a value
+)
+
+// Rules lists the rules of the validation findings
+var Rules = []string{
+	RuleNotMappedBack, RuleGetterNotApplied, RuleRedactedNotApplied, RuleNestedNotApplied,
+	RuleRedundantConverter, RuleMissingSource, RulePointerConversion,
+}
+
+// Severities a rule can be given in severities
+const (
+	// SeverityError fails the generation
+	SeverityError = "error"
+	// SeverityWarning is reported and fails strict runs only
+	SeverityWarning = "warning"
+	// SeverityInfo is reported without failing any run
+	SeverityInfo = "info"
+	// SeverityOff isn't reported
+	SeverityOff = "off"
+)
+
+// severities lists the severities a rule can be given
+var severities = []string{SeverityError, SeverityWarning, SeverityInfo, SeverityOff}
+
+// checkSeverities checks that severities gives known rules a known severity
+func (c *Config) checkSeverities() error {
+	rules := make([]string, 0, len(c.Severities))
+	for rule := range c.Severities {
+		rules = append(rules, rule)
+	}
+	slices.Sort(rules)
+
+	for _, rule := range rules {
+		if !slices.Contains(Rules, rule) {
+			return fmt.Errorf("severities: unknown rule %q (expected one of %s)", rule, strings.Join(Rules, ", "))
+		}
+		if severity := c.Severities[rule]; !slices.Contains(severities, severity) {
+			return fmt.Errorf("severities.%s: unknown severity %q (expected one of %s)",
+				rule, severity, strings.Join(severities, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// SchemaID is the JSON Schema dialect of the configuration schema
+const SchemaID = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema describing the configuration: types, properties, items and enums
+type Schema struct {
+	Schema     string             `json:"$schema,omitempty"`
+	Title      string             `json:"title,omitempty"`
+	Type       string             `json:"type,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	// AdditionalProperties is false for structs and the schema of the values for maps
+	AdditionalProperties any      `json:"additionalProperties,omitempty"`
+	Items                *Schema  `json:"items,omitempty"`
+	Enum                 []string `json:"enum,omitempty"`
+}
+
+// enums lists the accepted values of the configuration keys taking one of a fixed set
+var enums = map[string][]string{
+	"fieldMatch":      {FieldMatchExact, FieldMatchInsensitive, FieldMatchFuzzy},
+	"fieldNameSource": {FieldNameSourceName, FieldNameSourceJSON},
+	"style":           {StyleMethod, StyleFunction},
+}
+
+// ConfigSchema returns the JSON Schema of the configuration, derived from the Config struct
+func ConfigSchema() *Schema {
+	schema := schemaOf(reflect.TypeFor[Config]())
+	schema.Schema = SchemaID
+	schema.Title = "automapper-gen configuration"
+	// Editors find the schema of a file through its $schema key
+	schema.Properties["$schema"] = &Schema{Type: "string"}
+	for key, values := range enums {
+		schema.Properties[key].Enum = values
+	}
+	schema.Properties["severities"].AdditionalProperties.(*Schema).Enum = severities
+	return schema
+}
+
+// schemaOf describes a Go type the way encoding/json reads it
+func schemaOf(t reflect.Type) *Schema {
+	if t == reflect.TypeFor[json.RawMessage]() {
+		return &Schema{} // any JSON value
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(t.Elem())}
+	case reflect.Pointer:
+		return schemaOf(t.Elem())
+	case reflect.Struct:
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+		for i := range t.NumField() {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			schema.Properties[name] = schemaOf(field.Type)
+		}
+		return schema
+	}
+	return &Schema{}
+}
+
+// MarshalSchema returns the configuration schema as indented JSON
+func MarshalSchema() ([]byte, error) {
+	data, err := json.MarshalIndent(ConfigSchema(), "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// validateDocument checks a JSON configuration against the schema, reporting every unknown key
+// and mistyped value with its path, e.g. converters[1].lossless: expected boolean, got string
+func validateDocument(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return err
+	}
+
+	var problems []string
+	ConfigSchema().validate("", document, &problems)
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validate checks a decoded JSON value against the schema, appending the problems found under path
+func (s *Schema) validate(path string, value any, problems *[]string) {
+	fail := func(format string, args ...any) {
+		where := path
+		if where == "" {
+			where = "configuration"
+		}
+		*problems = append(*problems, where+": "+fmt.Sprintf(format, args...))
+	}
+
+	// encoding/json leaves a field set to null at its default
+	if value == nil && path != "" {
+		return
+	}
+
+	if s.Type != "" && jsonType(value) != s.Type && !(s.Type == "number" && jsonType(value) == "integer") {
+		fail("expected %s, got %s", s.Type, jsonType(value))
+		return
+	}
+
+	switch value := value.(type) {
+	case string:
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, value) {
+			fail("unknown value %q (expected %s or %s)",
+				value, strings.Join(s.Enum[:len(s.Enum)-1], ", "), s.Enum[len(s.Enum)-1])
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range value {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, problems)
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+			if property, ok := s.Properties[key]; ok {
+				property.validate(keyPath, value[key], problems)
+				continue
+			}
+			switch additional := s.AdditionalProperties.(type) {
+			case *Schema:
+				additional.validate(keyPath, value[key], problems)
+			case bool:
+				if !additional {
+					*problems = append(*problems, keyPath+": unknown key"+s.suggestKey(key))
+				}
+			}
+		}
+	}
+}
+
+// suggestKey names the property a misspelled key likely meant, differing only in case
+func (s *Schema) suggestKey(key string) string {
+	for name := range s.Properties {
+		if strings.EqualFold(name, key) {
+			return fmt.Sprintf(", did you mean %s?", name)
+		}
+	}
+	return ""
+}
+
+// jsonType names the JSON Schema type of a value decoded with UseNumber
+func jsonType(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
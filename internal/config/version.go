@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// versionTerm is a comparison of a version constraint, e.g. >=0.5
+type versionTerm struct {
+	op      string
+	version string // canonical semantic version with its v prefix
+}
+
+// versionOps lists the comparison operators of version constraints, longest first
+var versionOps = []string{">=", "<=", "!=", ">", "<", "="}
+
+// parseVersionConstraint parses space-separated comparisons that all have to hold, e.g. ">=0.5 <0.7".
+// Versions may leave out their v prefix and their minor and patch numbers.
+func parseVersionConstraint(constraint string) ([]versionTerm, error) {
+	fields := strings.Fields(constraint)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty version constraint")
+	}
+
+	terms := make([]versionTerm, 0, len(fields))
+	for _, field := range fields {
+		term := versionTerm{op: "="}
+		for _, op := range versionOps {
+			if rest, ok := strings.CutPrefix(field, op); ok {
+				term.op, field = op, rest
+				break
+			}
+		}
+
+		version := "v" + strings.TrimPrefix(field, "v")
+		if !semver.IsValid(version) {
+			return nil, fmt.Errorf("invalid version %q in %q", field, constraint)
+		}
+		term.version = semver.Canonical(version)
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+// CheckVersion reports an error when a generator version doesn't satisfy requiresVersion
+func (c *Config) CheckVersion(version string) error {
+	if c.RequiresVersion == "" {
+		return nil
+	}
+	terms, err := parseVersionConstraint(c.RequiresVersion)
+	if err != nil {
+		return err
+	}
+
+	for _, term := range terms {
+		cmp := semver.Compare(version, term.version)
+		var ok bool
+		switch term.op {
+		case ">=":
+			ok = cmp >= 0
+		case "<=":
+			ok = cmp <= 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case "<":
+			ok = cmp < 0
+		default:
+			ok = cmp == 0
+		}
+		if !ok {
+			return fmt.Errorf("automapper-gen %s doesn't satisfy requiresVersion %q", version, c.RequiresVersion)
+		}
+	}
+	return nil
+}
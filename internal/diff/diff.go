@@ -0,0 +1,187 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+)
+
+// contextLines is the number of unchanged lines shown around every change
+const contextLines = 3
+
+// opKind is the kind of an edit turning the old lines into the new ones
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op is a single line of the edit script
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified returns the unified diff turning old into new, or an empty string when they are equal.
+// Removed lines are colored red and added lines green when color is set.
+func Unified(oldName, newName string, old, new []byte, color bool) string {
+	if string(old) == string(new) {
+		return ""
+	}
+
+	ops := editScript(splitLines(string(old)), splitLines(string(new)))
+
+	paint := func(text, c string) string {
+		if color {
+			return c + text + logger.ColorReset
+		}
+		return text
+	}
+
+	var b strings.Builder
+	b.WriteString(paint("--- "+oldName, logger.ColorWhite) + "\n")
+	b.WriteString(paint("+++ "+newName, logger.ColorWhite) + "\n")
+
+	for _, h := range hunks(ops) {
+		oldStart, newStart := h.oldStart, h.newStart
+		oldCount, newCount := 0, 0
+		for _, o := range ops[h.from:h.to] {
+			if o.kind != opInsert {
+				oldCount++
+			}
+			if o.kind != opDelete {
+				newCount++
+			}
+		}
+		// Empty ranges start at the line before them
+		if oldCount > 0 {
+			oldStart++
+		}
+		if newCount > 0 {
+			newStart++
+		}
+
+		b.WriteString(paint(fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, oldCount, newStart, newCount), logger.ColorCyan) + "\n")
+		for _, o := range ops[h.from:h.to] {
+			switch o.kind {
+			case opEqual:
+				b.WriteString(" " + o.line + "\n")
+			case opDelete:
+				b.WriteString(paint("-"+o.line, logger.ColorRed) + "\n")
+			case opInsert:
+				b.WriteString(paint("+"+o.line, logger.ColorGreen) + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// splitLines splits a text into lines without their line breaks
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// editScript returns the shortest edit script turning a into b, from their longest common subsequence.
+// The common prefix and suffix are skipped first, as regenerated files mostly change in a few places.
+func editScript(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, op{opEqual, line})
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of midA[i:] and midB[j:]
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	lcs := make([][]int, len(midA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(midB)+1)
+	}
+	for i := len(midA) - 1; i >= 0; i-- {
+		for j := len(midB) - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(midA) || j < len(midB) {
+		switch {
+		case i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+			ops = append(ops, op{opEqual, midA[i]})
+			i++
+			j++
+		case j == len(midB) || (i < len(midA) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{opDelete, midA[i]})
+			i++
+		default:
+			ops = append(ops, op{opInsert, midB[j]})
+			j++
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, op{opEqual, line})
+	}
+	return ops
+}
+
+// hunk is a range of the edit script printed together, with the zero-based lines it starts at
+type hunk struct {
+	from, to           int
+	oldStart, newStart int
+}
+
+// hunks groups the changes of an edit script with their context, merging changes whose context overlaps
+func hunks(ops []op) []hunk {
+	var result []hunk
+	oldLine, newLine := 0, 0
+	lastChange := -1
+
+	for i, o := range ops {
+		if o.kind != opEqual {
+			from := max(i-contextLines, 0)
+			if len(result) > 0 && from <= lastChange+contextLines+1 {
+				result[len(result)-1].to = i + 1
+			} else {
+				// Count the context lines back from the change
+				context := i - from
+				result = append(result, hunk{from: from, to: i + 1, oldStart: oldLine - context, newStart: newLine - context})
+			}
+			lastChange = i
+		}
+
+		if o.kind != opInsert {
+			oldLine++
+		}
+		if o.kind != opDelete {
+			newLine++
+		}
+	}
+
+	// Extend every hunk with the context following its last change
+	for k := range result {
+		end := result[k].to
+		for end < len(ops) && end < result[k].to+contextLines && ops[end].kind == opEqual {
+			end++
+		}
+		result[k].to = end
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+package generator
+
+import (
+	"slices"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// GenerateInterfaceAssertions generates compile-time assertions that the DTOs implement the configured mapper interfaces.
+// Generic interfaces are asserted once per source, instantiated with the source type.
+func GenerateInterfaceAssertions(
+	f *jen.File,
+	dtos []types.DTOMapping,
+	interfaces []config.MapperInterface,
+	importMap map[string]string,
+	calls callContext,
+) {
+	var assertions []jen.Code
+	for _, iface := range interfaces {
+		for _, dto := range dtos {
+			// Function-style DTOs have no methods, they are only checked when listed explicitly
+			if len(iface.DTOs) > 0 && !slices.Contains(iface.DTOs, dto.Name) ||
+				len(iface.DTOs) == 0 && calls.functions[dto.Name] {
+				continue
+			}
+
+			impl := jen.Parens(jen.Op("*").Id(dto.Name)).Parens(jen.Nil())
+			if !iface.Generic {
+				assertions = append(assertions, jen.Id("_").Add(interfaceType(iface)).Op("=").Add(impl))
+				continue
+			}
+			for _, sourceName := range dto.Sources {
+				assertions = append(assertions, jen.Id("_").Add(interfaceType(iface)).Types(
+					ParseTypeRefForJen(sourceName, importMap),
+				).Op("=").Add(impl))
+			}
+		}
+	}
+
+	if len(assertions) == 0 {
+		return
+	}
+
+	f.Comment("Compile-time checks that the DTOs implement the configured mapper interfaces")
+	f.Var().Defs(assertions...)
+	f.Line()
+}
+
+// interfaceType references a configured interface, qualified unless it is declared in the generated package
+func interfaceType(iface config.MapperInterface) *jen.Statement {
+	if iface.ImportPath == "" {
+		return jen.Id(iface.Name)
+	}
+	return jen.Qual(iface.ImportPath, iface.Name)
+}
@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// BenchmarkTestFileName returns the name of the generated benchmark file, e.g. automappers_bench_test.go
+func BenchmarkTestFileName(output string) string {
+	return strings.TrimSuffix(output, ".go") + "_bench_test.go"
+}
+
+// BenchmarkName returns the name of the benchmark of a mapping, e.g. BenchmarkUserDTO_MapFromUserDB,
+// or BenchmarkMapUserDBToUserDTO for function-style DTOs
+func BenchmarkName(dtoName, sourceName, methodName string, calls callContext) string {
+	if calls.functions[dtoName] {
+		return "Benchmark" + MapFunctionName(sourceName, dtoName)
+	}
+	return "Benchmark" + dtoName + "_" + methodName
+}
+
+// GenerateBenchmarks creates a test file with a benchmark per mapping, run on populated sources
+func GenerateBenchmarks(
+	dtos []types.DTOMapping,
+	sources map[string]types.SourceStruct,
+	cfg *config.Config,
+	pkgName string,
+) *jen.File {
+	f := newGeneratedFile(pkgName, cfg)
+	importMap := buildImportMap(sources)
+	calls := callContext{functions: FunctionStyleDTOs(dtos, cfg), names: cfg.MethodNames()}
+
+	dtoNames := make(map[string]bool)
+	for _, dto := range dtos {
+		dtoNames[dto.Name] = true
+	}
+
+	benchmarks := 0
+	for _, dto := range dtos {
+		for _, sourceName := range dto.Sources {
+			methodName := mapFromMethodName(dto, sourceName, sources[sourceName], dtoNames, calls)
+			generateBenchmark(f, dto, sourceName, methodName, importMap, cfg, calls)
+			benchmarks++
+		}
+	}
+
+	logger.Verbose("Generated %d benchmarks", benchmarks)
+	return f
+}
+
+// generateBenchmark generates a benchmark mapping a populated source to a new DTO
+func generateBenchmark(
+	f *jen.File,
+	dto types.DTOMapping,
+	sourceName, methodName string,
+	importMap map[string]string,
+	cfg *config.Config,
+	calls callContext,
+) {
+	benchName := BenchmarkName(dto.Name, sourceName, methodName, calls)
+
+	mapping := []jen.Code{
+		jen.Var().Id("d").Id(dto.Name),
+		jen.If(
+			jen.Err().Op(":=").Id("d").Dot(methodName).Call(jen.Op("&").Id("src")),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Id("b").Dot("Fatal").Call(jen.Err()),
+		),
+	}
+	if calls.functions[dto.Name] {
+		mapping = []jen.Code{
+			jen.If(
+				jen.List(jen.Id("_"), jen.Err()).Op(":=").Id(MapFunctionName(sourceName, dto.Name)).Call(jen.Op("&").Id("src")),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Id("b").Dot("Fatal").Call(jen.Err()),
+			),
+		}
+	}
+
+	f.Comment(fmt.Sprintf("%s measures mapping a populated %s to %s", benchName, sourceName, dto.Name))
+	f.Func().Id(benchName).Params(jen.Id("b").Op("*").Qual("testing", "B")).Block(
+		jen.Var().Id("src").Add(ParseTypeForJen(sourceName, importMap)),
+		jen.Id("populateFixture").Call(
+			jen.Id("b"),
+			jen.Qual("reflect", "ValueOf").Call(jen.Op("&").Id("src")).Dot("Elem").Call(),
+			jen.Lit(0),
+		),
+		jen.Line(),
+		jen.Id("b").Dot("ReportAllocs").Call(),
+		jen.Id("b").Dot("ResetTimer").Call(),
+		countLoop(cfg, "", jen.Id("b").Dot("N"), mapping...),
+	)
+	f.Line()
+}
@@ -0,0 +1,188 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// CloneableDTOs returns the DTOs generating a Clone method
+func CloneableDTOs(dtos []types.DTOMapping) map[string]bool {
+	cloneable := make(map[string]bool)
+	for _, dto := range dtos {
+		if dto.HasMode(types.ModeClone) {
+			cloneable[dto.Name] = true
+		}
+	}
+	return cloneable
+}
+
+// GenerateCloneMethod generates a Clone method returning a deep copy of a DTO.
+// The struct is copied first, fields sharing memory with the original are then replaced by copies.
+func GenerateCloneMethod(
+	f *jen.File, dto types.DTOMapping, cloneable map[string]bool, importMap map[string]string, cfg *config.Config,
+) {
+	statements := []jen.Code{
+		jen.If(jen.Id("d").Op("==").Nil()).Block(
+			jen.Return(jen.Nil()),
+		),
+		jen.Id("c").Op(":=").Op("*").Id("d"),
+	}
+
+	for _, field := range dto.Fields {
+		statements = append(statements, buildCloneStatements(func() *jen.Statement {
+			return jen.Id("c").Dot(field.Name)
+		}, field.Type, cloneable, importMap, cfg, 0)...)
+	}
+
+	statements = append(statements, jen.Return(jen.Op("&").Id("c")))
+
+	f.Comment(fmt.Sprintf("Clone returns a deep copy of %s", dto.Name))
+	f.Func().Params(
+		jen.Id("d").Op("*").Id(dto.Name),
+	).Id("Clone").Params().Op("*").Id(dto.Name).Block(statements...)
+	f.Line()
+}
+
+// buildCloneStatements creates statements replacing a shallow copy of the given type with a deep one.
+// Depth numbers the loop and temporary variables of nested types.
+func buildCloneStatements(
+	target func() *jen.Statement, typeName string, cloneable map[string]bool,
+	importMap map[string]string, cfg *config.Config, depth int,
+) []jen.Code {
+	if !needsDeepCopy(typeName, cloneable) {
+		return nil
+	}
+
+	index := fmt.Sprintf("i%d", depth)
+	value := fmt.Sprintf("v%d", depth)
+
+	if elemType, ok := strings.CutPrefix(typeName, "*"); ok {
+		// Cloneable DTOs copy themselves
+		if cloneable[elemType] {
+			return []jen.Code{target().Op("=").Add(target()).Dot("Clone").Call()}
+		}
+
+		body := []jen.Code{jen.Id(value).Op(":=").Op("*").Add(target())}
+		body = append(body, buildCloneStatements(func() *jen.Statement { return jen.Id(value) }, elemType, cloneable, importMap, cfg, depth+1)...)
+		body = append(body, target().Op("=").Op("&").Id(value))
+		return []jen.Code{jen.If(target().Op("!=").Nil()).Block(body...)}
+	}
+
+	if elemType, ok := strings.CutPrefix(typeName, "[]"); ok {
+		statements := []jen.Code{target().Op("=").Qual("slices", "Clone").Call(target())}
+		if !cfg.GoAtLeast(config.GoSlicesMaps) {
+			// A nil slice stays nil like with slices.Clone
+			statements = []jen.Code{target().Op("=").Append(target().Index(jen.Empty(), jen.Lit(0), jen.Lit(0)), target().Op("..."))}
+		}
+		return append(statements, buildElementCloneLoop(target, index, elemType, cloneable, importMap, cfg, depth)...)
+	}
+
+	if keyType, valueType, ok := splitMapType(typeName); ok {
+		key := fmt.Sprintf("k%d", depth)
+		statements := []jen.Code{target().Op("=").Qual("maps", "Clone").Call(target())}
+		if !cfg.GoAtLeast(config.GoSlicesMaps) {
+			copied := fmt.Sprintf("m%d", depth)
+			statements = []jen.Code{jen.If(target().Op("!=").Nil()).Block(
+				jen.Id(copied).Op(":=").Make(
+					jen.Map(ParseTypeForJen(keyType, importMap)).Add(ParseTypeForJen(valueType, importMap)),
+					jen.Len(target()),
+				),
+				jen.For(jen.List(jen.Id(key), jen.Id(value)).Op(":=").Range().Add(target())).Block(
+					jen.Id(copied).Index(jen.Id(key)).Op("=").Id(value),
+				),
+				target().Op("=").Id(copied),
+			)}
+		}
+		if !needsDeepCopy(valueType, cloneable) {
+			return statements
+		}
+
+		// Map values are not addressable, they are copied out and written back
+		body := buildCloneStatements(func() *jen.Statement { return jen.Id(value) }, valueType, cloneable, importMap, cfg, depth+1)
+		body = append(body, target().Index(jen.Id(key)).Op("=").Id(value))
+		return append(statements, jen.For(jen.List(jen.Id(key), jen.Id(value)).Op(":=").Range().Add(target())).Block(body...))
+	}
+
+	// Arrays are copied with the struct, only their elements may need a deep copy
+	if elemType, ok := arrayElemType(typeName); ok {
+		return buildElementCloneLoop(target, index, elemType, cloneable, importMap, cfg, depth)
+	}
+
+	// A cloneable DTO stored by value
+	return []jen.Code{target().Op("=").Op("*").Add(target()).Dot("Clone").Call()}
+}
+
+// buildElementCloneLoop creates a loop replacing every element of a slice or array with a deep copy
+func buildElementCloneLoop(
+	target func() *jen.Statement, index, elemType string, cloneable map[string]bool,
+	importMap map[string]string, cfg *config.Config, depth int,
+) []jen.Code {
+	body := buildCloneStatements(func() *jen.Statement {
+		return target().Index(jen.Id(index))
+	}, elemType, cloneable, importMap, cfg, depth+1)
+	if len(body) == 0 {
+		return nil
+	}
+	return []jen.Code{jen.For(jen.Id(index).Op(":=").Range().Add(target())).Block(body...)}
+}
+
+// needsDeepCopy reports whether a copy of a value of the given type still shares memory with the original
+func needsDeepCopy(typeName string, cloneable map[string]bool) bool {
+	switch {
+	case strings.HasPrefix(typeName, "*"), strings.HasPrefix(typeName, "[]"), strings.HasPrefix(typeName, "map["):
+		return true
+	}
+	if elemType, ok := arrayElemType(typeName); ok {
+		return needsDeepCopy(elemType, cloneable)
+	}
+	return cloneable[typeName]
+}
+
+// splitMapType splits a map[K]V type into its key and value types
+func splitMapType(typeName string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(typeName, "map")
+	if !ok {
+		return "", "", false
+	}
+	end := closingBracket(rest)
+	if end < 0 {
+		return "", "", false
+	}
+	return rest[1:end], rest[end+1:], true
+}
+
+// arrayElemType returns the element type of a [N]T array type
+func arrayElemType(typeName string) (string, bool) {
+	if !strings.HasPrefix(typeName, "[") || strings.HasPrefix(typeName, "[]") {
+		return "", false
+	}
+	end := closingBracket(typeName)
+	if end < 0 {
+		return "", false
+	}
+	return typeName[end+1:], true
+}
+
+// closingBracket returns the index of the bracket closing the one the string starts with, or -1
+func closingBracket(s string) int {
+	if !strings.HasPrefix(s, "[") {
+		return -1
+	}
+	level := 0
+	for i, r := range s {
+		switch r {
+		case '[':
+			level++
+		case ']':
+			level--
+			if level == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
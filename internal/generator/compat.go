@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"github.com/dave/jennifer/jen"
+)
+
+// goIsExported is the Go version adding reflect.StructField.IsExported
+const goIsExported = "1.17"
+
+// anyType returns the any type, written interface{} for Go versions before generics
+func anyType(cfg *config.Config) *jen.Statement {
+	if cfg.GoAtLeast(config.GoGenerics) {
+		return jen.Any()
+	}
+	return jen.Interface()
+}
+
+// countLoop returns a loop running body n times, counting with index when it isn't empty.
+// Go versions before ranging over integers get a three-clause loop.
+func countLoop(cfg *config.Config, index string, n jen.Code, body ...jen.Code) *jen.Statement {
+	if cfg.GoAtLeast(config.GoRangeInt) {
+		if index == "" {
+			return jen.For(jen.Range().Add(n)).Block(body...)
+		}
+		return jen.For(jen.Id(index).Op(":=").Range().Add(n)).Block(body...)
+	}
+	if index == "" {
+		index = "i"
+	}
+	return jen.For(
+		jen.Id(index).Op(":=").Lit(0),
+		jen.Id(index).Op("<").Add(n),
+		jen.Id(index).Op("++"),
+	).Block(body...)
+}
+
+// shadowLoopVar copies a loop variable captured by a goroutine, every iteration sharing the same
+// variable before Go 1.22
+func shadowLoopVar(cfg *config.Config, name string) jen.Code {
+	if cfg.GoAtLeast(config.GoRangeInt) {
+		return jen.Null()
+	}
+	return jen.Id(name).Op(":=").Id(name)
+}
+
+// isExportedField returns the check of a reflect.StructField being exported, comparing its
+// package path for Go versions without IsExported
+func isExportedField(cfg *config.Config, field jen.Code) *jen.Statement {
+	if cfg.GoAtLeast(goIsExported) {
+		return jen.Add(field).Dot("IsExported").Call()
+	}
+	return jen.Add(field).Dot("PkgPath").Op("==").Lit("")
+}
+
+// reflectPointer returns the reflect.Kind of pointers, reflect.Ptr before Go 1.18 named it Pointer
+func reflectPointer(cfg *config.Config) *jen.Statement {
+	if cfg.GoAtLeast(config.GoGenerics) {
+		return jen.Qual("reflect", "Pointer")
+	}
+	return jen.Qual("reflect", "Ptr")
+}
@@ -0,0 +1,189 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// CoverageTestFileName returns the name of the generated coverage test file, e.g. automappers_coverage_test.go
+func CoverageTestFileName(output string) string {
+	return strings.TrimSuffix(output, ".go") + "_coverage_test.go"
+}
+
+// CoverageTestName returns the name of the test checking the fields assigned by a mapping,
+// e.g. TestUserDTOMapFromUserDBCoverage
+func CoverageTestName(dtoName, sourceName, methodName string, calls callContext) string {
+	if calls.functions[dtoName] {
+		return "Test" + MapFunctionName(sourceName, dtoName) + "Coverage"
+	}
+	return "Test" + dtoName + methodName + "Coverage"
+}
+
+// GenerateCoverageTests creates a test file checking that every mapping assigns all non-ignored
+// DTO fields from a populated source
+func GenerateCoverageTests(
+	dtos []types.DTOMapping,
+	sources map[string]types.SourceStruct,
+	cfg *config.Config,
+	pkgName string,
+) *jen.File {
+	f := newGeneratedFile(pkgName, cfg)
+	importMap := buildImportMap(sources)
+	calls := callContext{functions: FunctionStyleDTOs(dtos, cfg), names: cfg.MethodNames()}
+
+	dtoNames := make(map[string]bool)
+	for _, dto := range dtos {
+		dtoNames[dto.Name] = true
+	}
+
+	tests := 0
+	for _, dto := range dtos {
+		m := matcher.New(cfg, dto)
+		for _, sourceName := range dto.Sources {
+			var ignored []jen.Code
+			for _, field := range dto.Fields {
+				if field.Ignore || !fixtureFills(m, field, sources[sourceName]) {
+					ignored = append(ignored, jen.Lit(field.Name))
+				}
+			}
+
+			methodName := mapFromMethodName(dto, sourceName, sources[sourceName], dtoNames, calls)
+			generateCoverageTest(f, dto, sourceName, methodName, ignored, importMap, calls)
+			tests++
+		}
+	}
+
+	generateCheckCoverage(f, cfg)
+
+	logger.Verbose("Generated %d coverage tests", tests)
+	return f
+}
+
+// fixtureFills reports whether populateFixture makes the source of a DTO field non-zero. Getters read
+// unexported state, unexported fields aren't reachable through reflection and interfaces, funcs and
+// channels are left nil. DTO fields without a source stay checked, they are what the tests catch.
+func fixtureFills(m *matcher.Matcher, field types.FieldInfo, source types.SourceStruct) bool {
+	name, sourceField, exists := m.Resolve(field, source)
+	if !exists {
+		return true
+	}
+	if _, isGetter := matcher.GetterName(name); isGetter || !ast.IsExported(name) {
+		return false
+	}
+	if sourceField.IsSlice {
+		return true
+	}
+	switch sourceField.Kind {
+	case types.KindFunc, types.KindChan, types.KindInterface:
+		return false
+	}
+	underlying := sourceField.BaseIdentity.Underlying
+	return !strings.HasPrefix(underlying, "interface{") && underlying != "any" && sourceField.BaseType != "any"
+}
+
+// generateCoverageTest generates a test mapping a populated source and reporting the DTO fields left zero
+func generateCoverageTest(
+	f *jen.File,
+	dto types.DTOMapping,
+	sourceName, methodName string,
+	ignored []jen.Code,
+	importMap map[string]string,
+	calls callContext,
+) {
+	testName := CoverageTestName(dto.Name, sourceName, methodName, calls)
+
+	mapping := []jen.Code{
+		jen.Var().Id("d").Id(dto.Name),
+		jen.If(
+			jen.Err().Op(":=").Id("d").Dot(methodName).Call(jen.Op("&").Id("src")),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Id("t").Dot("Fatalf").Call(jen.Lit(fmt.Sprintf("mapping a populated %s: %%v", sourceName)), jen.Err()),
+		),
+	}
+	if calls.functions[dto.Name] {
+		mapping = []jen.Code{
+			jen.List(jen.Id("d"), jen.Err()).Op(":=").Id(MapFunctionName(sourceName, dto.Name)).Call(jen.Op("&").Id("src")),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Id("t").Dot("Fatalf").Call(jen.Lit(fmt.Sprintf("mapping a populated %s: %%v", sourceName)), jen.Err()),
+			),
+		}
+	}
+
+	body := []jen.Code{
+		jen.Var().Id("src").Add(ParseTypeForJen(sourceName, importMap)),
+		jen.Id("populateFixture").Call(
+			jen.Id("t"),
+			jen.Qual("reflect", "ValueOf").Call(jen.Op("&").Id("src")).Dot("Elem").Call(),
+			jen.Lit(0),
+		),
+		jen.Line(),
+	}
+	body = append(body, mapping...)
+	body = append(body,
+		jen.Line(),
+		jen.Id("checkCoverage").Call(append([]jen.Code{
+			jen.Id("t"),
+			jen.Qual("reflect", "ValueOf").Call(jen.Id("d")),
+		}, ignored...)...),
+	)
+
+	f.Comment(fmt.Sprintf("%s checks that %s assigns every non-ignored field of %s", testName, methodName, dto.Name))
+	f.Func().Id(testName).Params(jen.Id("t").Op("*").Qual("testing", "T")).Block(body...)
+	f.Line()
+}
+
+// generateCheckCoverage generates the helper reporting the DTO fields left zero by a mapping
+func generateCheckCoverage(f *jen.File, cfg *config.Config) {
+	v := func() *jen.Statement { return jen.Id("v") }
+
+	// Go versions without the slices package look the ignored fields up in a loop
+	ignored := jen.Qual("slices", "Contains").Call(jen.Id("ignored"), jen.Id("field").Dot("Name"))
+	if !cfg.GoAtLeast(config.GoSlicesMaps) {
+		ignored = jen.Id("isIgnoredField").Call(jen.Id("ignored"), jen.Id("field").Dot("Name"))
+	}
+
+	f.Comment("checkCoverage reports the exported fields of v that are still zero, apart from the ignored ones")
+	f.Func().Id("checkCoverage").Params(
+		jen.Id("t").Op("*").Qual("testing", "T"),
+		jen.Id("v").Qual("reflect", "Value"),
+		jen.Id("ignored").Op("...").String(),
+	).Block(
+		jen.Id("t").Dot("Helper").Call(),
+		jen.Line(),
+		countLoop(cfg, "i", v().Dot("NumField").Call(),
+			jen.Id("field").Op(":=").Add(v()).Dot("Type").Call().Dot("Field").Call(jen.Id("i")),
+			jen.If(
+				isExportedField(cfg, jen.Id("field")).
+					Op("&&").Op("!").Add(ignored).
+					Op("&&").Add(v()).Dot("Field").Call(jen.Id("i")).Dot("IsZero").Call(),
+			).Block(
+				jen.Id("t").Dot("Errorf").Call(jen.Lit("%s.%s is not assigned by the mapping"), v().Dot("Type").Call(), jen.Id("field").Dot("Name")),
+			),
+		),
+	)
+	f.Line()
+
+	if !cfg.GoAtLeast(config.GoSlicesMaps) {
+		f.Comment("isIgnoredField reports whether name is one of the ignored fields")
+		f.Func().Id("isIgnoredField").Params(
+			jen.Id("ignored").Index().String(),
+			jen.Id("name").String(),
+		).Bool().Block(
+			jen.For(jen.List(jen.Id("_"), jen.Id("field")).Op(":=").Range().Id("ignored")).Block(
+				jen.If(jen.Id("field").Op("==").Id("name")).Block(
+					jen.Return(jen.True()),
+				),
+			),
+			jen.Return(jen.False()),
+		)
+		f.Line()
+	}
+}
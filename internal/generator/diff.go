@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"fmt"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// DiffFunctionName returns the name of the function comparing a DTO with a source,
+// e.g. DiffUserDTO, or DiffUserDTOFromUserDB when the DTO has several sources
+func DiffFunctionName(dto types.DTOMapping, sourceName string) string {
+	if len(dto.Sources) == 1 {
+		return "Diff" + dto.Name
+	}
+	return "Diff" + dto.Name + "From" + ExtractTypeNameWithoutPackage(sourceName)
+}
+
+// GenerateFieldDiffType generates the FieldDiff type reported by Diff functions,
+// an alias of the runtime package's when one is configured
+func GenerateFieldDiffType(f *jen.File, cfg *config.Config) {
+	if cfg.RuntimePackage != "" {
+		generateRuntimeAlias(f, cfg, "FieldDiff")
+		return
+	}
+	writeFieldDiffType(f, cfg)
+}
+
+// writeFieldDiffType declares the FieldDiff type
+func writeFieldDiffType(f *jen.File, cfg *config.Config) {
+	f.Comment("FieldDiff describes a mapped field whose value differs between a DTO and its source")
+	f.Type().Id("FieldDiff").Struct(
+		jen.Id("Field").String().Comment("name of the DTO field"),
+		jen.Id("DTO").Add(anyType(cfg)).Comment("value of the DTO"),
+		jen.Id("Source").Add(anyType(cfg)).Comment("value mapped from the source"),
+	)
+	f.Line()
+}
+
+// GenerateDiffFunction generates a function mapping a source into a new DTO and reporting
+// the mapped fields that differ from the given DTO
+func GenerateDiffFunction(
+	f *jen.File,
+	dto types.DTOMapping,
+	source types.SourceStruct,
+	sourceName, methodName string,
+	cfg *config.Config,
+	importMap map[string]string,
+	calls callContext,
+) {
+	funcName := DiffFunctionName(dto, sourceName)
+
+	statements := []jen.Code{
+		jen.If(jen.Id("d").Op("==").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Qual("errors", "New").Call(jen.Lit("DTO is nil"))),
+		),
+		jen.Id("mapped").Op(":=").Op("&").Id(dto.Name).Values(),
+		jen.If(
+			jen.Err().Op(":=").Add(mapIntoCall(dto.Name, sourceName, methodName, jen.Id("mapped"), jen.Id("src"), calls)),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return(jen.Nil(), jen.Err()),
+		),
+		jen.Line(),
+		jen.Var().Id("diffs").Index().Id("FieldDiff"),
+	}
+
+	m := matcher.New(cfg, dto)
+	for _, dtoField := range dto.Fields {
+		if dtoField.Ignore {
+			continue
+		}
+
+		// Fields without a source are never mapped, so there is nothing to compare
+		if _, _, exists := m.Resolve(dtoField, source); !exists {
+			continue
+		}
+
+		statements = append(statements,
+			jen.If(jen.Op("!").Qual("reflect", "DeepEqual").Call(
+				jen.Id("d").Dot(dtoField.Name),
+				jen.Id("mapped").Dot(dtoField.Name),
+			)).Block(
+				jen.Id("diffs").Op("=").Append(jen.Id("diffs"), jen.Id("FieldDiff").Values(
+					jen.Id("Field").Op(":").Lit(dtoField.Name),
+					jen.Id("DTO").Op(":").Id("d").Dot(dtoField.Name),
+					jen.Id("Source").Op(":").Id("mapped").Dot(dtoField.Name),
+				)),
+			),
+		)
+	}
+
+	statements = append(statements, jen.Return(jen.Id("diffs"), jen.Nil()))
+
+	f.Comment(fmt.Sprintf("%s reports the mapped fields of %s that differ from %s, converters applied", funcName, dto.Name, sourceName))
+	f.Func().Id(funcName).Params(
+		jen.Id("d").Op("*").Id(dto.Name),
+		jen.Id("src").Op("*").Add(ParseTypeRefForJen(sourceName, importMap)),
+	).Params(jen.Index().Id("FieldDiff"), jen.Error()).Block(statements...)
+	f.Line()
+}
@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// UsesMappingError reports whether a DTO field is converted by an error-returning converter or inverter
+func UsesMappingError(dtos []types.DTOMapping, cfg *config.Config, functions map[string]types.FunctionInfo) bool {
+	converterMap := buildConverterMap(cfg)
+	returnsError := func(function string) bool {
+		fn, ok := functions[function]
+		return function != "" && !(ok && parser.IsSafeConverterSignature(fn))
+	}
+
+	for _, dto := range dtos {
+		reverse := dto.HasMode(types.ModeBidirectional) || dto.HasMode(types.ModeMerge) || dto.HasMode(types.ModePatch)
+		for _, field := range dto.Fields {
+			conv, ok := converterMap[field.ConverterTag]
+			if field.Ignore || !ok {
+				continue
+			}
+			if returnsError(conv.Function) || (reverse && returnsError(conv.Inverter)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GenerateMappingErrorType generates the MappingError type returned when a converter fails,
+// an alias of the runtime package's when one is configured
+func GenerateMappingErrorType(f *jen.File, cfg *config.Config) {
+	if cfg.RuntimePackage != "" {
+		generateRuntimeAlias(f, cfg, "MappingError")
+		return
+	}
+	writeMappingErrorType(f, cfg)
+}
+
+// writeMappingErrorType declares the MappingError type
+func writeMappingErrorType(f *jen.File, cfg *config.Config) {
+	f.Comment("MappingError describes a field whose converter failed during a mapping")
+	f.Type().Id("MappingError").Struct(
+		jen.Id("DTO").String().Comment("name of the DTO type"),
+		jen.Id("Source").String().Comment("name of the source type"),
+		jen.Id("Field").String().Comment("name of the DTO field"),
+		jen.Id("SourceField").String().Comment("name of the source field"),
+		jen.Id("Converter").String().Comment("name of the failed converter function"),
+		jen.Id("Err").Error().Comment("error returned by the converter"),
+	)
+	f.Line()
+
+	f.Func().Params(jen.Id("e").Op("*").Id("MappingError")).Id("Error").Params().String().Block(
+		jen.Return(buildConversionMessage(cfg.ErrorMessages.Conversion)),
+	)
+	f.Line()
+
+	f.Func().Params(jen.Id("e").Op("*").Id("MappingError")).Id("Unwrap").Params().Error().Block(
+		jen.Return(jen.Id("e").Dot("Err")),
+	)
+	f.Line()
+}
+
+// buildConversionMessage creates the expression formatting a MappingError with the configured template
+func buildConversionMessage(template string) jen.Code {
+	if template == "" {
+		return jen.Qual("fmt", "Sprintf").Call(jen.Lit("converting field %s: %v"), jen.Id("e").Dot("Field"), jen.Id("e").Dot("Err"))
+	}
+
+	fields := map[string]string{
+		config.PlaceholderDTO:         "DTO",
+		config.PlaceholderSource:      "Source",
+		config.PlaceholderField:       "Field",
+		config.PlaceholderSourceField: "SourceField",
+		config.PlaceholderConverter:   "Converter",
+		config.PlaceholderErr:         "Err",
+	}
+
+	var format strings.Builder
+	var args []jen.Code
+	parts := config.SplitTemplate(template)
+	for i, part := range parts {
+		if i%2 == 0 {
+			format.WriteString(strings.ReplaceAll(part, "%", "%%"))
+			continue
+		}
+		format.WriteString("%v")
+		args = append(args, jen.Id("e").Dot(fields[part]))
+	}
+
+	if len(args) == 0 {
+		return jen.Lit(template)
+	}
+	return jen.Qual("fmt", "Sprintf").Call(append([]jen.Code{jen.Lit(format.String())}, args...)...)
+}
+
+// nilSourceError creates the error returned for a nil source, rendered from the configured template
+func nilSourceError(cfg *config.Config, dtoName, sourceName string) jen.Code {
+	message := "source is nil"
+	if cfg.ErrorMessages.NilSource != "" {
+		message = strings.NewReplacer(
+			"{"+config.PlaceholderDTO+"}", dtoName,
+			"{"+config.PlaceholderSource+"}", sourceName,
+		).Replace(cfg.ErrorMessages.NilSource)
+	}
+	return jen.Qual("errors", "New").Call(jen.Lit(message))
+}
+
+// mappingError creates the MappingError value wrapping the err variable of a failed converter
+func mappingError(dtoName, sourceName, fieldName, sourceFieldName, converter string) *jen.Statement {
+	return jen.Op("&").Id("MappingError").Values(
+		jen.Id("DTO").Op(":").Lit(dtoName),
+		jen.Id("Source").Op(":").Lit(sourceName),
+		jen.Id("Field").Op(":").Lit(fieldName),
+		jen.Id("SourceField").Op(":").Lit(sourceFieldName),
+		jen.Id("Converter").Op(":").Lit(funcName(converter)),
+		jen.Id("Err").Op(":").Err(),
+	)
+}
@@ -0,0 +1,196 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+)
+
+// Explain describes how every field of a DTO is mapped from each of its sources, and back into them
+// for bidirectional DTOs: the resolved source field, the converter or inverter, the pointer
+// adjustments and the reason a field is skipped. DTOs of the package may be sources themselves.
+func Explain(
+	dto types.DTOMapping,
+	dtos []types.DTOMapping,
+	sources map[string]types.SourceStruct,
+	cfg *config.Config,
+	functions map[string]types.FunctionInfo,
+) ([]string, error) {
+	dtoNames := make(map[string]bool)
+	for _, other := range dtos {
+		dtoNames[other.Name] = true
+	}
+	calls := callContext{functions: FunctionStyleDTOs(dtos, cfg), names: cfg.MethodNames()}
+
+	var lines []string
+	for _, sourceName := range dto.Sources {
+		source, ok := sources[sourceName]
+		if !ok {
+			return nil, fmt.Errorf("source struct %s not found for DTO %s", sourceName, dto.Name)
+		}
+
+		methodName := mapFromMethodName(dto, sourceName, source, dtoNames, calls)
+		if calls.functions[dto.Name] {
+			methodName = MapFunctionName(sourceName, dto.Name)
+		}
+		lines = append(lines, fmt.Sprintf("%s from %s (%s)", dto.Name, sourceName, methodName))
+		lines = append(lines, explainTable(dto, source, cfg, functions, false)...)
+
+		if dto.HasMode(types.ModeBidirectional) {
+			mapToMethodName := reverseMethodName(dto, sourceName, source, dtoNames, calls)
+			lines = append(lines, "", fmt.Sprintf("%s back into %s (%s)", dto.Name, sourceName, mapToMethodName))
+			lines = append(lines, explainTable(dto, source, cfg, functions, true)...)
+		}
+		lines = append(lines, "")
+	}
+
+	if len(dto.PrioritySources) > 1 {
+		lines = append(lines, fmt.Sprintf("%s falls back through %s (%s)",
+			dto.Name, strings.Join(dto.PrioritySources, " > "), FallbackMethodName(dto.PrioritySources)), "")
+	}
+	if dto.Via != "" {
+		lines = append(lines, fmt.Sprintf("%s is also mapped through %s", dto.Name, dto.Via), "")
+	}
+
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines, nil
+}
+
+// explainTable lists the mapping of every DTO field from a source, or back into it when reverse is set,
+// with the field types and notes on converters and pointers
+func explainTable(
+	dto types.DTOMapping,
+	source types.SourceStruct,
+	cfg *config.Config,
+	functions map[string]types.FunctionInfo,
+	reverse bool,
+) []string {
+	converterMap := buildConverterMap(cfg)
+	m := matcher.New(cfg, dto)
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	if reverse {
+		fmt.Fprintln(w, "  DTO field\tType\tDestination field\tType\tMapping\tNotes")
+	} else {
+		fmt.Fprintln(w, "  DTO field\tType\tSource field\tType\tMapping\tNotes")
+	}
+
+	for _, dtoField := range dto.Fields {
+		sourceFieldName, sourceType, mapping := "-", "-", ""
+		var notes []string
+
+		name, sourceField, exists := m.Resolve(dtoField, source)
+		switch {
+		case dtoField.Ignore:
+			mapping = "skipped: ignored"
+		case !exists:
+			mapping = "skipped: not found in source"
+			if reverse {
+				mapping = "skipped: not found in destination"
+			}
+		default:
+			sourceFieldName, sourceType = name, sourceField.Type
+			if reverse {
+				mapping = describeReverseMapping(dtoField, name, converterMap)
+			} else {
+				mapping = describeMapping(dtoField, converterMap)
+			}
+			if !strings.HasPrefix(mapping, "skipped") {
+				notes = explainNotes(dtoField, sourceField, converterMap, functions, reverse, cfg.NilPointersForNull)
+			}
+		}
+
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t%s\n",
+			dtoField.Name, dtoField.Type, sourceFieldName, sourceType, mapping, strings.Join(notes, "; "))
+	}
+	w.Flush()
+
+	// Rows without notes end with the padding of the empty column
+	rows := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	for i, row := range rows {
+		rows[i] = strings.TrimRight(row, " ")
+	}
+	return rows
+}
+
+// explainNotes describes the converter and the pointer adjustments of a mapped field
+func explainNotes(
+	dtoField types.FieldInfo,
+	sourceField types.FieldTypeInfo,
+	converterMap map[string]config.ConverterDef,
+	functions map[string]types.FunctionInfo,
+	reverse bool,
+	nilPointers bool,
+) []string {
+	var notes []string
+	pointerConverter := false // the converter handles the pointers itself
+
+	if conv, ok := converterMap[dtoField.ConverterTag]; ok && dtoField.NestedDTO == "" && dtoField.Redact == "" {
+		function := conv.Function
+		if reverse {
+			function = conv.Inverter
+		}
+		fn, known := functions[function]
+		switch {
+		case !known:
+			notes = append(notes, fmt.Sprintf("calls %s, not found in the package", function))
+		case parser.IsSafeConverterSignature(fn):
+			notes = append(notes, fmt.Sprintf("calls %s, cannot fail", function))
+		default:
+			notes = append(notes, fmt.Sprintf("calls %s, may fail", function))
+		}
+		if !reverse && parser.TakesPointer(fn) {
+			notes = append(notes, "takes a pointer")
+			pointerConverter = true
+		}
+		if !reverse && parser.ReturnsPointer(fn) {
+			notes = append(notes, "returns a pointer")
+			pointerConverter = true
+		}
+		if !reverse && conv.Inverter != "" {
+			notes = append(notes, "inverter "+conv.Inverter)
+		}
+		if conv.Lossless {
+			notes = append(notes, "lossless")
+		}
+	}
+
+	dtoIsPointer := strings.HasPrefix(dtoField.Type, "*")
+	fromPointer, toPointer := sourceField.IsPointer, dtoIsPointer
+	if reverse {
+		fromPointer, toPointer = dtoIsPointer, sourceField.IsPointer
+	}
+	switch {
+	case pointerConverter:
+	case fromPointer && !toPointer:
+		notes = append(notes, "dereferenced, nil leaves the zero value")
+	case !fromPointer && toPointer:
+		notes = append(notes, "pointer to a copy")
+	case fromPointer && toPointer && !reverse && !nilPointers:
+		notes = append(notes, "nil gives a pointer to the zero value")
+	case fromPointer && toPointer && dtoField.ConverterTag != "":
+		notes = append(notes, "nil stays nil")
+	}
+
+	if dtoField.ConverterTag == "" && dtoField.NestedDTO == "" && len(dtoField.TypeCases) == 0 && dtoField.Redact == "" {
+		switch {
+		case types.NeedsConversion(dtoField, sourceField):
+			notes = append(notes, "converted, the types share their underlying type")
+		case types.NumericWidening(dtoField, sourceField):
+			notes = append(notes, "cast to the wider numeric type")
+		case ExtractBaseType(dtoField.Type) != sourceField.BaseType && !dtoField.BaseIdentity.Identical(sourceField.BaseIdentity):
+			notes = append(notes, "types differ, assigned as is")
+		}
+	}
+
+	return notes
+}
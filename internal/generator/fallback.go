@@ -0,0 +1,131 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// FallbackMethodName builds the method name for a priority-ordered multi-source mapping
+func FallbackMethodName(priority []string) string {
+	names := make([]string, len(priority))
+	for i, sourceName := range priority {
+		names[i] = ExtractTypeNameWithoutPackage(sourceName)
+	}
+	return "MapFrom" + strings.Join(names, "Or")
+}
+
+// GenerateFallbackMethod generates a method taking all priority sources and using,
+// for every field, the first source that holds a non-zero value.
+// It reports whether the generated code relies on the generic zero-value helper.
+func GenerateFallbackMethod(
+	f *jen.File,
+	dto types.DTOMapping,
+	sources map[string]types.SourceStruct,
+	methodName string,
+	cfg *config.Config,
+	importMap map[string]string,
+	functions map[string]types.FunctionInfo,
+	calls callContext,
+) bool {
+	params := make([]jen.Code, len(dto.PrioritySources))
+	sourceNames := make([]string, len(dto.PrioritySources))
+	for i, sourceName := range dto.PrioritySources {
+		params[i] = jen.Id(fallbackParamName(i)).Op("*").Add(ParseTypeRefForJen(sourceName, importMap))
+		sourceNames[i] = sources[sourceName].Name
+	}
+
+	f.Comment(fmt.Sprintf("%s maps to %s using the first non-zero value of %s",
+		methodName, dto.Name, strings.Join(dto.PrioritySources, ", ")))
+
+	methodBody, usesHelper := buildFallbackMethodBody(dto, sources, cfg, importMap, functions, calls)
+	methodBody = withObserver(cfg, dto.Name, strings.Join(sourceNames, ","), methodBody)
+
+	f.Func().Params(
+		jen.Id("d").Op("*").Id(dto.Name),
+	).Id(methodName).Params(params...).Add(mappingResult(cfg)).Block(methodBody...)
+
+	f.Line()
+
+	return usesHelper
+}
+
+// fallbackParamName returns the parameter name of the i-th priority source
+func fallbackParamName(i int) string {
+	return fmt.Sprintf("src%d", i+1)
+}
+
+// buildFallbackMethodBody constructs the body of a priority-ordered multi-source mapping
+func buildFallbackMethodBody(
+	dto types.DTOMapping,
+	sources map[string]types.SourceStruct,
+	cfg *config.Config,
+	importMap map[string]string,
+	functions map[string]types.FunctionInfo,
+	calls callContext,
+) ([]jen.Code, bool) {
+	nilCheck := jen.Id(fallbackParamName(0)).Op("==").Nil()
+	for i := 1; i < len(dto.PrioritySources); i++ {
+		nilCheck = nilCheck.Op("&&").Id(fallbackParamName(i)).Op("==").Nil()
+	}
+
+	statements := []jen.Code{
+		jen.If(nilCheck).Block(
+			jen.Return(jen.Qual("errors", "New").Call(jen.Lit("all sources are nil"))),
+		),
+		jen.Line(),
+	}
+
+	converterMap := buildConverterMap(cfg)
+	m := matcher.New(cfg, dto)
+	usesHelper := false
+	// Nil source pointers fall through to the next source rather than zeroing the field
+	calls.nilPointers = true
+
+	for _, dtoField := range dto.Fields {
+		if dtoField.Ignore {
+			continue
+		}
+
+		// Chain the sources as if/else-if branches, each shadowing src with the candidate source
+		var chain *jen.Statement
+		for i, sourceName := range dto.PrioritySources {
+			source := sources[sourceName]
+			sourceFieldName, sourceField, exists := m.Resolve(dtoField, source)
+			if !exists {
+				continue
+			}
+
+			condition := jen.Id("src").Op("!=").Nil()
+			if nonZero, helper, ok := buildNonZeroCondition(sourceField, sourceFieldName, source, sources); ok {
+				condition = condition.Op("&&").Add(nonZero)
+				usesHelper = usesHelper || helper
+			}
+
+			branch := jen.Id("src").Op(":=").Id(fallbackParamName(i)).Op(";").Add(condition)
+			body := buildFieldStatements(dto.Name, dtoField, sourceField, sourceFieldName, source, converterMap, functions, importMap, calls)
+
+			if chain == nil {
+				chain = jen.If(branch).Block(body...)
+			} else {
+				chain = chain.Else().If(branch).Block(body...)
+			}
+		}
+
+		if chain == nil {
+			statements = append(statements,
+				jen.Comment(fmt.Sprintf("%s: not found in any source, will be zero value", dtoField.Name)),
+			)
+			continue
+		}
+
+		statements = append(statements, chain)
+	}
+
+	statements = append(statements, jen.Line(), jen.Return(jen.Nil()))
+	return statements, usesHelper
+}
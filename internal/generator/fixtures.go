@@ -0,0 +1,198 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// fixtureMaxDepth bounds how deep populated sources nest, so recursive types terminate
+const fixtureMaxDepth = 4
+
+// FixturesTestFileName returns the name of the generated test fixtures file, e.g. automappers_fixtures_test.go
+func FixturesTestFileName(output string) string {
+	return strings.TrimSuffix(output, ".go") + "_fixtures_test.go"
+}
+
+// GenerateFixtures creates a test file with the helpers populating sources for coverage tests and benchmarks
+func GenerateFixtures(
+	dtos []types.DTOMapping,
+	sources map[string]types.SourceStruct,
+	cfg *config.Config,
+	pkgName string,
+) *jen.File {
+	f := newGeneratedFile(pkgName, cfg)
+	generateFixtureSamples(f, dtos, sources, cfg, buildImportMap(sources))
+	generatePopulateFixture(f, cfg)
+	return f
+}
+
+// generateFixtureSamples generates the converter samples written into populated sources,
+// keyed by the source type and the field feeding the converter
+func generateFixtureSamples(
+	f *jen.File,
+	dtos []types.DTOMapping,
+	sources map[string]types.SourceStruct,
+	cfg *config.Config,
+	importMap map[string]string,
+) {
+	converterMap := buildConverterMap(cfg)
+
+	var sourceNames []string
+	samples := make(map[string]map[string]string)
+	for _, dto := range dtos {
+		m := matcher.New(cfg, dto)
+		for _, sourceName := range dto.Sources {
+			for _, dtoField := range dto.Fields {
+				conv, ok := converterMap[dtoField.ConverterTag]
+				if dtoField.Ignore || !ok || len(conv.Sample) == 0 {
+					continue
+				}
+				sourceFieldName, _, exists := m.Resolve(dtoField, sources[sourceName])
+				if !exists || strings.HasSuffix(sourceFieldName, "()") {
+					continue
+				}
+
+				if samples[sourceName] == nil {
+					samples[sourceName] = make(map[string]string)
+					sourceNames = append(sourceNames, sourceName)
+				}
+				if _, ok := samples[sourceName][sourceFieldName]; !ok {
+					var sample bytes.Buffer
+					if err := json.Compact(&sample, conv.Sample); err != nil {
+						sample.Write(conv.Sample)
+					}
+					samples[sourceName][sourceFieldName] = sample.String()
+				}
+			}
+		}
+	}
+
+	entries := make([]jen.Code, 0, len(sourceNames))
+	for _, sourceName := range sourceNames {
+		fieldNames := make([]string, 0, len(samples[sourceName]))
+		for fieldName := range samples[sourceName] {
+			fieldNames = append(fieldNames, fieldName)
+		}
+		slices.Sort(fieldNames)
+
+		fields := make([]jen.Code, len(fieldNames))
+		for i, fieldName := range fieldNames {
+			fields[i] = jen.Lit(fieldName).Op(":").Lit(samples[sourceName][fieldName])
+		}
+		entries = append(entries, jen.Line().Qual("reflect", "TypeOf").Call(
+			jen.Add(ParseTypeForJen(sourceName, importMap)).Values(),
+		).Op(":").Values(fields...))
+	}
+	if len(entries) > 0 {
+		entries = append(entries, jen.Line())
+	}
+
+	f.Comment("fixtureSamples holds JSON values accepted by the converters, by source type and field")
+	f.Var().Id("fixtureSamples").Op("=").Map(jen.Qual("reflect", "Type")).Map(jen.String()).String().Values(entries...)
+	f.Line()
+}
+
+// generatePopulateFixture generates the helper filling a source with non-zero values through reflection
+func generatePopulateFixture(f *jen.File, cfg *config.Config) {
+	v := func() *jen.Statement { return jen.Id("v") }
+	populate := func(value jen.Code) *jen.Statement {
+		return jen.Id("populateFixture").Call(jen.Id("tb"), value, jen.Id("depth").Op("+").Lit(1))
+	}
+
+	f.Comment("populateFixture fills every exported field reachable from v with a non-zero value.")
+	f.Comment(fmt.Sprintf("Pointers, slices and maps are left nil below a depth of %d.", fixtureMaxDepth))
+	f.Func().Id("populateFixture").Params(
+		jen.Id("tb").Qual("testing", "TB"),
+		jen.Id("v").Qual("reflect", "Value"),
+		jen.Id("depth").Int(),
+	).Block(
+		jen.Id("tb").Dot("Helper").Call(),
+		jen.Line(),
+		jen.If(v().Dot("Type").Call().Op("==").Qual("reflect", "TypeOf").Call(jen.Qual("time", "Time").Values())).Block(
+			v().Dot("Set").Call(jen.Qual("reflect", "ValueOf").Call(jen.Qual("time", "Unix").Call(jen.Lit(1), jen.Lit(0)).Dot("UTC").Call())),
+			jen.Return(),
+		),
+		jen.Line(),
+		jen.Switch(v().Dot("Kind").Call()).Block(
+			jen.Case(reflectPointer(cfg)).Block(
+				jen.If(jen.Id("depth").Op("<").Lit(fixtureMaxDepth)).Block(
+					v().Dot("Set").Call(jen.Qual("reflect", "New").Call(v().Dot("Type").Call().Dot("Elem").Call())),
+					populate(v().Dot("Elem").Call()),
+				),
+			),
+			jen.Case(jen.Qual("reflect", "Slice")).Block(
+				jen.If(jen.Id("depth").Op("<").Lit(fixtureMaxDepth)).Block(
+					v().Dot("Set").Call(jen.Qual("reflect", "MakeSlice").Call(v().Dot("Type").Call(), jen.Lit(1), jen.Lit(1))),
+					populate(v().Dot("Index").Call(jen.Lit(0))),
+				),
+			),
+			jen.Case(jen.Qual("reflect", "Map")).Block(
+				jen.If(jen.Id("depth").Op("<").Lit(fixtureMaxDepth)).Block(
+					jen.Id("key").Op(":=").Qual("reflect", "New").Call(v().Dot("Type").Call().Dot("Key").Call()).Dot("Elem").Call(),
+					jen.Id("elem").Op(":=").Qual("reflect", "New").Call(v().Dot("Type").Call().Dot("Elem").Call()).Dot("Elem").Call(),
+					populate(jen.Id("key")),
+					populate(jen.Id("elem")),
+					v().Dot("Set").Call(jen.Qual("reflect", "MakeMap").Call(v().Dot("Type").Call())),
+					v().Dot("SetMapIndex").Call(jen.Id("key"), jen.Id("elem")),
+				),
+			),
+			jen.Case(jen.Qual("reflect", "Array")).Block(
+				countLoop(cfg, "i", v().Dot("Len").Call(),
+					populate(v().Dot("Index").Call(jen.Id("i"))),
+				),
+			),
+			jen.Case(jen.Qual("reflect", "Struct")).Block(
+				countLoop(cfg, "i", v().Dot("NumField").Call(),
+					jen.If(isExportedField(cfg, v().Dot("Type").Call().Dot("Field").Call(jen.Id("i")))).Block(
+						populate(v().Dot("Field").Call(jen.Id("i"))),
+					),
+				),
+				jen.For(jen.List(jen.Id("name"), jen.Id("sample")).Op(":=").Range().Id("fixtureSamples").Index(v().Dot("Type").Call())).Block(
+					jen.Id("field").Op(":=").Add(v()).Dot("FieldByName").Call(jen.Id("name")),
+					jen.If(
+						jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(
+							jen.Index().Byte().Parens(jen.Id("sample")),
+							jen.Id("field").Dot("Addr").Call().Dot("Interface").Call(),
+						),
+						jen.Err().Op("!=").Nil(),
+					).Block(
+						jen.Id("tb").Dot("Fatalf").Call(jen.Lit("sample of %s.%s: %v"), v().Dot("Type").Call(), jen.Id("name"), jen.Err()),
+					),
+				),
+			),
+			jen.Case(jen.Qual("reflect", "String")).Block(
+				v().Dot("SetString").Call(jen.Lit("x")),
+			),
+			jen.Case(jen.Qual("reflect", "Bool")).Block(
+				v().Dot("SetBool").Call(jen.True()),
+			),
+			jen.Case(
+				jen.Qual("reflect", "Int"), jen.Qual("reflect", "Int8"), jen.Qual("reflect", "Int16"),
+				jen.Qual("reflect", "Int32"), jen.Qual("reflect", "Int64"),
+			).Block(
+				v().Dot("SetInt").Call(jen.Lit(1)),
+			),
+			jen.Case(
+				jen.Qual("reflect", "Uint"), jen.Qual("reflect", "Uint8"), jen.Qual("reflect", "Uint16"),
+				jen.Qual("reflect", "Uint32"), jen.Qual("reflect", "Uint64"), jen.Qual("reflect", "Uintptr"),
+			).Block(
+				v().Dot("SetUint").Call(jen.Lit(1)),
+			),
+			jen.Case(jen.Qual("reflect", "Float32"), jen.Qual("reflect", "Float64")).Block(
+				v().Dot("SetFloat").Call(jen.Lit(1)),
+			),
+			jen.Case(jen.Qual("reflect", "Complex64"), jen.Qual("reflect", "Complex128")).Block(
+				v().Dot("SetComplex").Call(jen.Lit(1)),
+			),
+		),
+	)
+	f.Line()
+}
@@ -0,0 +1,133 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// callContext describes how generated code calls the mappings of nested DTOs
+type callContext struct {
+	// functions lists the DTOs mapped by package-level functions instead of methods
+	functions map[string]bool
+	// depthGuard lists the DTOs mapped through their depth-tracking variants
+	depthGuard map[string]bool
+	// options tells whether mapping methods and functions accept functional options
+	options bool
+	// sliceHelpers records the generic slice helpers used so far, nil when the helpers are disabled
+	sliceHelpers map[string]bool
+	// nilPointers tells whether nil source pointers leave DTO pointers nil rather than pointing to zero values
+	nilPointers bool
+	// names renders the configured MapFrom and MapTo method names
+	names *config.MethodNames
+}
+
+// mapFromName returns the name of the MapFrom method of a DTO for a source type name without its package.
+// Function-style DTOs keep MapFrom<Source>, which only names their unexported mapping function.
+func (c callContext) mapFromName(dtoName, sourceTypeName string) string {
+	if c.functions[dtoName] {
+		return "MapFrom" + sourceTypeName
+	}
+	return c.names.MapFrom(dtoName, sourceTypeName)
+}
+
+// FunctionStyleDTOs returns the DTOs generated as package-level functions
+func FunctionStyleDTOs(dtos []types.DTOMapping, cfg *config.Config) map[string]bool {
+	functionDTOs := make(map[string]bool)
+	for _, dto := range dtos {
+		if dtoStyle(dto, cfg) == config.StyleFunction {
+			functionDTOs[dto.Name] = true
+		}
+	}
+	return functionDTOs
+}
+
+// dtoStyle returns the output style of a DTO, its annotation overriding the configuration
+func dtoStyle(dto types.DTOMapping, cfg *config.Config) string {
+	if dto.Style != "" {
+		return dto.Style
+	}
+	return cfg.Style
+}
+
+// MapFunctionName returns the name of the package-level mapping function, e.g. MapUserDBToUserDTO
+func MapFunctionName(sourceTypeName, dtoName string) string {
+	return "Map" + ExtractTypeNameWithoutPackage(sourceTypeName) + "To" + dtoName
+}
+
+// mapFunctionImplName returns the name of the unexported function filling an existing DTO
+func mapFunctionImplName(sourceTypeName, dtoName string) string {
+	return "m" + MapFunctionName(sourceTypeName, dtoName)[1:]
+}
+
+// nestedCall builds the call of a nested DTO's mapping into the given receiver,
+// using the package-level function or the depth-tracking variant where required
+func nestedCall(
+	receiver *jen.Statement, receiverIsPointer bool, dtoTypeName, methodName string, arg jen.Code, calls callContext,
+) *jen.Statement {
+	if calls.functions[dtoTypeName] {
+		if !receiverIsPointer {
+			receiver = jen.Op("&").Add(receiver)
+		}
+		sourceTypeName := strings.TrimPrefix(methodName, "MapFrom")
+		return jen.Id(mapFunctionImplName(sourceTypeName, dtoTypeName)).Call(receiver, arg)
+	}
+
+	if calls.depthGuard[dtoTypeName] {
+		return receiver.Dot(depthMethodName(methodName)).Call(arg, jen.Id("depth").Op("+").Lit(1))
+	}
+	return receiver.Dot(methodName).Call(arg)
+}
+
+// GenerateMapFunction generates a package-level function returning a new DTO mapped from a source,
+// backed by an unexported function filling an existing DTO that nested mappings reuse
+func GenerateMapFunction(
+	f *jen.File,
+	dto types.DTOMapping,
+	source types.SourceStruct,
+	sourceName string,
+	cfg *config.Config,
+	importMap map[string]string,
+	functions map[string]types.FunctionInfo,
+	calls callContext,
+) {
+	paramType := ParseTypeRefForJen(sourceName, importMap)
+	funcName := MapFunctionName(sourceName, dto.Name)
+	implName := mapFunctionImplName(sourceName, dto.Name)
+
+	f.Comment(fmt.Sprintf("%s maps from %s to a new %s", funcName, sourceName, dto.Name))
+	commentMappingTable(f, mappingTable(dto, source, cfg, false))
+	f.Func().Id(funcName).Params(
+		mapParams(cfg, jen.Id("src").Op("*").Add(paramType))...,
+	).Params(jen.Id(dto.Name), jen.Error()).Block(
+		jen.Var().Id("d").Id(dto.Name),
+		jen.If(
+			jen.Err().Op(":=").Id(implName).Call(append([]jen.Code{jen.Op("&").Id("d"), jen.Id("src")}, optionsArgs(cfg.Options)...)...),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return(jen.Id(dto.Name).Values(), jen.Err()),
+		),
+		jen.Return(jen.Id("d"), jen.Nil()),
+	)
+	f.Line()
+
+	methodBody := withObserver(cfg, dto.Name, source.Name, buildMethodBody(dto, source, cfg, importMap, functions, calls))
+
+	f.Comment(fmt.Sprintf("%s maps from %s into an existing %s", implName, sourceName, dto.Name))
+	f.Func().Id(implName).Params(
+		mapParams(cfg, jen.Id("d").Op("*").Id(dto.Name), jen.Id("src").Op("*").Add(paramType))...,
+	).Add(mappingResult(cfg)).Block(methodBody...)
+	f.Line()
+}
+
+// mapIntoCall builds the call mapping src into the DTO dst points to, both pointers,
+// through the MapFrom method or the package-level function of function-style DTOs
+func mapIntoCall(dtoName, sourceName, methodName string, dst, src jen.Code, calls callContext) *jen.Statement {
+	if calls.functions[dtoName] {
+		return jen.Id(mapFunctionImplName(sourceName, dtoName)).Call(dst, src)
+	}
+	return jen.Add(dst).Dot(methodName).Call(src)
+}
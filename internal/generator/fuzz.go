@@ -0,0 +1,223 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// fuzzableTypes lists the types Go fuzz targets accept as arguments
+var fuzzableTypes = []string{
+	"string", "[]byte", "bool", "byte", "rune",
+	"int", "int8", "int16", "int32", "int64",
+	"uint", "uint8", "uint16", "uint32", "uint64",
+	"float32", "float64",
+}
+
+// fuzzArg is a source field filled from an argument of a fuzz target
+type fuzzArg struct {
+	field    string // source field name
+	typeName string // source field type
+	checked  bool   // whether the field must survive the round trip
+}
+
+// FuzzTestFileName returns the name of the generated fuzz test file, e.g. automappers_fuzz_test.go
+func FuzzTestFileName(output string) string {
+	return strings.TrimSuffix(output, ".go") + "_fuzz_test.go"
+}
+
+// FuzzFunctionName returns the name of the fuzz target of a bidirectional DTO, e.g. FuzzUserDTORoundTrip,
+// or FuzzUserDTORoundTripUserDB when the DTO has several sources
+func FuzzFunctionName(dto types.DTOMapping, sourceName string) string {
+	if len(dto.Sources) == 1 {
+		return "Fuzz" + dto.Name + "RoundTrip"
+	}
+	return "Fuzz" + dto.Name + "RoundTrip" + ExtractTypeNameWithoutPackage(sourceName)
+}
+
+// GenerateFuzzTests creates a test file with a fuzz target per bidirectional DTO and source,
+// or nil when no DTO has a field to check
+func GenerateFuzzTests(
+	dtos []types.DTOMapping,
+	sources map[string]types.SourceStruct,
+	cfg *config.Config,
+	pkgName string,
+) *jen.File {
+	f := newGeneratedFile(pkgName, cfg)
+
+	importMap := buildImportMap(sources)
+	dtoNames := make(map[string]bool)
+	for _, dto := range dtos {
+		dtoNames[dto.Name] = true
+	}
+	calls := callContext{functions: FunctionStyleDTOs(dtos, cfg), names: cfg.MethodNames()}
+
+	targets := 0
+	for _, dto := range dtos {
+		if !dto.HasMode(types.ModeBidirectional) {
+			continue
+		}
+		for _, sourceName := range dto.Sources {
+			source := sources[sourceName]
+			args := fuzzArgs(dto, source, cfg)
+			if !slices.ContainsFunc(args, func(arg fuzzArg) bool { return arg.checked }) {
+				logger.Verbose("No lossless fields to fuzz in %s <-> %s", dto.Name, sourceName)
+				continue
+			}
+
+			methodName := mapFromMethodName(dto, sourceName, source, dtoNames, calls)
+			generateFuzzFunction(f, dto, sourceName, methodName, args, allConvertersLossless(dto, cfg), importMap)
+			targets++
+		}
+	}
+
+	if targets == 0 {
+		return nil
+	}
+	logger.Verbose("Generated %d fuzz targets", targets)
+	return f
+}
+
+// fuzzArgs returns the source fields a fuzz target fills, marking those expected back unchanged:
+// fields mapped directly and fields converted by a converter declared lossless
+func fuzzArgs(dto types.DTOMapping, source types.SourceStruct, cfg *config.Config) []fuzzArg {
+	converterMap := buildConverterMap(cfg)
+	m := matcher.New(cfg, dto)
+
+	var args []fuzzArg
+	for _, dtoField := range dto.Fields {
+		if dtoField.Ignore {
+			continue
+		}
+		sourceFieldName, sourceField, exists := m.Resolve(dtoField, source)
+		if !exists || strings.HasSuffix(sourceFieldName, "()") || !slices.Contains(fuzzableTypes, sourceField.Type) {
+			continue
+		}
+		if slices.ContainsFunc(args, func(arg fuzzArg) bool { return arg.field == sourceFieldName }) {
+			continue
+		}
+
+		checked := dtoField.NestedDTO == "" && dtoField.Redact == "" && len(dtoField.TypeCases) == 0
+		if dtoField.ConverterTag != "" {
+			conv := converterMap[dtoField.ConverterTag]
+			checked = checked && conv.Lossless && conv.Inverter != ""
+		}
+
+		args = append(args, fuzzArg{field: sourceFieldName, typeName: sourceField.Type, checked: checked})
+	}
+	return args
+}
+
+// allConvertersLossless reports whether every converter used by a DTO is declared lossless,
+// so its inverters are expected to accept every value the converters produce
+func allConvertersLossless(dto types.DTOMapping, cfg *config.Config) bool {
+	converterMap := buildConverterMap(cfg)
+	for _, field := range dto.Fields {
+		if !field.Ignore && field.ConverterTag != "" && !converterMap[field.ConverterTag].Lossless {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzArgName returns the name of the fuzz target argument filling a source field
+func fuzzArgName(field string) string {
+	return "in" + field
+}
+
+// fuzzSeed returns the zero value of a fuzzable type as a seed corpus entry
+func fuzzSeed(typeName string) jen.Code {
+	switch typeName {
+	case "string":
+		return jen.Lit("")
+	case "[]byte":
+		return jen.Index().Byte().Values()
+	case "bool":
+		return jen.False()
+	default:
+		return jen.Id(typeName).Call(jen.Lit(0))
+	}
+}
+
+// generateFuzzFunction generates a fuzz target mapping random sources to a DTO and back
+func generateFuzzFunction(
+	f *jen.File,
+	dto types.DTOMapping,
+	sourceName, methodName string,
+	args []fuzzArg,
+	lossless bool,
+	importMap map[string]string,
+) {
+	funcName := FuzzFunctionName(dto, sourceName)
+
+	// Inverters of lossy converters may reject the values mapped from random sources
+	backFailed := jen.Id("t").Dot("Skip").Call()
+	if lossless {
+		backFailed = jen.Id("t").Dot("Fatalf").Call(jen.Lit(fmt.Sprintf("mapping %s back: %%v", dto.Name)), jen.Err())
+	}
+
+	params := []jen.Code{jen.Id("t").Op("*").Qual("testing", "T")}
+	seeds := make([]jen.Code, 0, len(args))
+	values := make([]jen.Code, 0, len(args))
+	var body []jen.Code
+
+	for _, arg := range args {
+		name := fuzzArgName(arg.field)
+		params = append(params, jen.Id(name).Id(arg.typeName))
+		seeds = append(seeds, fuzzSeed(arg.typeName))
+		values = append(values, jen.Id(arg.field).Op(":").Id(name))
+
+		// NaN never equals itself
+		if arg.checked && strings.HasPrefix(arg.typeName, "float") {
+			value := jen.Id(name)
+			if arg.typeName != "float64" {
+				value = jen.Float64().Call(value)
+			}
+			body = append(body, jen.If(jen.Qual("math", "IsNaN").Call(value)).Block(
+				jen.Id("t").Dot("Skip").Call(),
+			))
+		}
+	}
+
+	body = append(body,
+		jen.Id("src").Op(":=").Op("&").Add(ParseTypeForJen(sourceName, importMap)).Values(values...),
+		jen.Line(),
+		jen.Comment("Sources rejected by a converter are outside of the mapped domain"),
+		jen.Id("d").Op(":=").Op("&").Id(dto.Name).Values(),
+		jen.If(jen.Err().Op(":=").Id("d").Dot(methodName).Call(jen.Id("src")), jen.Err().Op("!=").Nil()).Block(
+			jen.Id("t").Dot("Skip").Call(),
+		),
+		jen.List(jen.Id("back"), jen.Err()).Op(":=").Id("d").Dot(ToNewMethodName(sourceName)).Call(),
+		jen.If(jen.Err().Op("!=").Nil()).Block(backFailed),
+		jen.Line(),
+	)
+
+	for _, arg := range args {
+		if !arg.checked {
+			continue
+		}
+		body = append(body, jen.If(jen.Op("!").Qual("reflect", "DeepEqual").Call(
+			jen.Id("src").Dot(arg.field),
+			jen.Id("back").Dot(arg.field),
+		)).Block(
+			jen.Id("t").Dot("Errorf").Call(
+				jen.Lit(fmt.Sprintf("%s: got %%v back, want %%v", arg.field)),
+				jen.Id("back").Dot(arg.field),
+				jen.Id("src").Dot(arg.field),
+			),
+		))
+	}
+
+	f.Comment(fmt.Sprintf("%s checks that the lossless fields of %s survive a round trip through %s", funcName, sourceName, dto.Name))
+	f.Func().Id(funcName).Params(jen.Id("f").Op("*").Qual("testing", "F")).Block(
+		jen.Id("f").Dot("Add").Call(seeds...),
+		jen.Id("f").Dot("Fuzz").Call(jen.Func().Params(params...).Block(body...)),
+	)
+	f.Line()
+}
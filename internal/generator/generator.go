@@ -6,28 +6,54 @@ import (
 
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
 	"github.com/dave/jennifer/jen"
 )
 
-// Generate creates the automapper code file
+// newGeneratedFile creates a file of the given package carrying the configured header and
+// build constraint followed by the generated code header, ending with the given notes
+func newGeneratedFile(pkgName string, cfg *config.Config, notes ...string) *jen.File {
+	f := jen.NewFile(pkgName)
+
+	// Comments starting with // are rendered as is, a trailing newline leaves a blank line
+	if cfg.Header != "" {
+		lines := strings.Split(strings.TrimRight(cfg.Header, "\n"), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("// "+line, " ")
+		}
+		f.HeaderComment(strings.Join(lines, "\n") + "\n")
+	}
+	if cfg.BuildTags != "" {
+		f.HeaderComment("//go:build " + cfg.BuildTags + "\n")
+	}
+
+	f.HeaderComment(strings.Join(append([]string{
+		parser.GeneratedNotice + " " + Version + ". DO NOT EDIT.",
+		"Learn more: https://git.weirdcat.su/weirdcat/automapper-gen",
+	}, notes...), "\n"))
+	registerImports(f, cfg)
+	return f
+}
+
+// Generate creates the automapper code files by name: the output file, holding the helpers shared
+// by the package, and the file of every output group
 func Generate(
 	dtos []types.DTOMapping,
 	sources map[string]types.SourceStruct,
 	cfg *config.Config,
 	pkgName string,
 	functions map[string]types.FunctionInfo,
-) (*jen.File, error) {
+	inputHash string,
+) (map[string]*jen.File, error) {
 	logger.Verbose("Starting code generation for package: %s", pkgName)
 	logger.Debug("Available functions for converter detection: %d", len(functions))
 
-	f := jen.NewFile(pkgName)
-
-	// Add header comment
-	f.HeaderComment(
-		"Code generated by automapper-gen. DO NOT EDIT.\n" +
-			"Learn more: https://git.weirdcat.su/weirdcat/automapper-gen",
-	)
+	files := make(map[string]*jen.File)
+	for _, output := range cfg.OutputFiles() {
+		files[output] = newGeneratedFile(pkgName, cfg, inputHashPrefix+inputHash)
+	}
+	f := files[cfg.Output]
 
 	// Build import mapping (alias -> importPath) for external packages
 	logger.Verbose("Building import map...")
@@ -39,36 +65,291 @@ func Generate(
 		}
 	}
 
+	calls := callContext{
+		functions:   FunctionStyleDTOs(dtos, cfg),
+		options:     cfg.Options,
+		nilPointers: cfg.NilPointersForNull,
+		names:       cfg.MethodNames(),
+	}
+	if cfg.GenericHelpers {
+		calls.sliceHelpers = make(map[string]bool)
+	}
+	if len(calls.functions) > 0 {
+		logger.Verbose("Function-style DTOs: %d", len(calls.functions))
+	}
+
+	// Recursive DTOs are only depth guarded when a maximum depth is configured
+	guardedCalls := calls
+	if cfg.MaxDepth > 0 {
+		guardedCalls.depthGuard = FindRecursiveDTOs(dtos)
+		logger.Verbose("Depth guarded DTOs: %d (max depth %d)", len(guardedCalls.depthGuard), cfg.MaxDepth)
+	}
+
+	cloneable := CloneableDTOs(dtos)
+
+	// DTOs of this package may themselves be used as sources
+	dtoNames := make(map[string]bool)
+	for _, dto := range dtos {
+		dtoNames[dto.Name] = true
+	}
+
 	// Generate MapFrom methods
 	logger.Verbose("Generating MapFrom methods for %d DTOs...", len(dtos))
 	totalMethods := 0
+	needsZeroHelper := false
+	var registry []registryEntry
 
 	for i, dto := range dtos {
 		logger.Verbose("[%d/%d] Generating methods for DTO: %s", i+1, len(dtos), dto.Name)
 
+		// The mappings of a DTO go to the file of its output group, those only building in tests to the test output
+		f := files[cfg.OutputOf(dto.Name)]
+		if dto.TestOnly {
+			f = files[cfg.TestOutput()]
+		}
+
 		for j, sourceName := range dto.Sources {
 			source, ok := sources[sourceName]
 			if !ok {
 				return nil, fmt.Errorf("source struct %s not found for DTO %s", sourceName, dto.Name)
 			}
 
-			methodName := "MapFrom"
-			if len(dto.Sources) > 1 || source.IsExternal {
-				methodName = "MapFrom" + ExtractTypeNameWithoutPackage(sourceName)
+			methodName := mapFromMethodName(dto, sourceName, source, dtoNames, calls)
+			suffix := methodSuffix(dto, sourceName, source, dtoNames)
+
+			switch {
+			case calls.functions[dto.Name]:
+				logger.Debug("  [%d/%d] Generating %s (source: %s)",
+					j+1, len(dto.Sources), MapFunctionName(sourceName, dto.Name), sourceName)
+				GenerateMapFunction(f, dto, source, sourceName, cfg, importMap, functions, calls)
+			case guardedCalls.depthGuard[dto.Name]:
+				logger.Debug("  [%d/%d] Generating %s.%s (source: %s, depth guarded)",
+					j+1, len(dto.Sources), dto.Name, methodName, sourceName)
+				GenerateDepthLimitedMapFromMethod(f, dto, source, sourceName, methodName, cfg, importMap, functions, guardedCalls)
+			default:
+				logger.Debug("  [%d/%d] Generating %s.%s (source: %s)",
+					j+1, len(dto.Sources), dto.Name, methodName, sourceName)
+				GenerateMapFromMethod(f, dto, source, sourceName, methodName, cfg, importMap, functions, calls)
+			}
+			totalMethods++
+			if !dto.TestOnly {
+				registry = append(registry, newMapFromEntry(dto.Name, sourceName, methodName, importMap, calls))
+			}
+
+			if cfg.Tracing {
+				logger.Debug("  Generating %s (tracing)", ContextMethodName(dto.Name, sourceName, methodName, calls))
+
+				GenerateContextMethod(f, dto, sourceName, methodName, cfg, importMap, calls)
+				totalMethods++
+			}
+
+			if cfg.SafeVariants && !guardedCalls.depthGuard[dto.Name] && CannotFail(dto, source, cfg, functions) {
+				logger.Debug("  Generating %s (cannot fail)", SafeMethodName(dto.Name, sourceName, methodName, calls))
+
+				GenerateSafeMethod(f, dto, sourceName, methodName, cfg, importMap, calls)
+				totalMethods++
+			}
+
+			if dto.HasMode(types.ModeUpdate) {
+				updateMethodName := "UpdateFrom" + suffix
+				logger.Debug("  Generating %s.%s (update mode)", dto.Name, updateMethodName)
+
+				if GenerateUpdateFromMethod(f, dto, source, sourceName, updateMethodName, cfg, importMap, functions, sources, calls) {
+					needsZeroHelper = true
+				}
+				totalMethods++
+			}
+
+			if dto.HasMode(types.ModePatch) {
+				applyMethodName := "ApplyTo" + suffix
+				logger.Debug("  Generating %s.%s (patch mode)", dto.Name, applyMethodName)
+
+				GenerateApplyToMethod(f, dto, source, sourceName, applyMethodName, cfg, importMap, functions)
+				totalMethods++
+			}
+
+			mapToMethodName := ""
+			if dto.HasMode(types.ModeBidirectional) {
+				mapToMethodName = reverseMethodName(dto, sourceName, source, dtoNames, calls)
+				if mapToMethodName == ToNewMethodName(sourceName) {
+					return nil, fmt.Errorf("mapToName gives %s for DTO %s, the name of the method returning a new %s",
+						mapToMethodName, dto.Name, sourceName)
+				}
+				logger.Debug("  Generating %s.%s (bidirectional mode)", dto.Name, mapToMethodName)
+
+				GenerateMapToMethod(f, dto, source, sourceName, mapToMethodName, cfg, importMap, functions)
+				GenerateToNewMethod(f, dto, sourceName, mapToMethodName, importMap)
+				GenerateRoundTripMethod(f, dto, source, sourceName, methodName, cfg, importMap, calls)
+				totalMethods += 3
+				if !dto.TestOnly {
+					registry = append(registry, newMapToEntry(dto.Name, sourceName, mapToMethodName, importMap))
+				}
+			}
+
+			if dto.HasMode(types.ModeMerge) {
+				mergeToMethodName := "MergeTo" + suffix
+				logger.Debug("  Generating %s.%s (merge mode)", dto.Name, mergeToMethodName)
+
+				if GenerateMergeToMethod(f, dto, source, sourceName, mergeToMethodName, cfg, importMap, functions, sources) {
+					needsZeroHelper = true
+				}
+				totalMethods++
+			}
+
+			if dto.HasMode(types.ModeMapper) {
+				logger.Debug("  Generating %s (mapper mode)", MapperInterfaceName(dto.Name))
+
+				GenerateMapperInterface(f, dto, sourceName, methodName, mapToMethodName, importMap, calls)
+				totalMethods++
+			}
+
+			if dto.HasMode(types.ModeStream) {
+				logger.Debug("  Generating %s (stream mode)", StreamFunctionName(sourceName, dto.Name))
+
+				GenerateStreamFunction(f, dto, sourceName, methodName, cfg, importMap, calls)
+				totalMethods++
+			}
+
+			if dto.HasMode(types.ModeParallel) {
+				logger.Debug("  Generating %s (parallel mode)", ParallelFunctionName(sourceName, dto.Name))
+
+				GenerateParallelFunction(f, dto, sourceName, methodName, importMap, cfg, calls)
+				totalMethods++
 			}
 
-			logger.Debug("  [%d/%d] Generating %s.%s (source: %s)",
-				j+1, len(dto.Sources), dto.Name, methodName, sourceName)
+			if dto.HasMode(types.ModeDiff) {
+				logger.Debug("  Generating %s (diff mode)", DiffFunctionName(dto, sourceName))
 
-			GenerateMapFromMethod(f, dto, source, sourceName, methodName, cfg, importMap, functions)
+				GenerateDiffFunction(f, dto, source, sourceName, methodName, cfg, importMap, calls)
+				totalMethods++
+			}
+		}
+
+		if dto.Via != "" {
+			viaEntries, err := generateViaMethods(f, dto, dtos, sources, dtoNames, cfg, importMap, calls)
+			if err != nil {
+				return nil, err
+			}
+			if !dto.TestOnly {
+				registry = append(registry, viaEntries...)
+			}
+			totalMethods += len(viaEntries)
+		}
+
+		if len(dto.PrioritySources) > 1 {
+			fallbackMethodName := FallbackMethodName(dto.PrioritySources)
+			logger.Debug("  Generating %s.%s (priority: %v)", dto.Name, fallbackMethodName, dto.PrioritySources)
+
+			if GenerateFallbackMethod(f, dto, sources, fallbackMethodName, cfg, importMap, functions, calls) {
+				needsZeroHelper = true
+			}
 			totalMethods++
 		}
+
+		if cloneable[dto.Name] {
+			logger.Debug("  Generating %s.Clone (clone mode)", dto.Name)
+
+			GenerateCloneMethod(f, dto, cloneable, importMap, cfg)
+			totalMethods++
+		}
+	}
+
+	if needsZeroHelper {
+		logger.Debug("Generating zero-value helper")
+		GenerateIsZeroHelper(f, cfg)
+	}
+
+	for _, helper := range usedSliceHelpers(calls) {
+		logger.Debug("Generating slice helper: %s", helper)
+		GenerateSliceHelper(f, helper)
+	}
+
+	if len(cfg.MapperInterfaces) > 0 {
+		logger.Debug("Generating assertions for %d mapper interfaces", len(cfg.MapperInterfaces))
+		GenerateInterfaceAssertions(f, buildingDTOs(dtos), cfg.MapperInterfaces, importMap, calls)
 	}
 
-	logger.Verbose("Generated %d MapFrom methods", totalMethods)
+	if UsesMappingError(dtos, cfg, functions) {
+		logger.Debug("Generating MappingError type")
+		GenerateMappingErrorType(f, cfg)
+	}
+
+	if types.UsesMode(dtos, types.ModeDiff) {
+		logger.Debug("Generating FieldDiff type")
+		GenerateFieldDiffType(f, cfg)
+	}
+
+	if cfg.Metrics {
+		logger.Debug("Generating mapping observer")
+		GenerateMapObserver(f, cfg)
+	}
+
+	if cfg.Tracing {
+		logger.Debug("Generating tracing helper")
+		GenerateTracingHelper(f, pkgName)
+	}
+
+	if cfg.Options {
+		logger.Debug("Generating mapping options")
+		GenerateMapOptions(f)
+	}
+
+	if cfg.Registry {
+		logger.Debug("Generating mapping registry with %d entries", len(registry))
+		GenerateRegistry(f, registry)
+	}
+
+	for _, strategy := range UsedRedactions(dtos) {
+		logger.Debug("Generating redaction helper: %s", strategy)
+		GenerateRedactHelper(f, strategy)
+	}
+
+	logger.Verbose("Generated %d mapping methods", totalMethods)
 	logger.Success("Code generation completed successfully")
 
-	return f, nil
+	return files, nil
+}
+
+// buildingDTOs returns the DTOs whose mappings build outside tests, the package-level declarations
+// of the output only referring to these
+func buildingDTOs(dtos []types.DTOMapping) []types.DTOMapping {
+	var building []types.DTOMapping
+	for _, dto := range dtos {
+		if !dto.TestOnly {
+			building = append(building, dto)
+		}
+	}
+	return building
+}
+
+// mapFromMethodName returns the name of the MapFrom method of a DTO for one of its sources.
+// Without a mapFromName template, the source name is left out when it can't be ambiguous.
+func mapFromMethodName(
+	dto types.DTOMapping, sourceName string, source types.SourceStruct, dtoNames map[string]bool, calls callContext,
+) string {
+	if calls.names.HasMapFrom() && !calls.functions[dto.Name] {
+		return calls.names.MapFrom(dto.Name, ExtractTypeNameWithoutPackage(sourceName))
+	}
+	return "MapFrom" + methodSuffix(dto, sourceName, source, dtoNames)
+}
+
+// reverseMethodName returns the name of the MapTo method of a bidirectional DTO for one of its sources
+func reverseMethodName(
+	dto types.DTOMapping, sourceName string, source types.SourceStruct, dtoNames map[string]bool, calls callContext,
+) string {
+	if calls.names.HasMapTo() {
+		return calls.names.MapTo(dto.Name, ExtractTypeNameWithoutPackage(sourceName))
+	}
+	return "MapTo" + methodSuffix(dto, sourceName, source, dtoNames)
+}
+
+// methodSuffix returns the source name appended to the names of a DTO's methods for one of its sources,
+// e.g. UserDB in MapFromUserDB and UpdateFromUserDB, or nothing when the source can't be ambiguous
+func methodSuffix(dto types.DTOMapping, sourceName string, source types.SourceStruct, dtoNames map[string]bool) string {
+	if len(dto.Sources) > 1 || source.IsExternal || dtoNames[sourceName] {
+		return ExtractTypeNameWithoutPackage(sourceName)
+	}
+	return ""
 }
 
 // buildImportMap creates a mapping of package aliases to import paths
@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+)
+
+// Version is the generator version, part of the input hash since it changes the output
+const Version = "v0.0.1"
+
+// inputHashPrefix starts the header line carrying the input hash of a generated file
+const inputHashPrefix = "Input hash: "
+
+// InputHash returns a hash of everything the generated code depends on: the generator version,
+// the loaded configuration and the parsed DTOs, sources and functions
+func InputHash(
+	cfg *config.Config,
+	dtos []types.DTOMapping,
+	sources map[string]types.SourceStruct,
+	functions map[string]types.FunctionInfo,
+	pkgName string,
+) (string, error) {
+	// Maps are encoded with sorted keys, so equal inputs always hash the same
+	inputs, err := json.Marshal(struct {
+		Version   string
+		Package   string
+		Config    *config.Config
+		DTOs      []types.DTOMapping
+		Sources   map[string]types.SourceStruct
+		Functions map[string]types.FunctionInfo
+	}{Version, pkgName, cfg, dtos, sources, functions})
+	if err != nil {
+		return "", fmt.Errorf("encoding inputs: %w", err)
+	}
+
+	sum := sha256.Sum256(inputs)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ReadInputHash returns the input hash in the header of a generated file,
+// or an empty string when the file doesn't exist or carries no hash
+func ReadInputHash(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if hash, ok := strings.CutPrefix(line, inputHashPrefix); ok {
+			return strings.TrimSpace(hash)
+		}
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"github.com/dave/jennifer/jen"
+)
+
+// generatedLocals lists the parameters and variables of the generated code, which would hide
+// an imported package of the same name in the functions declaring them
+var generatedLocals = map[string]bool{
+	"b": true, "back": true, "c": true, "ctx": true, "d": true, "depth": true, "diffs": true,
+	"dst": true, "dto": true, "e": true, "elem": true, "err": true, "errs": true, "f": true,
+	"field": true, "fields": true, "from": true, "g": true, "i": true, "in": true, "item": true,
+	"key": true, "mapped": true, "mapper": true, "name": true, "nested": true, "o": true,
+	"observer": true, "ok": true, "opts": true, "out": true, "r": true, "result": true, "s": true,
+	"source": true, "span": true, "src": true, "t": true, "tb": true, "to": true, "v": true,
+}
+
+// registerImports names the packages the configuration makes the generated code import: the
+// import alias configured for their path, or else the alias of the external package or the
+// default alias of the path, suffixed with pkg when it's the name of a generated variable.
+// Jennifer numbers the names still colliding with another import.
+func registerImports(f *jen.File, cfg *config.Config) {
+	names := make(map[string]string)
+	for _, extPkg := range cfg.ExternalPackages {
+		names[extPkg.ImportPath] = extPkg.Name()
+	}
+	for _, path := range cfg.ConverterPackages {
+		names[path] = config.DefaultAlias(path)
+	}
+	for _, iface := range cfg.MapperInterfaces {
+		if iface.ImportPath != "" {
+			names[iface.ImportPath] = config.DefaultAlias(iface.ImportPath)
+		}
+	}
+	if cfg.RuntimePackage != "" {
+		names[cfg.RuntimePackage] = config.DefaultAlias(cfg.RuntimePackage)
+	}
+
+	for path, name := range names {
+		if generatedLocals[name] {
+			name += "pkg"
+		}
+		names[path] = name
+	}
+	for path, alias := range cfg.ImportAliases {
+		names[path] = alias
+	}
+
+	for path, name := range names {
+		f.ImportAlias(path, name)
+	}
+}
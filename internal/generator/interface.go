@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// MapperInterfaceName returns the name of the mapper interface of a DTO, e.g. UserMapper for UserDTO
+func MapperInterfaceName(dtoName string) string {
+	if trimmed := strings.TrimSuffix(dtoName, "DTO"); trimmed != "" {
+		dtoName = trimmed
+	}
+	return dtoName + "Mapper"
+}
+
+// GenerateMapperInterface generates a mapper interface for a single-source DTO together with an implementation
+// and its constructor, so services can depend on and mock the mapping layer.
+// ToDB is only part of the interface when the DTO maps back with MapTo.
+func GenerateMapperInterface(
+	f *jen.File,
+	dto types.DTOMapping,
+	sourceName, mapFromName, mapToName string,
+	importMap map[string]string,
+	calls callContext,
+) {
+	interfaceName := MapperInterfaceName(dto.Name)
+	implName := strings.ToLower(interfaceName[:1]) + interfaceName[1:]
+	sourceType := func() jen.Code { return ParseTypeRefForJen(sourceName, importMap) }
+
+	methods := []jen.Code{
+		jen.Id("ToDTO").Params(jen.Op("*").Add(sourceType())).Params(jen.Op("*").Id(dto.Name), jen.Error()),
+	}
+	if mapToName != "" {
+		methods = append(methods,
+			jen.Id("ToDB").Params(jen.Op("*").Id(dto.Name)).Params(jen.Op("*").Add(sourceType()), jen.Error()),
+		)
+	}
+
+	f.Comment(fmt.Sprintf("%s maps between %s and %s", interfaceName, sourceName, dto.Name))
+	f.Type().Id(interfaceName).Interface(methods...)
+	f.Line()
+
+	f.Comment(fmt.Sprintf("%s implements %s with the generated mappings", implName, interfaceName))
+	f.Type().Id(implName).Struct()
+	f.Line()
+
+	f.Comment(fmt.Sprintf("New%s returns the generated %s implementation", interfaceName, interfaceName))
+	f.Func().Id("New" + interfaceName).Params().Id(interfaceName).Block(
+		jen.Return(jen.Id(implName).Values()),
+	)
+	f.Line()
+
+	// Function-style DTOs have no MapFrom method to call
+	mapCall := jen.Id("d").Dot(mapFromName).Call(jen.Id("src"))
+	if calls.functions[dto.Name] {
+		mapCall = jen.Id(mapFunctionImplName(sourceName, dto.Name)).Call(jen.Id("d"), jen.Id("src"))
+	}
+
+	f.Comment(fmt.Sprintf("ToDTO maps %s to a new %s", sourceName, dto.Name))
+	f.Func().Params(jen.Id(implName)).Id("ToDTO").Params(
+		jen.Id("src").Op("*").Add(sourceType()),
+	).Params(jen.Op("*").Id(dto.Name), jen.Error()).Block(
+		jen.Id("d").Op(":=").Op("&").Id(dto.Name).Values(),
+		jen.If(jen.Err().Op(":=").Add(mapCall), jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Err()),
+		),
+		jen.Return(jen.Id("d"), jen.Nil()),
+	)
+	f.Line()
+
+	if mapToName == "" {
+		return
+	}
+
+	f.Comment(fmt.Sprintf("ToDB maps %s to a new %s", dto.Name, sourceName))
+	f.Func().Params(jen.Id(implName)).Id("ToDB").Params(
+		jen.Id("d").Op("*").Id(dto.Name),
+	).Params(jen.Op("*").Add(sourceType()), jen.Error()).Block(
+		jen.If(jen.Id("d").Op("==").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Qual("errors", "New").Call(jen.Lit("source is nil"))),
+		),
+		jen.Return(jen.Id("d").Dot(ToNewMethodName(sourceName)).Call()),
+	)
+	f.Line()
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
 	"github.com/dave/jennifer/jen"
@@ -19,20 +20,22 @@ func GenerateMapFromMethod(
 	cfg *config.Config,
 	importMap map[string]string,
 	functions map[string]types.FunctionInfo,
+	calls callContext,
 ) {
 	// Parse parameter type
 	paramType := ParseTypeRefForJen(sourceName, importMap)
 
 	f.Comment(fmt.Sprintf("%s maps from %s to %s", methodName, sourceName, dto.Name))
+	commentMappingTable(f, mappingTable(dto, source, cfg, false))
 
-	methodBody := buildMethodBody(dto, source, cfg, functions)
+	methodBody := withObserver(cfg, dto.Name, source.Name, buildMethodBody(dto, source, cfg, importMap, functions, calls))
 
 	// Generate method
 	f.Func().Params(
 		jen.Id("d").Op("*").Id(dto.Name),
 	).Id(methodName).Params(
-		jen.Id("src").Op("*").Add(paramType),
-	).Error().Block(methodBody...)
+		mapParams(cfg, jen.Id("src").Op("*").Add(paramType))...,
+	).Add(mappingResult(cfg)).Block(methodBody...)
 
 	f.Line()
 }
@@ -42,20 +45,20 @@ func buildMethodBody(
 	dto types.DTOMapping,
 	source types.SourceStruct,
 	cfg *config.Config,
+	importMap map[string]string,
 	functions map[string]types.FunctionInfo,
+	calls callContext,
 ) []jen.Code {
 	statements := []jen.Code{
 		jen.If(jen.Id("src").Op("==").Nil()).Block(
-			jen.Return(jen.Qual("errors", "New").Call(jen.Lit("source is nil"))),
+			jen.Return(nilSourceError(cfg, dto.Name, source.Name)),
 		),
 		jen.Line(),
 	}
 
-	// Build converter map
-	converterMap := make(map[string]config.ConverterDef)
-	for _, conv := range cfg.Converters {
-		converterMap[conv.Name] = conv
-	}
+	converterMap := buildConverterMap(cfg)
+	m := matcher.New(cfg, dto)
+	usesOptions := false
 
 	// Generate field mappings
 	for _, dtoField := range dto.Fields {
@@ -63,52 +66,156 @@ func buildMethodBody(
 			continue
 		}
 
-		sourceFieldName := resolveSourceFieldName(dtoField)
-		sourceField, exists := source.Fields[sourceFieldName]
-
+		sourceFieldName, sourceField, exists := m.Resolve(dtoField, source)
 		if !exists {
 			statements = append(statements,
 				jen.Comment(fmt.Sprintf("%s: not found in source, will be zero value", dtoField.Name)),
 			)
+			if cfg.Options {
+				statements = append(statements, buildMissingFieldOption(dtoField))
+				usesOptions = true
+			}
 			continue
 		}
 
-		// Nested DTO mapping takes precedence
-		if dtoField.NestedDTO != "" {
-			statements = append(statements, buildNestedDTOMapping(dtoField, sourceField, sourceFieldName)...)
-		} else if dtoField.ConverterTag != "" {
-			conv, exists := converterMap[dtoField.ConverterTag]
-			if !exists {
-				// This should be caught by validation, but handle it gracefully
-				statements = append(statements,
-					jen.Comment(fmt.Sprintf("%s: converter '%s' not found", dtoField.Name, dtoField.ConverterTag)),
-				)
-				continue
-			}
-
-			// Check if converter is safe (1 return) or error-returning (2 returns)
-			fn, fnExists := functions[conv.Function]
-			isSafe := fnExists && parser.IsSafeConverterSignature(fn)
-
-			statements = append(statements, buildConverterMapping(dtoField, sourceField, sourceFieldName, conv, isSafe)...)
-		} else {
-			statements = append(statements, buildFieldMapping(dtoField, sourceField, sourceFieldName)...)
+		fieldStatements := buildFieldStatements(dto.Name, dtoField, sourceField, sourceFieldName, source, converterMap, functions, importMap, calls)
+		if cfg.Options {
+			fieldStatements = []jen.Code{wrapWithOptions(dtoField, sourceField, sourceFieldName, fieldStatements, importMap)}
+			usesOptions = true
 		}
+		statements = append(statements, fieldStatements...)
+	}
+
+	if usesOptions {
+		statements = append(statements[:2:2], append([]jen.Code{
+			jen.Id("o").Op(":=").Id("newMapOptions").Call(jen.Id("opts")),
+		}, statements[2:]...)...)
 	}
 
 	statements = append(statements, jen.Line(), jen.Return(jen.Nil()))
 	return statements
 }
 
-// resolveSourceFieldName determines the source field name for a DTO field
-func resolveSourceFieldName(
+// buildConverterMap indexes the configured converters by name
+func buildConverterMap(cfg *config.Config) map[string]config.ConverterDef {
+	converterMap := make(map[string]config.ConverterDef)
+	for _, conv := range cfg.Converters {
+		converterMap[conv.Name] = conv
+	}
+	return converterMap
+}
+
+// funcRef refers to a converter or inverter function, qualified by its import path when it's declared
+// in a converter package, e.g. example.com/shared/convert.ToLower, and instantiated with the type
+// arguments given, e.g. To[string]
+func funcRef(function string, typeArgs ...jen.Code) *jen.Statement {
+	ref := jen.Id(function)
+	if i := strings.LastIndex(function, "."); i >= 0 {
+		ref = jen.Qual(function[:i], function[i+1:])
+	}
+	if len(typeArgs) > 0 {
+		ref = ref.Index(jen.List(typeArgs...))
+	}
+	return ref
+}
+
+// converterTypeArgs returns the type arguments a call of a generic converter or inverter has to give,
+// from the types of its argument and result, argIsDTO telling which one is the DTO field
+func converterTypeArgs(fn types.FunctionInfo, argType, resultType string, argIsDTO bool, importMap map[string]string) []jen.Code {
+	typeArgs, _ := parser.ConverterTypeArgs(fn, strings.TrimPrefix(argType, "*"), strings.TrimPrefix(resultType, "*"), argIsDTO)
+	code := make([]jen.Code, len(typeArgs))
+	for i, typeArg := range typeArgs {
+		code[i] = ParseTypeForJen(typeArg, importMap)
+	}
+	return code
+}
+
+// funcName shortens a converter or inverter function for comments and errors, keeping the package
+// name of a converter package function, e.g. convert.ToLower
+func funcName(function string) string {
+	return function[strings.LastIndex(function, "/")+1:]
+}
+
+// buildFieldStatements constructs the statements that map a single DTO field from its resolved source field
+func buildFieldStatements(
+	dtoName string,
 	dtoField types.FieldInfo,
-) string {
-	if dtoField.FieldTag != "" {
-		return dtoField.FieldTag
+	sourceField types.FieldTypeInfo,
+	sourceFieldName string,
+	source types.SourceStruct,
+	converterMap map[string]config.ConverterDef,
+	functions map[string]types.FunctionInfo,
+	importMap map[string]string,
+	calls callContext,
+) []jen.Code {
+	// Interface-typed fields are mapped by their dynamic type
+	if len(dtoField.TypeCases) > 0 {
+		return buildTypeSwitchMapping(dtoField, sourceFieldName, source, importMap, calls)
+	}
+
+	// Nested DTO mapping takes precedence
+	if dtoField.NestedDTO != "" {
+		return buildNestedDTOMapping(dtoField, sourceField, sourceFieldName, source, importMap, calls)
+	}
+
+	if dtoField.Redact != "" {
+		return buildRedactedMapping(dtoField, sourceField, sourceFieldName, calls.nilPointers)
+	}
+
+	if dtoField.ConverterTag != "" {
+		conv, exists := converterMap[dtoField.ConverterTag]
+		if !exists {
+			// This should be caught by validation, but handle it gracefully
+			return []jen.Code{
+				jen.Comment(fmt.Sprintf("%s: converter '%s' not found", dtoField.Name, dtoField.ConverterTag)),
+			}
+		}
+
+		// Check if converter is safe (1 return) or error-returning (2 returns)
+		fn, fnExists := functions[conv.Function]
+		isSafe := fnExists && parser.IsSafeConverterSignature(fn)
+
+		typeArgs := converterTypeArgs(fn, sourceField.Type, dtoField.Type, false, importMap)
+
+		if parser.TakesPointer(fn) || parser.ReturnsPointer(fn) {
+			return buildPointerConverterMapping(dtoName, source.Name, dtoField, sourceField, sourceFieldName, conv, typeArgs,
+				parser.TakesPointer(fn), parser.ReturnsPointer(fn), isSafe, calls.nilPointers)
+		}
+		return buildConverterMapping(dtoName, source.Name, dtoField, sourceField, sourceFieldName, conv, typeArgs, isSafe, calls.nilPointers)
 	}
 
-	return dtoField.Name
+	return buildFieldMapping(dtoField, sourceField, sourceFieldName, calls.nilPointers)
+}
+
+// nilPointerNote is the comment following the nil check of a source pointer, telling what a nil
+// pointer results in. UpdateFrom, where it leaves the field unchanged, replaces it.
+type nilPointerNote struct {
+	*jen.Statement
+}
+
+// newNilPointerNote comments what a nil source pointer results in, e.g. zero value
+func newNilPointerNote(dtoField types.FieldInfo, result string) nilPointerNote {
+	return nilPointerNote{jen.Comment(fmt.Sprintf("// %s: nil pointer will result in %s", dtoField.Name, result))}
+}
+
+// nilPointerFallback completes the nil check of a source pointer mapped to a DTO pointer: the DTO pointer
+// stays nil with nilPointersForNull, or points to the zero value given otherwise
+func nilPointerFallback(check *jen.Statement, dtoField types.FieldInfo, zero jen.Code, nilPointers bool) []jen.Code {
+	if nilPointers {
+		return []jen.Code{check, newNilPointerNote(dtoField, "nil")}
+	}
+	return []jen.Code{
+		check.Else().Block(jen.Id("d").Dot(dtoField.Name).Op("=").Add(zero)),
+		newNilPointerNote(dtoField, "a pointer to the zero value"),
+	}
+}
+
+// sourceAccess builds the expression reading a source field, calling the getter for names ending in ()
+func sourceAccess(sourceFieldName string) *jen.Statement {
+	if getter, ok := matcher.GetterName(sourceFieldName); ok {
+		return jen.Id("src").Dot(getter).Call()
+	}
+	return jen.Id("src").Dot(sourceFieldName)
 }
 
 // buildSafeConverterMapping creates statements for safe converter (no error)
@@ -117,6 +224,8 @@ func buildSafeConverterMapping(
 	sourceField types.FieldTypeInfo,
 	sourceFieldName string,
 	conv config.ConverterDef,
+	typeArgs []jen.Code,
+	nilPointers bool,
 ) []jen.Code {
 	srcIsPointer := sourceField.IsPointer
 	dstIsPointer := strings.HasPrefix(dtoField.Type, "*")
@@ -128,24 +237,24 @@ func buildSafeConverterMapping(
 	if srcIsPointer {
 		if dstIsPointer {
 			// *T -> dereference -> converter -> T -> take address -> *T
-			return []jen.Code{
-				jen.If(jen.Id("src").Dot(sourceFieldName).Op("!=").Nil()).Block(
-					jen.Id("result").Op(":=").Id(conv.Function).Call(
-						jen.Op("*").Id("src").Dot(sourceFieldName),
+			return nilPointerFallback(
+				jen.If(sourceAccess(sourceFieldName).Op("!=").Nil()).Block(
+					jen.Id("result").Op(":=").Add(funcRef(conv.Function, typeArgs...)).Call(
+						jen.Op("*").Add(sourceAccess(sourceFieldName)),
 					),
 					jen.Id("d").Dot(dtoField.Name).Op("=").Op("&").Id("result"),
 				),
-				jen.Comment(fmt.Sprintf("// %s: nil pointer will result in nil", dtoField.Name)),
-			}
+				dtoField, jen.New(jen.Id(ExtractBaseType(dtoField.Type))), nilPointers,
+			)
 		} else {
 			// *T -> dereference -> converter -> T
 			return []jen.Code{
-				jen.If(jen.Id("src").Dot(sourceFieldName).Op("!=").Nil()).Block(
-					jen.Id("d").Dot(dtoField.Name).Op("=").Id(conv.Function).Call(
-						jen.Op("*").Id("src").Dot(sourceFieldName),
+				jen.If(sourceAccess(sourceFieldName).Op("!=").Nil()).Block(
+					jen.Id("d").Dot(dtoField.Name).Op("=").Add(funcRef(conv.Function, typeArgs...)).Call(
+						jen.Op("*").Add(sourceAccess(sourceFieldName)),
 					),
 				),
-				jen.Comment(fmt.Sprintf("// %s: nil pointer will result in zero value", dtoField.Name)),
+				newNilPointerNote(dtoField, "zero value"),
 			}
 		}
 	}
@@ -154,8 +263,8 @@ func buildSafeConverterMapping(
 	if dstIsPointer {
 		return []jen.Code{
 			jen.Block(
-				jen.Id("result").Op(":=").Id(conv.Function).Call(
-					jen.Id("src").Dot(sourceFieldName),
+				jen.Id("result").Op(":=").Add(funcRef(conv.Function, typeArgs...)).Call(
+					sourceAccess(sourceFieldName),
 				),
 				jen.Id("d").Dot(dtoField.Name).Op("=").Op("&").Id("result"),
 			),
@@ -164,35 +273,41 @@ func buildSafeConverterMapping(
 
 	// Case 3: Both are values
 	return []jen.Code{
-		jen.Id("d").Dot(dtoField.Name).Op("=").Id(conv.Function).Call(
-			jen.Id("src").Dot(sourceFieldName),
+		jen.Id("d").Dot(dtoField.Name).Op("=").Add(funcRef(conv.Function, typeArgs...)).Call(
+			sourceAccess(sourceFieldName),
 		),
 	}
 }
 
 // buildConverterMapping creates statements for converter - automatically detects safe vs error-returning
 func buildConverterMapping(
+	dtoName, sourceName string,
 	dtoField types.FieldInfo,
 	sourceField types.FieldTypeInfo,
 	sourceFieldName string,
 	conv config.ConverterDef,
+	typeArgs []jen.Code,
 	isSafe bool,
+	nilPointers bool,
 ) []jen.Code {
 	// For safe converters, use the safe version
 	if isSafe {
-		return buildSafeConverterMapping(dtoField, sourceField, sourceFieldName, conv)
+		return buildSafeConverterMapping(dtoField, sourceField, sourceFieldName, conv, typeArgs, nilPointers)
 	}
 
 	// Otherwise use error-returning version
-	return buildErrorReturningConverterMapping(dtoField, sourceField, sourceFieldName, conv)
+	return buildErrorReturningConverterMapping(dtoName, sourceName, dtoField, sourceField, sourceFieldName, conv, typeArgs, nilPointers)
 }
 
 // buildErrorReturningConverterMapping creates statements for error-returning converter
 func buildErrorReturningConverterMapping(
+	dtoName, sourceName string,
 	dtoField types.FieldInfo,
 	sourceField types.FieldTypeInfo,
 	sourceFieldName string,
 	conv config.ConverterDef,
+	typeArgs []jen.Code,
+	nilPointers bool,
 ) []jen.Code {
 	srcIsPointer := sourceField.IsPointer
 	dstIsPointer := strings.HasPrefix(dtoField.Type, "*")
@@ -204,39 +319,33 @@ func buildErrorReturningConverterMapping(
 	if srcIsPointer {
 		if dstIsPointer {
 			// *T -> dereference -> converter -> T -> take address -> *T
-			statements = []jen.Code{
-				jen.If(jen.Id("src").Dot(sourceFieldName).Op("!=").Nil()).Block(
+			statements = nilPointerFallback(
+				jen.If(sourceAccess(sourceFieldName).Op("!=").Nil()).Block(
 					jen.Var().Id("result").Id(ExtractBaseType(dtoField.Type)),
 					jen.Var().Id("err").Error(),
-					jen.List(jen.Id("result"), jen.Id("err")).Op("=").Id(conv.Function).Call(
-						jen.Op("*").Id("src").Dot(sourceFieldName),
+					jen.List(jen.Id("result"), jen.Id("err")).Op("=").Add(funcRef(conv.Function, typeArgs...)).Call(
+						jen.Op("*").Add(sourceAccess(sourceFieldName)),
 					),
 					jen.If(jen.Id("err").Op("!=").Nil()).Block(
-						jen.Return(jen.Qual("fmt", "Errorf").Call(
-							jen.Lit(fmt.Sprintf("converting field %s: %%w", dtoField.Name)),
-							jen.Id("err"),
-						)),
+						jen.Return(mappingError(dtoName, sourceName, dtoField.Name, sourceFieldName, conv.Function)),
 					),
 					jen.Id("d").Dot(dtoField.Name).Op("=").Op("&").Id("result"),
 				),
-				jen.Comment(fmt.Sprintf("// %s: nil pointer will result in nil", dtoField.Name)),
-			}
+				dtoField, jen.New(jen.Id(ExtractBaseType(dtoField.Type))), nilPointers,
+			)
 		} else {
 			// *T -> dereference -> converter -> T
 			statements = []jen.Code{
-				jen.If(jen.Id("src").Dot(sourceFieldName).Op("!=").Nil()).Block(
+				jen.If(sourceAccess(sourceFieldName).Op("!=").Nil()).Block(
 					jen.Var().Id("err").Error(),
-					jen.List(jen.Id("d").Dot(dtoField.Name), jen.Id("err")).Op("=").Id(conv.Function).Call(
-						jen.Op("*").Id("src").Dot(sourceFieldName),
+					jen.List(jen.Id("d").Dot(dtoField.Name), jen.Id("err")).Op("=").Add(funcRef(conv.Function, typeArgs...)).Call(
+						jen.Op("*").Add(sourceAccess(sourceFieldName)),
 					),
 					jen.If(jen.Id("err").Op("!=").Nil()).Block(
-						jen.Return(jen.Qual("fmt", "Errorf").Call(
-							jen.Lit(fmt.Sprintf("converting field %s: %%w", dtoField.Name)),
-							jen.Id("err"),
-						)),
+						jen.Return(mappingError(dtoName, sourceName, dtoField.Name, sourceFieldName, conv.Function)),
 					),
 				),
-				jen.Comment(fmt.Sprintf("// %s: nil pointer will result in zero value", dtoField.Name)),
+				newNilPointerNote(dtoField, "zero value"),
 			}
 		}
 	} else if dstIsPointer {
@@ -245,14 +354,11 @@ func buildErrorReturningConverterMapping(
 			jen.Block(
 				jen.Var().Id("result").Id(ExtractBaseType(dtoField.Type)),
 				jen.Var().Id("err").Error(),
-				jen.List(jen.Id("result"), jen.Id("err")).Op("=").Id(conv.Function).Call(
-					jen.Id("src").Dot(sourceFieldName),
+				jen.List(jen.Id("result"), jen.Id("err")).Op("=").Add(funcRef(conv.Function, typeArgs...)).Call(
+					sourceAccess(sourceFieldName),
 				),
 				jen.If(jen.Id("err").Op("!=").Nil()).Block(
-					jen.Return(jen.Qual("fmt", "Errorf").Call(
-						jen.Lit(fmt.Sprintf("converting field %s: %%w", dtoField.Name)),
-						jen.Id("err"),
-					)),
+					jen.Return(mappingError(dtoName, sourceName, dtoField.Name, sourceFieldName, conv.Function)),
 				),
 				jen.Id("d").Dot(dtoField.Name).Op("=").Op("&").Id("result"),
 			),
@@ -262,14 +368,11 @@ func buildErrorReturningConverterMapping(
 		statements = []jen.Code{
 			jen.Block(
 				jen.Var().Id("err").Error(),
-				jen.List(jen.Id("d").Dot(dtoField.Name), jen.Id("err")).Op("=").Id(conv.Function).Call(
-					jen.Id("src").Dot(sourceFieldName),
+				jen.List(jen.Id("d").Dot(dtoField.Name), jen.Id("err")).Op("=").Add(funcRef(conv.Function, typeArgs...)).Call(
+					sourceAccess(sourceFieldName),
 				),
 				jen.If(jen.Id("err").Op("!=").Nil()).Block(
-					jen.Return(jen.Qual("fmt", "Errorf").Call(
-						jen.Lit(fmt.Sprintf("converting field %s: %%w", dtoField.Name)),
-						jen.Id("err"),
-					)),
+					jen.Return(mappingError(dtoName, sourceName, dtoField.Name, sourceFieldName, conv.Function)),
 				),
 			),
 		}
@@ -278,20 +381,96 @@ func buildErrorReturningConverterMapping(
 	return statements
 }
 
+// buildPointerConverterMapping creates statements for a converter taking a pointer, func(*T) U, or
+// returning one, func(T) *U. Source values are passed by address and source pointers as they are,
+// nil ones included; returned pointers are assigned to DTO pointers as they are and dereferenced
+// for DTO values, a nil result leaving the zero value.
+func buildPointerConverterMapping(
+	dtoName, sourceName string,
+	dtoField types.FieldInfo,
+	sourceField types.FieldTypeInfo,
+	sourceFieldName string,
+	conv config.ConverterDef,
+	typeArgs []jen.Code,
+	takesPointer, returnsPointer bool,
+	isSafe bool,
+	nilPointers bool,
+) []jen.Code {
+	dstIsPointer := strings.HasPrefix(dtoField.Type, "*")
+	target := func() *jen.Statement { return jen.Id("d").Dot(dtoField.Name) }
+
+	var body []jen.Code
+	arg := sourceAccess(sourceFieldName)
+	switch {
+	case takesPointer && !sourceField.IsPointer:
+		// Only variables are addressable
+		if _, ok := matcher.GetterName(sourceFieldName); ok {
+			body = append(body, jen.Id("v").Op(":=").Add(arg))
+			arg = jen.Op("&").Id("v")
+		} else {
+			arg = jen.Op("&").Add(arg)
+		}
+	case !takesPointer && sourceField.IsPointer:
+		arg = jen.Op("*").Add(arg)
+	}
+
+	result := funcRef(conv.Function, typeArgs...).Call(arg)
+	if !isSafe {
+		body = append(body,
+			jen.List(jen.Id("result"), jen.Err()).Op(":=").Add(result),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(mappingError(dtoName, sourceName, dtoField.Name, sourceFieldName, conv.Function)),
+			),
+		)
+		result = jen.Id("result")
+	}
+
+	switch {
+	case returnsPointer == dstIsPointer:
+		body = append(body, target().Op("=").Add(result))
+	case dstIsPointer:
+		if isSafe {
+			body = append(body, jen.Id("result").Op(":=").Add(result))
+		}
+		body = append(body, target().Op("=").Op("&").Id("result"))
+	default:
+		if isSafe {
+			body = append(body, jen.Id("result").Op(":=").Add(result))
+		}
+		body = append(body, jen.If(jen.Id("result").Op("!=").Nil()).Block(
+			target().Op("=").Op("*").Id("result"),
+		))
+	}
+
+	// Converters taking values are only called with the value of a non-nil source pointer
+	if !takesPointer && sourceField.IsPointer {
+		check := jen.If(sourceAccess(sourceFieldName).Op("!=").Nil()).Block(body...)
+		if dstIsPointer {
+			return nilPointerFallback(check, dtoField, jen.New(jen.Id(ExtractBaseType(dtoField.Type))), nilPointers)
+		}
+		return []jen.Code{check, newNilPointerNote(dtoField, "zero value")}
+	}
+
+	if len(body) == 1 {
+		return body
+	}
+	return []jen.Code{jen.Block(body...)}
+}
+
 // buildNestedDTOMapping creates statements for nested DTO mapping with pointer and slice handling
 func buildNestedDTOMapping(
-	dtoField types.FieldInfo, sourceField types.FieldTypeInfo, sourceFieldName string,
+	dtoField types.FieldInfo,
+	sourceField types.FieldTypeInfo,
+	sourceFieldName string,
+	source types.SourceStruct,
+	importMap map[string]string,
+	calls callContext,
 ) []jen.Code {
 	dtoTypeName := dtoField.NestedDTO
-	sourceTypeName := sourceField.BaseType
+	sourceTypeName := strings.TrimPrefix(sourceField.BaseType, "*")
 
 	// Determine the MapFrom method name based on source type
-	methodName := "MapFrom"
-	if strings.Contains(sourceTypeName, ".") {
-		methodName = "MapFrom" + ExtractTypeNameWithoutPackage(sourceTypeName)
-	} else {
-		methodName = "MapFrom" + sourceTypeName
-	}
+	methodName := calls.mapFromName(dtoTypeName, ExtractTypeNameWithoutPackage(sourceTypeName))
 
 	dtoIsPointer := strings.HasPrefix(dtoField.Type, "*")
 	dtoIsSlice := strings.HasPrefix(dtoField.Type, "[]")
@@ -300,16 +479,24 @@ func buildNestedDTOMapping(
 
 	// Handle slice to slice mapping
 	if dtoIsSlice && srcIsSlice {
-		return buildNestedSliceMapping(dtoField, sourceField, sourceFieldName, dtoTypeName, methodName)
+		if calls.sliceHelpers != nil {
+			return buildSliceHelperMapping(
+				dtoField.Name, sourceFieldName, strings.TrimPrefix(dtoTypeName, "*"), methodName,
+				ParseTypeForJen(QualifySourceType(sourceTypeName, source), importMap),
+				strings.HasPrefix(sourceField.Type, "[]*"), strings.HasPrefix(dtoField.Type, "[]*"),
+				calls,
+			)
+		}
+		return buildNestedSliceMapping(dtoField, sourceField, sourceFieldName, dtoTypeName, methodName, calls)
 	}
 
 	// Handle pointer to pointer
 	if dtoIsPointer && srcIsPointer {
-		return []jen.Code{
-			jen.If(jen.Id("src").Dot(sourceFieldName).Op("!=").Nil()).Block(
+		return nilPointerFallback(
+			jen.If(sourceAccess(sourceFieldName).Op("!=").Nil()).Block(
 				jen.Id("nested").Op(":=").Op("&").Id(dtoTypeName).Values(),
 				jen.Var().Id("err").Error(),
-				jen.Id("err").Op("=").Id("nested").Dot(methodName).Call(jen.Id("src").Dot(sourceFieldName)),
+				jen.Id("err").Op("=").Add(nestedCall(jen.Id("nested"), true, dtoTypeName, methodName, sourceAccess(sourceFieldName), calls)),
 				jen.If(
 					jen.Id("err").Op("!=").Nil(),
 				).Block(
@@ -320,17 +507,17 @@ func buildNestedDTOMapping(
 				),
 				jen.Id("d").Dot(dtoField.Name).Op("=").Id("nested"),
 			),
-			jen.Comment(fmt.Sprintf("// %s: nil pointer will result in nil", dtoField.Name)),
-		}
+			dtoField, jen.Op("&").Id(dtoTypeName).Values(), calls.nilPointers,
+		)
 	}
 
 	// Handle pointer to value
 	if !dtoIsPointer && srcIsPointer {
 		return []jen.Code{
-			jen.If(jen.Id("src").Dot(sourceFieldName).Op("!=").Nil()).Block(
+			jen.If(sourceAccess(sourceFieldName).Op("!=").Nil()).Block(
 				jen.Var().Id("nested").Id(dtoTypeName),
 				jen.Var().Id("err").Error(),
-				jen.Id("err").Op("=").Id("nested").Dot(methodName).Call(jen.Id("src").Dot(sourceFieldName)),
+				jen.Id("err").Op("=").Add(nestedCall(jen.Id("nested"), false, dtoTypeName, methodName, sourceAccess(sourceFieldName), calls)),
 				jen.If(
 					jen.Id("err").Op("!=").Nil(),
 				).Block(
@@ -341,7 +528,7 @@ func buildNestedDTOMapping(
 				),
 				jen.Id("d").Dot(dtoField.Name).Op("=").Id("nested"),
 			),
-			jen.Comment(fmt.Sprintf("// %s: nil pointer will result in zero value", dtoField.Name)),
+			newNilPointerNote(dtoField, "zero value"),
 		}
 	}
 
@@ -351,7 +538,7 @@ func buildNestedDTOMapping(
 			jen.Block(
 				jen.Id("nested").Op(":=").Op("&").Id(dtoTypeName).Values(),
 				jen.Var().Id("err").Error(),
-				jen.Id("err").Op("=").Id("nested").Dot(methodName).Call(jen.Op("&").Id("src").Dot(sourceFieldName)),
+				jen.Id("err").Op("=").Add(nestedCall(jen.Id("nested"), true, dtoTypeName, methodName, jen.Op("&").Add(sourceAccess(sourceFieldName)), calls)),
 				jen.If(
 					jen.Id("err").Op("!=").Nil(),
 				).Block(
@@ -370,7 +557,7 @@ func buildNestedDTOMapping(
 		jen.Block(
 			jen.Var().Id("nested").Id(dtoTypeName),
 			jen.Var().Id("err").Error(),
-			jen.Id("err").Op("=").Id("nested").Dot(methodName).Call(jen.Op("&").Id("src").Dot(sourceFieldName)),
+			jen.Id("err").Op("=").Add(nestedCall(jen.Id("nested"), false, dtoTypeName, methodName, jen.Op("&").Add(sourceAccess(sourceFieldName)), calls)),
 			jen.If(
 				jen.Id("err").Op("!=").Nil(),
 			).Block(
@@ -391,6 +578,7 @@ func buildNestedSliceMapping(
 	sourceFieldName string,
 	dtoTypeName string,
 	methodName string,
+	calls callContext,
 ) []jen.Code {
 	// Extract slice element types
 	dtoElemType := strings.TrimPrefix(dtoField.Type, "[]")
@@ -406,10 +594,10 @@ func buildNestedSliceMapping(
 	if !srcElemIsPointer && !dtoElemIsPointer {
 		return []jen.Code{
 			jen.Block(
-				jen.Id("d").Dot(dtoField.Name).Op("=").Make(jen.Index().Id(cleanDtoTypeName), jen.Len(jen.Id("src").Dot(sourceFieldName))),
-				jen.For(jen.List(jen.Id("i"), jen.Id("item")).Op(":=").Range().Id("src").Dot(sourceFieldName)).Block(
+				jen.Id("d").Dot(dtoField.Name).Op("=").Make(jen.Index().Id(cleanDtoTypeName), jen.Len(sourceAccess(sourceFieldName))),
+				jen.For(jen.List(jen.Id("i"), jen.Id("item")).Op(":=").Range().Add(sourceAccess(sourceFieldName))).Block(
 					jen.Var().Id("err").Error(),
-					jen.Id("err").Op("=").Id("d").Dot(dtoField.Name).Index(jen.Id("i")).Dot(methodName).Call(jen.Op("&").Id("item")),
+					jen.Id("err").Op("=").Add(nestedCall(jen.Id("d").Dot(dtoField.Name).Index(jen.Id("i")), false, cleanDtoTypeName, methodName, jen.Op("&").Id("item"), calls)),
 					jen.If(
 						jen.Id("err").Op("!=").Nil(),
 					).Block(
@@ -428,12 +616,12 @@ func buildNestedSliceMapping(
 	if srcElemIsPointer && dtoElemIsPointer {
 		return []jen.Code{
 			jen.Block(
-				jen.Id("d").Dot(dtoField.Name).Op("=").Make(jen.Index().Op("*").Id(cleanDtoTypeName), jen.Len(jen.Id("src").Dot(sourceFieldName))),
-				jen.For(jen.List(jen.Id("i"), jen.Id("item")).Op(":=").Range().Id("src").Dot(sourceFieldName)).Block(
+				jen.Id("d").Dot(dtoField.Name).Op("=").Make(jen.Index().Op("*").Id(cleanDtoTypeName), jen.Len(sourceAccess(sourceFieldName))),
+				jen.For(jen.List(jen.Id("i"), jen.Id("item")).Op(":=").Range().Add(sourceAccess(sourceFieldName))).Block(
 					jen.If(jen.Id("item").Op("!=").Nil()).Block(
 						jen.Id("nested").Op(":=").Op("&").Id(cleanDtoTypeName).Values(),
 						jen.Var().Id("err").Error(),
-						jen.Id("err").Op("=").Id("nested").Dot(methodName).Call(jen.Id("item")),
+						jen.Id("err").Op("=").Add(nestedCall(jen.Id("nested"), true, cleanDtoTypeName, methodName, jen.Id("item"), calls)),
 						jen.If(
 							jen.Id("err").Op("!=").Nil(),
 						).Block(
@@ -454,11 +642,11 @@ func buildNestedSliceMapping(
 	if !srcElemIsPointer && dtoElemIsPointer {
 		return []jen.Code{
 			jen.Block(
-				jen.Id("d").Dot(dtoField.Name).Op("=").Make(jen.Index().Op("*").Id(cleanDtoTypeName), jen.Len(jen.Id("src").Dot(sourceFieldName))),
-				jen.For(jen.List(jen.Id("i"), jen.Id("item")).Op(":=").Range().Id("src").Dot(sourceFieldName)).Block(
+				jen.Id("d").Dot(dtoField.Name).Op("=").Make(jen.Index().Op("*").Id(cleanDtoTypeName), jen.Len(sourceAccess(sourceFieldName))),
+				jen.For(jen.List(jen.Id("i"), jen.Id("item")).Op(":=").Range().Add(sourceAccess(sourceFieldName))).Block(
 					jen.Id("nested").Op(":=").Op("&").Id(cleanDtoTypeName).Values(),
 					jen.Var().Id("err").Error(),
-					jen.Id("err").Op("=").Id("nested").Dot(methodName).Call(jen.Op("&").Id("item")),
+					jen.Id("err").Op("=").Add(nestedCall(jen.Id("nested"), true, cleanDtoTypeName, methodName, jen.Op("&").Id("item"), calls)),
 					jen.If(
 						jen.Id("err").Op("!=").Nil(),
 					).Block(
@@ -478,12 +666,12 @@ func buildNestedSliceMapping(
 	if srcElemIsPointer && !dtoElemIsPointer {
 		return []jen.Code{
 			jen.Block(
-				jen.Id("d").Dot(dtoField.Name).Op("=").Make(jen.Index().Id(cleanDtoTypeName), jen.Lit(0), jen.Len(jen.Id("src").Dot(sourceFieldName))),
-				jen.For(jen.List(jen.Id("i"), jen.Id("item")).Op(":=").Range().Id("src").Dot(sourceFieldName)).Block(
+				jen.Id("d").Dot(dtoField.Name).Op("=").Make(jen.Index().Id(cleanDtoTypeName), jen.Lit(0), jen.Len(sourceAccess(sourceFieldName))),
+				jen.For(jen.List(jen.Id("i"), jen.Id("item")).Op(":=").Range().Add(sourceAccess(sourceFieldName))).Block(
 					jen.If(jen.Id("item").Op("!=").Nil()).Block(
 						jen.Var().Id("nested").Id(cleanDtoTypeName),
 						jen.Var().Id("err").Error(),
-						jen.Id("err").Op("=").Id("nested").Dot(methodName).Call(jen.Id("item")),
+						jen.Id("err").Op("=").Add(nestedCall(jen.Id("nested"), false, cleanDtoTypeName, methodName, jen.Id("item"), calls)),
 						jen.If(
 							jen.Id("err").Op("!=").Nil(),
 						).Block(
@@ -508,7 +696,7 @@ func buildNestedSliceMapping(
 
 // buildFieldMapping creates statements for field mapping with pointer conversion
 func buildFieldMapping(
-	dtoField types.FieldInfo, sourceField types.FieldTypeInfo, sourceFieldName string,
+	dtoField types.FieldInfo, sourceField types.FieldTypeInfo, sourceFieldName string, nilPointers bool,
 ) []jen.Code {
 	dtoIsPointer := strings.HasPrefix(dtoField.Type, "*")
 	srcIsPointer := sourceField.IsPointer
@@ -517,27 +705,64 @@ func buildFieldMapping(
 	dtoBaseType := ExtractBaseType(dtoField.Type)
 	srcBaseType := sourceField.BaseType
 
-	// If base types don't match, direct assignment
-	if dtoBaseType != srcBaseType {
+	// Distinct types of the same underlying type are converted to the DTO type, pointers to pointers,
+	// and numeric types cast to the wider DTO type
+	convert := func(value *jen.Statement) *jen.Statement { return value }
+	convertPointer := convert
+	widen := types.NumericWidening(dtoField, sourceField)
+	if widen {
+		convert = func(value *jen.Statement) *jen.Statement { return jen.Id(dtoBaseType).Call(value) }
+	} else if types.NeedsConversion(dtoField, sourceField) {
+		convert = func(value *jen.Statement) *jen.Statement { return jen.Id(dtoBaseType).Call(value) }
+		convertPointer = func(value *jen.Statement) *jen.Statement {
+			return jen.Parens(jen.Op("*").Id(dtoBaseType)).Call(value)
+		}
+	} else if dtoBaseType != srcBaseType && !dtoField.BaseIdentity.Identical(sourceField.BaseIdentity) {
+		// If base types don't match, direct assignment
 		return []jen.Code{
-			jen.Id("d").Dot(dtoField.Name).Op("=").Id("src").Dot(sourceFieldName),
+			jen.Id("d").Dot(dtoField.Name).Op("=").Add(sourceAccess(sourceFieldName)),
 		}
 	}
 
-	// Case 1: Both are pointers or both are values - direct assignment
+	// Pointers don't cast, the value pointed to is cast into a copy
+	if dtoIsPointer && srcIsPointer && widen {
+		return nilPointerFallback(
+			jen.If(sourceAccess(sourceFieldName).Op("!=").Nil()).Block(
+				jen.Id("v").Op(":=").Add(convert(jen.Op("*").Add(sourceAccess(sourceFieldName)))),
+				jen.Id("d").Dot(dtoField.Name).Op("=").Op("&").Id("v"),
+			),
+			dtoField, jen.New(jen.Id(dtoBaseType)), nilPointers,
+		)
+	}
+
+	// Case 1: Both are pointers or both are values - direct assignment, nil pointers may be replaced
+	// by pointers to zero values
+	if dtoIsPointer && srcIsPointer && !nilPointers {
+		return nilPointerFallback(
+			jen.If(sourceAccess(sourceFieldName).Op("!=").Nil()).Block(
+				jen.Id("d").Dot(dtoField.Name).Op("=").Add(convertPointer(sourceAccess(sourceFieldName))),
+			),
+			dtoField, jen.New(jen.Id(dtoBaseType)), nilPointers,
+		)
+	}
+	if dtoIsPointer && srcIsPointer {
+		return []jen.Code{
+			jen.Id("d").Dot(dtoField.Name).Op("=").Add(convertPointer(sourceAccess(sourceFieldName))),
+		}
+	}
 	if dtoIsPointer == srcIsPointer {
 		return []jen.Code{
-			jen.Id("d").Dot(dtoField.Name).Op("=").Id("src").Dot(sourceFieldName),
+			jen.Id("d").Dot(dtoField.Name).Op("=").Add(convert(sourceAccess(sourceFieldName))),
 		}
 	}
 
 	// Case 2: Source is pointer, destination is value
 	if srcIsPointer && !dtoIsPointer {
 		return []jen.Code{
-			jen.If(jen.Id("src").Dot(sourceFieldName).Op("!=").Nil()).Block(
-				jen.Id("d").Dot(dtoField.Name).Op("=").Op("*").Id("src").Dot(sourceFieldName),
+			jen.If(sourceAccess(sourceFieldName).Op("!=").Nil()).Block(
+				jen.Id("d").Dot(dtoField.Name).Op("=").Add(convert(jen.Op("*").Add(sourceAccess(sourceFieldName)))),
 			),
-			jen.Comment(fmt.Sprintf("// %s: nil pointer will result in zero value", dtoField.Name)),
+			newNilPointerNote(dtoField, "zero value"),
 		}
 	}
 
@@ -545,7 +770,7 @@ func buildFieldMapping(
 	if !srcIsPointer && dtoIsPointer {
 		return []jen.Code{
 			jen.Block(
-				jen.Id("v").Op(":=").Id("src").Dot(sourceFieldName),
+				jen.Id("v").Op(":=").Add(convert(sourceAccess(sourceFieldName))),
 				jen.Id("d").Dot(dtoField.Name).Op("=").Op("&").Id("v"),
 			),
 		}
@@ -553,6 +778,6 @@ func buildFieldMapping(
 
 	// Fallback
 	return []jen.Code{
-		jen.Id("d").Dot(dtoField.Name).Op("=").Id("src").Dot(sourceFieldName),
+		jen.Id("d").Dot(dtoField.Name).Op("=").Add(sourceAccess(sourceFieldName)),
 	}
 }
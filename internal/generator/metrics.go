@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"github.com/dave/jennifer/jen"
+)
+
+// mappingResult returns the result of a mapping method, naming the error when mappings are observed
+func mappingResult(cfg *config.Config) *jen.Statement {
+	if !cfg.Metrics {
+		return jen.Error()
+	}
+	return jen.Params(jen.Err().Error())
+}
+
+// withObserver prepends the statements reporting a mapping to MapObserver, if metrics are enabled.
+// The method must declare its error result with mappingResult.
+func withObserver(cfg *config.Config, dtoName, sourceName string, body []jen.Code) []jen.Code {
+	if !cfg.Metrics {
+		return body
+	}
+	return append([]jen.Code{
+		jen.If(runtimeRef(cfg, "MapObserver").Op("!=").Nil()).Block(
+			jen.Defer().Add(runtimeRef(cfg, observeMappingName(cfg))).Call(
+				jen.Lit(dtoName), jen.Lit(sourceName), jen.Qual("time", "Now").Call(), jen.Op("&").Err(),
+			),
+		),
+	}, body...)
+}
+
+// observeMappingName returns the name of the helper reporting mappings to MapObserver,
+// exported by the runtime package
+func observeMappingName(cfg *config.Config) string {
+	if cfg.RuntimePackage != "" {
+		return "ObserveMapping"
+	}
+	return "observeMapping"
+}
+
+// GenerateMapObserver generates the MapObserver hook and the helper reporting mappings to it,
+// unless the runtime package declares them
+func GenerateMapObserver(f *jen.File, cfg *config.Config) {
+	if cfg.RuntimePackage != "" {
+		return
+	}
+	writeMapObserver(f, observeMappingName(cfg))
+}
+
+// writeMapObserver declares the MapObserver hook and the helper of the given name reporting mappings to it
+func writeMapObserver(f *jen.File, helper string) {
+	f.Comment("MapObserver, when set, is called after every mapping with its duration and result")
+	f.Var().Id("MapObserver").Func().Params(
+		jen.List(jen.Id("dto"), jen.Id("source")).String(),
+		jen.Id("dur").Qual("time", "Duration"),
+		jen.Err().Error(),
+	)
+	f.Line()
+
+	f.Comment(helper + " reports a finished mapping to MapObserver")
+	f.Func().Id(helper).Params(
+		jen.List(jen.Id("dto"), jen.Id("source")).String(),
+		jen.Id("start").Qual("time", "Time"),
+		jen.Err().Op("*").Error(),
+	).Block(
+		jen.If(jen.Id("observer").Op(":=").Id("MapObserver"), jen.Id("observer").Op("!=").Nil()).Block(
+			jen.Id("observer").Call(jen.Id("dto"), jen.Id("source"), jen.Qual("time", "Since").Call(jen.Id("start")), jen.Op("*").Err()),
+		),
+	)
+	f.Line()
+}
@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// mapParams appends the options parameter to the parameters of a mapping method, if options are enabled
+func mapParams(cfg *config.Config, params ...jen.Code) []jen.Code {
+	if !cfg.Options {
+		return params
+	}
+	return append(params, jen.Id("opts").Op("...").Id("MapOption"))
+}
+
+// optionsArgs returns the options argument forwarded to another mapping call, if options are enabled
+func optionsArgs(enabled bool) []jen.Code {
+	if !enabled {
+		return nil
+	}
+	return []jen.Code{jen.Id("opts").Op("...")}
+}
+
+// GenerateMapOptions generates the MapOption type and the built-in options
+func GenerateMapOptions(f *jen.File) {
+	f.Comment("MapOption tunes a single mapping call")
+	f.Type().Id("MapOption").Func().Params(jen.Op("*").Id("mapOptions"))
+	f.Line()
+
+	f.Comment("mapOptions holds the options of a single mapping call")
+	f.Type().Id("mapOptions").Struct(
+		jen.Id("skip").Map(jen.String()).Bool(),
+		jen.Id("strict").Bool(),
+		jen.Id("nilAsZero").Bool(),
+	)
+	f.Line()
+
+	f.Comment("WithSkipFields leaves the given DTO fields untouched")
+	f.Func().Id("WithSkipFields").Params(jen.Id("fields").Op("...").String()).Id("MapOption").Block(
+		jen.Return(jen.Func().Params(jen.Id("o").Op("*").Id("mapOptions")).Block(
+			jen.For(jen.List(jen.Id("_"), jen.Id("field")).Op(":=").Range().Id("fields")).Block(
+				jen.Id("o").Dot("skip").Index(jen.Id("field")).Op("=").True(),
+			),
+		)),
+	)
+	f.Line()
+
+	f.Comment("WithStrict fails the mapping when a source field is nil or missing instead of leaving the zero value")
+	f.Func().Id("WithStrict").Params().Id("MapOption").Block(
+		jen.Return(jen.Func().Params(jen.Id("o").Op("*").Id("mapOptions")).Block(
+			jen.Id("o").Dot("strict").Op("=").True(),
+		)),
+	)
+	f.Line()
+
+	f.Comment("WithNilAsZero sets pointer fields left nil by the mapping to a pointer to the zero value")
+	f.Func().Id("WithNilAsZero").Params().Id("MapOption").Block(
+		jen.Return(jen.Func().Params(jen.Id("o").Op("*").Id("mapOptions")).Block(
+			jen.Id("o").Dot("nilAsZero").Op("=").True(),
+		)),
+	)
+	f.Line()
+
+	f.Comment("newMapOptions applies the options of a mapping call")
+	f.Func().Id("newMapOptions").Params(jen.Id("opts").Index().Id("MapOption")).Op("*").Id("mapOptions").Block(
+		jen.Id("o").Op(":=").Op("&").Id("mapOptions").Values(jen.Dict{
+			jen.Id("skip"): jen.Make(jen.Map(jen.String()).Bool()),
+		}),
+		jen.For(jen.List(jen.Id("_"), jen.Id("opt")).Op(":=").Range().Id("opts")).Block(
+			jen.Id("opt").Call(jen.Id("o")),
+		),
+		jen.Return(jen.Id("o")),
+	)
+	f.Line()
+}
+
+// wrapWithOptions guards the statements mapping a field with the options of the mapping call
+func wrapWithOptions(
+	dtoField types.FieldInfo,
+	sourceField types.FieldTypeInfo,
+	sourceFieldName string,
+	statements []jen.Code,
+	importMap map[string]string,
+) jen.Code {
+	var body []jen.Code
+
+	if sourceField.IsPointer {
+		body = append(body, jen.If(jen.Id("o").Dot("strict").Op("&&").Add(sourceAccess(sourceFieldName)).Op("==").Nil()).Block(
+			jen.Return(jen.Qual("errors", "New").Call(jen.Lit(fmt.Sprintf("mapping field %s: source is nil", dtoField.Name)))),
+		))
+	}
+
+	body = append(body, statements...)
+
+	if elemType, ok := strings.CutPrefix(dtoField.Type, "*"); ok {
+		body = append(body, jen.If(jen.Id("o").Dot("nilAsZero").Op("&&").Id("d").Dot(dtoField.Name).Op("==").Nil()).Block(
+			jen.Id("d").Dot(dtoField.Name).Op("=").New(ParseTypeForJen(elemType, importMap)),
+		))
+	}
+
+	return jen.If(jen.Op("!").Id("o").Dot("skip").Index(jen.Lit(dtoField.Name))).Block(body...)
+}
+
+// buildMissingFieldOption creates the strict check of a DTO field that has no source field
+func buildMissingFieldOption(dtoField types.FieldInfo) jen.Code {
+	return jen.If(jen.Id("o").Dot("strict").Op("&&").Op("!").Id("o").Dot("skip").Index(jen.Lit(dtoField.Name))).Block(
+		jen.Return(jen.Qual("errors", "New").Call(jen.Lit(fmt.Sprintf("mapping field %s: not found in source", dtoField.Name)))),
+	)
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"fmt"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// ParallelFunctionName returns the name of the concurrent batch mapping function, e.g. MapUserDTOsFromUserDBsParallel
+func ParallelFunctionName(sourceName, dtoName string) string {
+	return "Map" + dtoName + "sFrom" + ExtractTypeNameWithoutPackage(sourceName) + "sParallel"
+}
+
+// GenerateParallelFunction generates a function mapping a slice of sources on a bounded pool of goroutines.
+// The order of the sources is preserved, no further items are started after the first error.
+func GenerateParallelFunction(
+	f *jen.File,
+	dto types.DTOMapping,
+	sourceName, methodName string,
+	importMap map[string]string,
+	cfg *config.Config,
+	calls callContext,
+) {
+	funcName := ParallelFunctionName(sourceName, dto.Name)
+
+	// Function-style DTOs have no MapFrom method to call
+	mapCall := jen.Id("dst").Index(jen.Id("i")).Dot(methodName).Call(jen.Op("&").Id("src").Index(jen.Id("i")))
+	if calls.functions[dto.Name] {
+		mapCall = jen.Id(mapFunctionImplName(sourceName, dto.Name)).Call(
+			jen.Op("&").Id("dst").Index(jen.Id("i")),
+			jen.Op("&").Id("src").Index(jen.Id("i")),
+		)
+	}
+
+	f.Comment(fmt.Sprintf("%s maps every %s to %s on up to workers goroutines, preserving their order.", funcName, sourceName, dto.Name))
+	f.Comment("No further items are mapped after the first error. Workers below 1 default to GOMAXPROCS.")
+	f.Func().Id(funcName).Params(
+		jen.Id("src").Index().Add(ParseTypeRefForJen(sourceName, importMap)),
+		jen.Id("workers").Int(),
+	).Params(jen.Index().Id(dto.Name), jen.Error()).Block(
+		jen.If(jen.Id("workers").Op("<").Lit(1)).Block(
+			jen.Id("workers").Op("=").Qual("runtime", "GOMAXPROCS").Call(jen.Lit(0)),
+		),
+		jen.Line(),
+		jen.Id("dst").Op(":=").Make(jen.Index().Id(dto.Name), jen.Len(jen.Id("src"))),
+		jen.List(jen.Id("g"), jen.Id("ctx")).Op(":=").Qual("golang.org/x/sync/errgroup", "WithContext").Call(
+			jen.Qual("context", "Background").Call(),
+		),
+		jen.Id("g").Dot("SetLimit").Call(jen.Id("workers")),
+		jen.Line(),
+		jen.For(jen.Id("i").Op(":=").Range().Id("src")).Block(
+			// Go blocks while all workers are busy, so a failure stops the loop early
+			jen.If(jen.Id("ctx").Dot("Err").Call().Op("!=").Nil()).Block(
+				jen.Break(),
+			),
+			shadowLoopVar(cfg, "i"),
+			jen.Id("g").Dot("Go").Call(jen.Func().Params().Error().Block(
+				jen.If(jen.Err().Op(":=").Add(mapCall), jen.Err().Op("!=").Nil()).Block(
+					jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("mapping item %d: %w"), jen.Id("i"), jen.Err())),
+				),
+				jen.Return(jen.Nil()),
+			)),
+		),
+		jen.Line(),
+		jen.If(jen.Err().Op(":=").Id("g").Dot("Wait").Call(), jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Err()),
+		),
+		jen.Return(jen.Id("dst"), jen.Nil()),
+	)
+	f.Line()
+}
@@ -0,0 +1,166 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// GenerateApplyToMethod generates an ApplyTo method that writes the non-nil fields of a patch DTO into a source struct
+func GenerateApplyToMethod(
+	f *jen.File,
+	dto types.DTOMapping,
+	source types.SourceStruct,
+	sourceName, methodName string,
+	cfg *config.Config,
+	importMap map[string]string,
+	functions map[string]types.FunctionInfo,
+) {
+	paramType := ParseTypeRefForJen(sourceName, importMap)
+
+	f.Comment(fmt.Sprintf("%s writes the non-nil fields of %s into %s", methodName, dto.Name, sourceName))
+
+	methodBody := withObserver(cfg, dto.Name, source.Name, buildApplyMethodBody(dto, source, cfg, importMap, functions))
+
+	f.Func().Params(
+		jen.Id("d").Op("*").Id(dto.Name),
+	).Id(methodName).Params(
+		jen.Id("dst").Op("*").Add(paramType),
+	).Add(mappingResult(cfg)).Block(methodBody...)
+
+	f.Line()
+}
+
+// buildApplyMethodBody constructs the ApplyTo method body
+func buildApplyMethodBody(
+	dto types.DTOMapping,
+	source types.SourceStruct,
+	cfg *config.Config,
+	importMap map[string]string,
+	functions map[string]types.FunctionInfo,
+) []jen.Code {
+	statements := []jen.Code{
+		jen.If(jen.Id("dst").Op("==").Nil()).Block(
+			jen.Return(jen.Qual("errors", "New").Call(jen.Lit("destination is nil"))),
+		),
+		jen.Line(),
+	}
+
+	converterMap := buildConverterMap(cfg)
+	m := matcher.New(cfg, dto)
+
+	for _, dtoField := range dto.Fields {
+		if dtoField.Ignore {
+			continue
+		}
+
+		targetFieldName, targetField, exists := m.Resolve(dtoField, source)
+
+		switch {
+		case !exists:
+			statements = append(statements,
+				jen.Comment(fmt.Sprintf("%s: not found in destination, skipped", dtoField.Name)),
+			)
+		case strings.HasSuffix(targetFieldName, "()"):
+			statements = append(statements,
+				jen.Comment(fmt.Sprintf("%s: read through getter %s, skipped", dtoField.Name, targetFieldName)),
+			)
+		case !strings.HasPrefix(dtoField.Type, "*"):
+			statements = append(statements,
+				jen.Comment(fmt.Sprintf("%s: not a pointer, skipped", dtoField.Name)),
+			)
+		case dtoField.Redact != "":
+			statements = append(statements,
+				jen.Comment(fmt.Sprintf("%s: redacted, skipped", dtoField.Name)),
+			)
+		case types.NumericWidening(dtoField, targetField):
+			statements = append(statements,
+				jen.Comment(fmt.Sprintf("%s: widened from %s, not narrowed back", dtoField.Name, targetField.Type)),
+			)
+		case dtoField.NestedDTO != "", len(dtoField.TypeCases) > 0:
+			statements = append(statements,
+				jen.Comment(fmt.Sprintf("%s: nested DTOs are not applied", dtoField.Name)),
+			)
+		case dtoField.ConverterTag != "":
+			conv, ok := converterMap[dtoField.ConverterTag]
+			if !ok || conv.Inverter == "" {
+				statements = append(statements,
+					jen.Comment(fmt.Sprintf("%s: converter '%s' has no inverter, skipped", dtoField.Name, dtoField.ConverterTag)),
+				)
+				continue
+			}
+
+			fn, fnExists := functions[conv.Inverter]
+			isSafe := fnExists && parser.IsSafeConverterSignature(fn)
+
+			typeArgs := converterTypeArgs(fn, dtoField.Type, targetField.Type, true, importMap)
+
+			statements = append(statements, buildApplyInverterMapping(dto.Name, source.Name, dtoField, targetField, targetFieldName, conv, typeArgs, isSafe))
+		default:
+			statements = append(statements, buildApplyFieldMapping(dtoField, targetField, targetFieldName,
+				reverseConversion(dtoField, targetField, source, importMap)))
+		}
+	}
+
+	statements = append(statements, jen.Line(), jen.Return(jen.Nil()))
+	return statements
+}
+
+// buildApplyFieldMapping creates the statement copying a non-nil pointer field into the destination,
+// converting the value to convertTo when given
+func buildApplyFieldMapping(
+	dtoField types.FieldInfo, targetField types.FieldTypeInfo, targetFieldName string, convertTo jen.Code,
+) jen.Code {
+	value := jen.Op("*").Id("d").Dot(dtoField.Name)
+	if convertTo != nil {
+		value = jen.Add(convertTo).Call(value)
+	}
+
+	if targetField.IsPointer {
+		// Copy the value so the destination does not alias the DTO
+		return jen.If(jen.Id("d").Dot(dtoField.Name).Op("!=").Nil()).Block(
+			jen.Id("v").Op(":=").Add(value),
+			jen.Id("dst").Dot(targetFieldName).Op("=").Op("&").Id("v"),
+		)
+	}
+
+	return jen.If(jen.Id("d").Dot(dtoField.Name).Op("!=").Nil()).Block(
+		jen.Id("dst").Dot(targetFieldName).Op("=").Add(value),
+	)
+}
+
+// buildApplyInverterMapping creates the statement converting a non-nil pointer field back with the inverter
+func buildApplyInverterMapping(
+	dtoName, sourceName string,
+	dtoField types.FieldInfo,
+	targetField types.FieldTypeInfo,
+	targetFieldName string,
+	conv config.ConverterDef,
+	typeArgs []jen.Code,
+	isSafe bool,
+) jen.Code {
+	assign := jen.Id("dst").Dot(targetFieldName).Op("=").Id("result")
+	if targetField.IsPointer {
+		assign = jen.Id("dst").Dot(targetFieldName).Op("=").Op("&").Id("result")
+	}
+
+	if isSafe {
+		return jen.If(jen.Id("d").Dot(dtoField.Name).Op("!=").Nil()).Block(
+			jen.Id("result").Op(":=").Add(funcRef(conv.Inverter, typeArgs...)).Call(jen.Op("*").Id("d").Dot(dtoField.Name)),
+			assign,
+		)
+	}
+
+	return jen.If(jen.Id("d").Dot(dtoField.Name).Op("!=").Nil()).Block(
+		jen.List(jen.Id("result"), jen.Id("err")).Op(":=").Add(funcRef(conv.Inverter, typeArgs...)).Call(jen.Op("*").Id("d").Dot(dtoField.Name)),
+		jen.If(jen.Id("err").Op("!=").Nil()).Block(
+			jen.Return(mappingError(dtoName, sourceName, dtoField.Name, targetFieldName, conv.Inverter)),
+		),
+		assign,
+	)
+}
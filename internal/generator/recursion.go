@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// FindRecursiveDTOs returns the DTOs that can reach themselves through nested DTO fields
+func FindRecursiveDTOs(dtos []types.DTOMapping) map[string]bool {
+	dtoMap := make(map[string]types.DTOMapping)
+	for _, dto := range dtos {
+		dtoMap[dto.Name] = dto
+	}
+
+	recursive := make(map[string]bool)
+	for _, dto := range dtos {
+		for _, next := range nestedDTONames(dto) {
+			if reachesDTO(dtoMap, next, dto.Name, map[string]bool{}) {
+				recursive[dto.Name] = true
+				break
+			}
+		}
+	}
+	return recursive
+}
+
+// nestedDTONames lists the DTOs referenced by the nested and type switch fields of a DTO
+func nestedDTONames(dto types.DTOMapping) []string {
+	var names []string
+	for _, field := range dto.Fields {
+		if field.Ignore {
+			continue
+		}
+		if field.NestedDTO != "" {
+			names = append(names, field.NestedDTO)
+		}
+		for _, typeCase := range field.TypeCases {
+			names = append(names, ExtractBaseType(typeCase.DTO))
+		}
+	}
+	return names
+}
+
+// reachesDTO reports whether the DTO 'to' can be reached from 'from'
+func reachesDTO(dtoMap map[string]types.DTOMapping, from, to string, visited map[string]bool) bool {
+	if from == to {
+		return true
+	}
+	if visited[from] {
+		return false
+	}
+	visited[from] = true
+
+	for _, next := range nestedDTONames(dtoMap[from]) {
+		if reachesDTO(dtoMap, next, to, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// depthMethodName returns the name of the unexported depth-tracking variant of a MapFrom method
+func depthMethodName(methodName string) string {
+	return strings.ToLower(methodName[:1]) + methodName[1:] + "WithDepth"
+}
+
+// GenerateDepthLimitedMapFromMethod generates a MapFrom method for a recursive DTO that delegates to
+// a depth-tracking variant, failing once nesting exceeds the configured maximum depth.
+// The calls context has to list the recursive DTOs as depth guarded.
+func GenerateDepthLimitedMapFromMethod(
+	f *jen.File,
+	dto types.DTOMapping,
+	source types.SourceStruct,
+	sourceName, methodName string,
+	cfg *config.Config,
+	importMap map[string]string,
+	functions map[string]types.FunctionInfo,
+	calls callContext,
+) {
+	paramType := ParseTypeRefForJen(sourceName, importMap)
+	depthName := depthMethodName(methodName)
+
+	f.Comment(fmt.Sprintf("%s maps from %s to %s", methodName, sourceName, dto.Name))
+	commentMappingTable(f, mappingTable(dto, source, cfg, false))
+	f.Func().Params(
+		jen.Id("d").Op("*").Id(dto.Name),
+	).Id(methodName).Params(
+		mapParams(cfg, jen.Id("src").Op("*").Add(paramType))...,
+	).Error().Block(
+		jen.Return(jen.Id("d").Dot(depthName).Call(append([]jen.Code{jen.Id("src"), jen.Lit(0)}, optionsArgs(cfg.Options)...)...)),
+	)
+	f.Line()
+
+	methodBody := []jen.Code{
+		jen.If(jen.Id("depth").Op(">").Lit(cfg.MaxDepth)).Block(
+			jen.Return(jen.Qual("errors", "New").Call(
+				jen.Lit(fmt.Sprintf("mapping %s: maximum depth of %d exceeded", dto.Name, cfg.MaxDepth)),
+			)),
+		),
+		jen.Line(),
+	}
+	methodBody = append(methodBody, buildMethodBody(dto, source, cfg, importMap, functions, calls)...)
+	methodBody = withObserver(cfg, dto.Name, source.Name, methodBody)
+
+	f.Comment(fmt.Sprintf("%s maps from %s to %s at the given nesting depth", depthName, sourceName, dto.Name))
+	f.Func().Params(
+		jen.Id("d").Op("*").Id(dto.Name),
+	).Id(depthName).Params(
+		mapParams(cfg, jen.Id("src").Op("*").Add(paramType), jen.Id("depth").Int())...,
+	).Add(mappingResult(cfg)).Block(methodBody...)
+	f.Line()
+}
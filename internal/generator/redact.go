@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// redactedPlaceholder replaces fully redacted values
+const redactedPlaceholder = "[REDACTED]"
+
+// redactHelperName returns the name of the generated helper implementing a redaction strategy
+func redactHelperName(strategy string) string {
+	return "automapperRedact" + strings.ToUpper(strategy[:1]) + strategy[1:]
+}
+
+// UsedRedactions returns the redaction strategies used by any DTO field, in a stable order
+func UsedRedactions(dtos []types.DTOMapping) []string {
+	var strategies []string
+	for _, dto := range dtos {
+		for _, field := range dto.Fields {
+			if field.Redact != "" && !field.Ignore && !slices.Contains(strategies, field.Redact) {
+				strategies = append(strategies, field.Redact)
+			}
+		}
+	}
+	slices.Sort(strategies)
+	return strategies
+}
+
+// buildRedactedMapping creates statements assigning the redacted source string to the DTO field
+func buildRedactedMapping(
+	dtoField types.FieldInfo, sourceField types.FieldTypeInfo, sourceFieldName string, nilPointers bool,
+) []jen.Code {
+	helper := redactHelperName(dtoField.Redact)
+	dtoIsPointer := strings.HasPrefix(dtoField.Type, "*")
+
+	if sourceField.IsPointer {
+		value := jen.Op("*").Add(sourceAccess(sourceFieldName))
+		if dtoIsPointer {
+			return nilPointerFallback(
+				jen.If(sourceAccess(sourceFieldName).Op("!=").Nil()).Block(
+					jen.Id("v").Op(":=").Id(helper).Call(value),
+					jen.Id("d").Dot(dtoField.Name).Op("=").Op("&").Id("v"),
+				),
+				dtoField, jen.New(jen.String()), nilPointers,
+			)
+		}
+		return []jen.Code{
+			jen.If(sourceAccess(sourceFieldName).Op("!=").Nil()).Block(
+				jen.Id("d").Dot(dtoField.Name).Op("=").Id(helper).Call(value),
+			),
+			newNilPointerNote(dtoField, "zero value"),
+		}
+	}
+
+	if dtoIsPointer {
+		return []jen.Code{
+			jen.Block(
+				jen.Id("v").Op(":=").Id(helper).Call(sourceAccess(sourceFieldName)),
+				jen.Id("d").Dot(dtoField.Name).Op("=").Op("&").Id("v"),
+			),
+		}
+	}
+
+	return []jen.Code{
+		jen.Id("d").Dot(dtoField.Name).Op("=").Id(helper).Call(sourceAccess(sourceFieldName)),
+	}
+}
+
+// GenerateRedactHelper generates the helper implementing a redaction strategy
+func GenerateRedactHelper(f *jen.File, strategy string) {
+	name := redactHelperName(strategy)
+
+	var body []jen.Code
+	switch strategy {
+	case types.RedactFull:
+		f.Comment(fmt.Sprintf("%s replaces non-empty values with %s", name, redactedPlaceholder))
+		body = []jen.Code{
+			jen.If(jen.Id("s").Op("==").Lit("")).Block(jen.Return(jen.Lit(""))),
+			jen.Return(jen.Lit(redactedPlaceholder)),
+		}
+	case types.RedactMask4:
+		f.Comment(fmt.Sprintf("%s masks all but the last four characters", name))
+		body = []jen.Code{
+			jen.Id("r").Op(":=").Index().Rune().Parens(jen.Id("s")),
+			jen.If(jen.Len(jen.Id("r")).Op("<=").Lit(4)).Block(
+				jen.Return(jen.Qual("strings", "Repeat").Call(jen.Lit("*"), jen.Len(jen.Id("r")))),
+			),
+			jen.Return(
+				jen.Qual("strings", "Repeat").Call(jen.Lit("*"), jen.Len(jen.Id("r")).Op("-").Lit(4)).
+					Op("+").String().Parens(jen.Id("r").Index(jen.Len(jen.Id("r")).Op("-").Lit(4), jen.Empty())),
+			),
+		}
+	case types.RedactHash:
+		f.Comment(fmt.Sprintf("%s replaces non-empty values with a short SHA-256 fingerprint", name))
+		body = []jen.Code{
+			jen.If(jen.Id("s").Op("==").Lit("")).Block(jen.Return(jen.Lit(""))),
+			jen.Id("sum").Op(":=").Qual("crypto/sha256", "Sum256").Call(jen.Index().Byte().Parens(jen.Id("s"))),
+			jen.Return(jen.Lit("sha256:").Op("+").Qual("encoding/hex", "EncodeToString").Call(
+				jen.Id("sum").Index(jen.Empty(), jen.Lit(8)),
+			)),
+		}
+	default:
+		return
+	}
+
+	f.Func().Id(name).Params(jen.Id("s").String()).String().Block(body...)
+	f.Line()
+}
@@ -0,0 +1,87 @@
+package generator
+
+import "github.com/dave/jennifer/jen"
+
+// registryEntry describes a generated mapping registered for its type pair
+type registryEntry struct {
+	from, to jen.Code
+	// mapInto builds the statement mapping src into dst, both pointers
+	mapInto func(dst, src jen.Code) *jen.Statement
+}
+
+// newMapFromEntry registers the mapping from a source into a DTO
+func newMapFromEntry(dtoName, sourceName, methodName string, importMap map[string]string, calls callContext) registryEntry {
+	return registryEntry{
+		from: ParseTypeRefForJen(sourceName, importMap),
+		to:   jen.Id(dtoName),
+		mapInto: func(dst, src jen.Code) *jen.Statement {
+			if calls.functions[dtoName] {
+				return jen.Id(mapFunctionImplName(sourceName, dtoName)).Call(dst, src)
+			}
+			return jen.Add(dst).Dot(methodName).Call(src)
+		},
+	}
+}
+
+// newMapToEntry registers the mapping from a bidirectional DTO back into a source
+func newMapToEntry(dtoName, sourceName, methodName string, importMap map[string]string) registryEntry {
+	return registryEntry{
+		from: jen.Id(dtoName),
+		to:   ParseTypeRefForJen(sourceName, importMap),
+		mapInto: func(dst, src jen.Code) *jen.Statement {
+			return jen.Add(src).Dot(methodName).Call(dst)
+		},
+	}
+}
+
+// typeOf builds the reflect.Type expression of a type without needing a value of it
+func typeOf(t jen.Code) *jen.Statement {
+	return jen.Qual("reflect", "TypeOf").Call(jen.Parens(jen.Op("*").Add(t)).Parens(jen.Nil())).Dot("Elem").Call()
+}
+
+// GenerateRegistry generates a registry of all mappings keyed by their type pair together with the generic MapperFor lookup
+func GenerateRegistry(f *jen.File, entries []registryEntry) {
+	registry := jen.Dict{}
+	for _, entry := range entries {
+		key := jen.Values(typeOf(entry.from), typeOf(entry.to))
+		registry[key] = jen.Func().Params(
+			jen.Id("src").Op("*").Add(entry.from),
+		).Params(jen.Op("*").Add(entry.to), jen.Error()).Block(
+			jen.Id("dst").Op(":=").Op("&").Add(entry.to).Values(),
+			jen.If(jen.Err().Op(":=").Add(entry.mapInto(jen.Id("dst"), jen.Id("src"))), jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Nil(), jen.Err()),
+			),
+			jen.Return(jen.Id("dst"), jen.Nil()),
+		)
+	}
+
+	f.Comment("automapperKey identifies a mapping by its source and target type")
+	f.Type().Id("automapperKey").Struct(
+		jen.List(jen.Id("from"), jen.Id("to")).Qual("reflect", "Type"),
+	)
+	f.Line()
+
+	f.Comment("automapperRegistry holds every generated mapping as a func(*From) (*To, error)")
+	f.Var().Id("automapperRegistry").Op("=").Map(jen.Id("automapperKey")).Any().Values(registry)
+	f.Line()
+
+	f.Comment("MapperFor returns the generated mapping from From to To and reports whether one is registered")
+	f.Func().Id("MapperFor").Types(
+		jen.List(jen.Id("From"), jen.Id("To")).Any(),
+	).Params().Params(
+		jen.Func().Params(jen.Op("*").Id("From")).Params(jen.Op("*").Id("To"), jen.Error()),
+		jen.Bool(),
+	).Block(
+		jen.List(jen.Id("mapper"), jen.Id("ok")).Op(":=").Id("automapperRegistry").Index(
+			jen.Id("automapperKey").Values(typeOf(jen.Id("From")), typeOf(jen.Id("To"))),
+		),
+		jen.If(jen.Op("!").Id("ok")).Block(
+			jen.Return(jen.Nil(), jen.False()),
+		),
+		jen.Return(
+			jen.Id("mapper").Assert(jen.Func().Params(jen.Op("*").Id("From")).Params(jen.Op("*").Id("To"), jen.Error())),
+			jen.True(),
+		),
+	)
+	f.Line()
+}
@@ -0,0 +1,396 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// GenerateMapToMethod generates a MapTo method writing every mapped field of a bidirectional DTO back into a source struct
+func GenerateMapToMethod(
+	f *jen.File,
+	dto types.DTOMapping,
+	source types.SourceStruct,
+	sourceName, methodName string,
+	cfg *config.Config,
+	importMap map[string]string,
+	functions map[string]types.FunctionInfo,
+) {
+	paramType := ParseTypeRefForJen(sourceName, importMap)
+
+	f.Comment(fmt.Sprintf("%s maps from %s back to %s", methodName, dto.Name, sourceName))
+	commentMappingTable(f, mappingTable(dto, source, cfg, true))
+
+	methodBody, _ := buildMapToMethodBody(dto, source, cfg, importMap, functions, nil, false)
+	methodBody = withObserver(cfg, dto.Name, source.Name, methodBody)
+
+	f.Func().Params(
+		jen.Id("d").Op("*").Id(dto.Name),
+	).Id(methodName).Params(
+		jen.Id("dst").Op("*").Add(paramType),
+	).Add(mappingResult(cfg)).Block(methodBody...)
+
+	f.Line()
+}
+
+// ToNewMethodName returns the name of the method mapping a bidirectional DTO to a new source struct, e.g. ToUserDB
+func ToNewMethodName(sourceName string) string {
+	return "To" + ExtractTypeNameWithoutPackage(sourceName)
+}
+
+// GenerateToNewMethod generates a method allocating a new source struct and filling it with the MapTo method
+func GenerateToNewMethod(
+	f *jen.File,
+	dto types.DTOMapping,
+	sourceName, mapToMethodName string,
+	importMap map[string]string,
+) {
+	methodName := ToNewMethodName(sourceName)
+
+	f.Comment(fmt.Sprintf("%s maps from %s to a new %s", methodName, dto.Name, sourceName))
+	f.Func().Params(
+		jen.Id("d").Op("*").Id(dto.Name),
+	).Id(methodName).Params().Params(
+		jen.Op("*").Add(ParseTypeRefForJen(sourceName, importMap)), jen.Error(),
+	).Block(
+		jen.Id("dst").Op(":=").Op("&").Add(ParseTypeForJen(sourceName, importMap)).Values(),
+		jen.If(
+			jen.Err().Op(":=").Id("d").Dot(mapToMethodName).Call(jen.Id("dst")),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return(jen.Nil(), jen.Err()),
+		),
+		jen.Return(jen.Id("dst"), jen.Nil()),
+	)
+	f.Line()
+}
+
+// GenerateMergeToMethod generates a MergeTo method writing the mapped fields of a DTO back into
+// the fields of a source struct that are still zero, leaving populated fields untouched.
+// Returns whether the generic zero-value helper is used.
+func GenerateMergeToMethod(
+	f *jen.File,
+	dto types.DTOMapping,
+	source types.SourceStruct,
+	sourceName, methodName string,
+	cfg *config.Config,
+	importMap map[string]string,
+	functions map[string]types.FunctionInfo,
+	sources map[string]types.SourceStruct,
+) bool {
+	paramType := ParseTypeRefForJen(sourceName, importMap)
+
+	f.Comment(fmt.Sprintf("%s maps from %s into the zero-valued fields of %s", methodName, dto.Name, sourceName))
+	commentMappingTable(f, mappingTable(dto, source, cfg, true))
+
+	methodBody, usesHelper := buildMapToMethodBody(dto, source, cfg, importMap, functions, sources, true)
+	methodBody = withObserver(cfg, dto.Name, source.Name, methodBody)
+
+	f.Func().Params(
+		jen.Id("d").Op("*").Id(dto.Name),
+	).Id(methodName).Params(
+		jen.Id("dst").Op("*").Add(paramType),
+	).Add(mappingResult(cfg)).Block(methodBody...)
+
+	f.Line()
+	return usesHelper
+}
+
+// buildMapToMethodBody constructs the MapTo method body. When merging, every field is only
+// written while it is zero in the destination; the second result reports whether the
+// generic zero-value helper is used for that.
+func buildMapToMethodBody(
+	dto types.DTOMapping,
+	source types.SourceStruct,
+	cfg *config.Config,
+	importMap map[string]string,
+	functions map[string]types.FunctionInfo,
+	sources map[string]types.SourceStruct,
+	merge bool,
+) ([]jen.Code, bool) {
+	statements := []jen.Code{
+		jen.If(jen.Id("dst").Op("==").Nil()).Block(
+			jen.Return(jen.Qual("errors", "New").Call(jen.Lit("destination is nil"))),
+		),
+		jen.Line(),
+	}
+
+	converterMap := buildConverterMap(cfg)
+	m := matcher.New(cfg, dto)
+	usesHelper := false
+
+	// onlyIfZero guards the statements writing a field when merging
+	onlyIfZero := func(dtoField types.FieldInfo, targetField types.FieldTypeInfo, targetFieldName string, fieldStatements []jen.Code) []jen.Code {
+		if !merge {
+			return fieldStatements
+		}
+
+		condition, helper, ok := buildZeroCheck(jen.Id("dst").Dot(targetFieldName), targetField, source, sources, true)
+		if !ok {
+			return append([]jen.Code{
+				jen.Comment(fmt.Sprintf("%s: %s is not comparable, always assigned", dtoField.Name, targetField.Type)),
+			}, fieldStatements...)
+		}
+
+		usesHelper = usesHelper || helper
+		return []jen.Code{jen.If(condition).Block(fieldStatements...)}
+	}
+
+	for _, dtoField := range dto.Fields {
+		if dtoField.Ignore {
+			continue
+		}
+
+		targetFieldName, targetField, exists := m.Resolve(dtoField, source)
+
+		switch {
+		case !exists:
+			statements = append(statements,
+				jen.Comment(fmt.Sprintf("%s: not found in destination, skipped", dtoField.Name)),
+			)
+		case strings.HasSuffix(targetFieldName, "()"):
+			statements = append(statements,
+				jen.Comment(fmt.Sprintf("%s: read through getter %s, skipped", dtoField.Name, targetFieldName)),
+			)
+		case len(dtoField.TypeCases) > 0:
+			statements = append(statements,
+				jen.Comment(fmt.Sprintf("%s: interface fields are not mapped back", dtoField.Name)),
+			)
+		case dtoField.Redact != "":
+			statements = append(statements,
+				jen.Comment(fmt.Sprintf("%s: redacted, skipped", dtoField.Name)),
+			)
+		case types.NumericWidening(dtoField, targetField):
+			statements = append(statements,
+				jen.Comment(fmt.Sprintf("%s: widened from %s, not narrowed back", dtoField.Name, targetField.Type)),
+			)
+		case dtoField.NestedDTO != "":
+			statements = append(statements, onlyIfZero(dtoField, targetField, targetFieldName,
+				buildReverseNestedMapping(dtoField, targetField, targetFieldName, source, importMap, cfg.MethodNames()))...)
+		case dtoField.ConverterTag != "":
+			conv, ok := converterMap[dtoField.ConverterTag]
+			if !ok || conv.Inverter == "" {
+				statements = append(statements,
+					jen.Comment(fmt.Sprintf("%s: converter '%s' has no inverter, skipped", dtoField.Name, dtoField.ConverterTag)),
+				)
+				continue
+			}
+
+			fn, fnExists := functions[conv.Inverter]
+			isSafe := fnExists && parser.IsSafeConverterSignature(fn)
+
+			typeArgs := converterTypeArgs(fn, dtoField.Type, targetField.Type, true, importMap)
+
+			statements = append(statements, onlyIfZero(dtoField, targetField, targetFieldName,
+				buildReverseFieldMapping(dto.Name, source.Name, dtoField, targetField, targetFieldName, conv.Inverter, typeArgs, isSafe, nil))...)
+		default:
+			statements = append(statements, onlyIfZero(dtoField, targetField, targetFieldName,
+				buildReverseFieldMapping(dto.Name, source.Name, dtoField, targetField, targetFieldName, "", nil, true,
+					reverseConversion(dtoField, targetField, source, importMap)))...)
+		}
+	}
+
+	statements = append(statements, jen.Line(), jen.Return(jen.Nil()))
+	return statements, usesHelper
+}
+
+// buildReverseFieldMapping creates statements writing a DTO field into the destination,
+// passing the value through the inverter when one is given, instantiated with typeArgs, or
+// converting it to convertTo
+func buildReverseFieldMapping(
+	dtoName, sourceName string,
+	dtoField types.FieldInfo,
+	targetField types.FieldTypeInfo,
+	targetFieldName string,
+	inverter string,
+	typeArgs []jen.Code,
+	isSafe bool,
+	convertTo jen.Code,
+) []jen.Code {
+	dtoIsPointer := strings.HasPrefix(dtoField.Type, "*")
+	target := func() *jen.Statement { return jen.Id("dst").Dot(targetFieldName) }
+	value := func() *jen.Statement {
+		if dtoIsPointer {
+			return jen.Op("*").Id("d").Dot(dtoField.Name)
+		}
+		return jen.Id("d").Dot(dtoField.Name)
+	}
+	if convertTo != nil {
+		value = func() *jen.Statement {
+			if dtoIsPointer {
+				return jen.Add(convertTo).Call(jen.Op("*").Id("d").Dot(dtoField.Name))
+			}
+			return jen.Add(convertTo).Call(jen.Id("d").Dot(dtoField.Name))
+		}
+	}
+
+	// Matching pointer shapes are assigned as is, like the forward mapping does
+	if inverter == "" && dtoIsPointer == targetField.IsPointer {
+		switch {
+		case convertTo != nil && dtoIsPointer:
+			return []jen.Code{target().Op("=").Parens(jen.Op("*").Add(convertTo)).Call(jen.Id("d").Dot(dtoField.Name))}
+		case convertTo != nil:
+			return []jen.Code{target().Op("=").Add(value())}
+		}
+		return []jen.Code{target().Op("=").Id("d").Dot(dtoField.Name)}
+	}
+
+	var body []jen.Code
+	result := value()
+	resultIsVar := false
+	if inverter != "" && isSafe {
+		result = funcRef(inverter, typeArgs...).Call(value())
+	} else if inverter != "" {
+		body = append(body,
+			jen.List(jen.Id("result"), jen.Err()).Op(":=").Add(funcRef(inverter, typeArgs...)).Call(value()),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(mappingError(dtoName, sourceName, dtoField.Name, targetFieldName, inverter)),
+			),
+		)
+		result = jen.Id("result")
+		resultIsVar = true
+	}
+
+	if targetField.IsPointer {
+		// Only variables are addressable
+		if !resultIsVar {
+			body = append(body, jen.Id("v").Op(":=").Add(result))
+			result = jen.Id("v")
+		}
+		body = append(body, target().Op("=").Op("&").Add(result))
+	} else {
+		body = append(body, target().Op("=").Add(result))
+	}
+
+	if dtoIsPointer {
+		if targetField.IsPointer {
+			return []jen.Code{
+				jen.If(jen.Id("d").Dot(dtoField.Name).Op("!=").Nil()).Block(body...).Else().Block(
+					target().Op("=").Nil(),
+				),
+			}
+		}
+		return []jen.Code{
+			jen.If(jen.Id("d").Dot(dtoField.Name).Op("!=").Nil()).Block(body...),
+			jen.Comment(fmt.Sprintf("// %s: nil pointer leaves the destination unchanged", dtoField.Name)),
+		}
+	}
+
+	if len(body) == 1 {
+		return body
+	}
+	return []jen.Code{jen.Block(body...)}
+}
+
+// reverseConversion returns the type a DTO field converts to when mapped back to a field of a
+// distinct type of the same underlying type, nil when it is assigned as is
+func reverseConversion(
+	dtoField types.FieldInfo, targetField types.FieldTypeInfo, source types.SourceStruct, importMap map[string]string,
+) jen.Code {
+	if !types.NeedsConversion(dtoField, targetField) {
+		return nil
+	}
+	return ParseTypeForJen(QualifySourceType(targetField.BaseType, source), importMap)
+}
+
+// buildReverseNestedMapping creates statements mapping a nested DTO back through its MapTo method
+func buildReverseNestedMapping(
+	dtoField types.FieldInfo,
+	targetField types.FieldTypeInfo,
+	targetFieldName string,
+	source types.SourceStruct,
+	importMap map[string]string,
+	names *config.MethodNames,
+) []jen.Code {
+	targetTypeName := QualifySourceType(strings.TrimPrefix(targetField.BaseType, "*"), source)
+	methodName := names.MapTo(dtoField.NestedDTO, ExtractTypeNameWithoutPackage(targetTypeName))
+	targetType := ParseTypeForJen(targetTypeName, importMap)
+
+	dtoType := strings.TrimPrefix(dtoField.Type, "[]")
+	targetElemType := strings.TrimPrefix(targetField.Type, "[]")
+
+	receiver := func() *jen.Statement { return jen.Id("d").Dot(dtoField.Name) }
+	target := func() *jen.Statement { return jen.Id("dst").Dot(targetFieldName) }
+	label := jen.Lit(fmt.Sprintf("mapping nested field %s: %%w", dtoField.Name))
+
+	if strings.HasPrefix(dtoField.Type, "[]") {
+		receiver = func() *jen.Statement { return jen.Id("d").Dot(dtoField.Name).Index(jen.Id("i")) }
+		target = func() *jen.Statement { return jen.Id("dst").Dot(targetFieldName).Index(jen.Id("i")) }
+		label = jen.Lit(fmt.Sprintf("mapping nested field %s[%%d]: %%w", dtoField.Name))
+
+		elemType := targetType
+		if strings.HasPrefix(targetElemType, "*") {
+			elemType = jen.Op("*").Add(ParseTypeForJen(targetTypeName, importMap))
+		}
+
+		return []jen.Code{
+			jen.Id("dst").Dot(targetFieldName).Op("=").Make(jen.Index().Add(elemType), jen.Len(jen.Id("d").Dot(dtoField.Name))),
+			jen.For(jen.Id("i").Op(":=").Range().Id("d").Dot(dtoField.Name)).Block(
+				buildReverseNestedCall(
+					receiver, strings.HasPrefix(dtoType, "*"),
+					target, strings.HasPrefix(targetElemType, "*"),
+					ParseTypeForJen(targetTypeName, importMap), methodName, label, jen.Id("i"),
+				)...,
+			),
+		}
+	}
+
+	return buildReverseNestedCall(
+		receiver, strings.HasPrefix(dtoType, "*"),
+		target, targetField.IsPointer,
+		targetType, methodName, label,
+	)
+}
+
+// buildReverseNestedCall creates statements calling a nested DTO's MapTo method with pointer handling
+// on both sides, the label arguments are passed to the error message before the wrapped error
+func buildReverseNestedCall(
+	receiver func() *jen.Statement,
+	receiverIsPointer bool,
+	target func() *jen.Statement,
+	targetIsPointer bool,
+	targetType jen.Code,
+	methodName string,
+	label jen.Code,
+	labelArgs ...jen.Code,
+) []jen.Code {
+	errArgs := append(append([]jen.Code{label}, labelArgs...), jen.Err())
+	call := func(dst jen.Code) jen.Code {
+		return jen.If(
+			jen.Err().Op(":=").Add(receiver()).Dot(methodName).Call(dst),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return(jen.Qual("fmt", "Errorf").Call(errArgs...)),
+		)
+	}
+
+	var body []jen.Code
+	if targetIsPointer {
+		body = []jen.Code{
+			jen.Id("nested").Op(":=").Op("&").Add(targetType).Values(),
+			call(jen.Id("nested")),
+			target().Op("=").Id("nested"),
+		}
+	} else {
+		body = []jen.Code{call(jen.Op("&").Add(target()))}
+	}
+
+	if !receiverIsPointer {
+		if targetIsPointer {
+			return []jen.Code{jen.Block(body...)}
+		}
+		return body
+	}
+
+	if targetIsPointer {
+		return []jen.Code{
+			jen.If(receiver().Op("!=").Nil()).Block(body...).Else().Block(target().Op("=").Nil()),
+		}
+	}
+	return []jen.Code{jen.If(receiver().Op("!=").Nil()).Block(body...)}
+}
@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// RoundTripMethodName returns the name of the round-trip check of a bidirectional DTO,
+// e.g. RoundTripEqual, or RoundTripEqualUserDB when the DTO has several sources
+func RoundTripMethodName(dto types.DTOMapping, sourceName string) string {
+	if len(dto.Sources) == 1 {
+		return "RoundTripEqual"
+	}
+	return "RoundTripEqual" + ExtractTypeNameWithoutPackage(sourceName)
+}
+
+// GenerateRoundTripMethod generates a method mapping a source to a new DTO and back,
+// reporting the source fields that do not survive the round trip.
+// The receiver is left unchanged; when a mapping fails every compared field is reported.
+func GenerateRoundTripMethod(
+	f *jen.File,
+	dto types.DTOMapping,
+	source types.SourceStruct,
+	sourceName, mapFromMethodName string,
+	cfg *config.Config,
+	importMap map[string]string,
+	calls callContext,
+) {
+	methodName := RoundTripMethodName(dto, sourceName)
+
+	// Every source field read by the mapping is expected back, getters cannot be written
+	var fields []string
+	m := matcher.New(cfg, dto)
+	for _, dtoField := range dto.Fields {
+		if dtoField.Ignore {
+			continue
+		}
+		sourceFieldName, _, exists := m.Resolve(dtoField, source)
+		if !exists || strings.HasSuffix(sourceFieldName, "()") || slices.Contains(fields, sourceFieldName) {
+			continue
+		}
+		fields = append(fields, sourceFieldName)
+	}
+
+	fieldNames := make([]jen.Code, len(fields))
+	for i, field := range fields {
+		fieldNames[i] = jen.Lit(field)
+	}
+
+	statements := []jen.Code{
+		jen.Id("fields").Op(":=").Index().String().Values(fieldNames...),
+		jen.Id("mapped").Op(":=").Op("&").Id(dto.Name).Values(),
+		jen.If(
+			jen.Err().Op(":=").Add(mapIntoCall(dto.Name, sourceName, mapFromMethodName, jen.Id("mapped"), jen.Id("src"), calls)),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return(jen.False(), jen.Id("fields")),
+		),
+		jen.List(jen.Id("back"), jen.Err()).Op(":=").Id("mapped").Dot(ToNewMethodName(sourceName)).Call(),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.False(), jen.Id("fields")),
+		),
+		jen.Line(),
+		jen.Var().Id("lossy").Index().String(),
+	}
+
+	for _, field := range fields {
+		statements = append(statements,
+			jen.If(jen.Op("!").Qual("reflect", "DeepEqual").Call(
+				jen.Id("src").Dot(field),
+				jen.Id("back").Dot(field),
+			)).Block(
+				jen.Id("lossy").Op("=").Append(jen.Id("lossy"), jen.Lit(field)),
+			),
+		)
+	}
+
+	statements = append(statements, jen.Return(jen.Len(jen.Id("lossy")).Op("==").Lit(0), jen.Id("lossy")))
+
+	f.Comment(fmt.Sprintf("%s maps %s to a new %s and back, reporting the source fields lost on the way", methodName, sourceName, dto.Name))
+	f.Func().Params(
+		jen.Id("d").Op("*").Id(dto.Name),
+	).Id(methodName).Params(
+		jen.Id("src").Op("*").Add(ParseTypeRefForJen(sourceName, importMap)),
+	).Params(jen.Bool(), jen.Index().String()).Block(statements...)
+	f.Line()
+}
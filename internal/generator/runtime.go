@@ -0,0 +1,34 @@
+package generator
+
+import (
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"github.com/dave/jennifer/jen"
+)
+
+// RuntimeFileName is the file the runtime command writes the shared infrastructure to
+const RuntimeFileName = "automapper_runtime.go"
+
+// runtimeRef refers to a declaration of the configured runtime package, or of the generated
+// package itself when no runtime package is configured
+func runtimeRef(cfg *config.Config, name string) *jen.Statement {
+	return jen.Qual(cfg.RuntimePackage, name)
+}
+
+// GenerateRuntime creates the file of a runtime package declaring the infrastructure shared by
+// the packages whose configuration names it in runtimePackage
+func GenerateRuntime(pkgName string, cfg *config.Config) *jen.File {
+	f := newGeneratedFile(pkgName, cfg)
+	f.PackageComment("Package " + pkgName + " declares the infrastructure shared by generated mappers")
+
+	writeMappingErrorType(f, cfg)
+	writeFieldDiffType(f, cfg)
+	writeMapObserver(f, "ObserveMapping")
+	return f
+}
+
+// generateRuntimeAlias declares a type of the runtime package under the same name in the generated package
+func generateRuntimeAlias(f *jen.File, cfg *config.Config, name string) {
+	f.Commentf("%s is shared by the packages mapping through %s", name, cfg.RuntimePackage)
+	f.Type().Id(name).Op("=").Add(runtimeRef(cfg, name))
+	f.Line()
+}
@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"fmt"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// SafeMethodName returns the name of the variant of a mapping that cannot fail,
+// e.g. MapFromUserDBSafe or MapUserDBToUserDTOSafe
+func SafeMethodName(dtoName, sourceName, methodName string, calls callContext) string {
+	if calls.functions[dtoName] {
+		return MapFunctionName(sourceName, dtoName) + "Safe"
+	}
+	return methodName + "Safe"
+}
+
+// CannotFail reports whether mapping a DTO from a source only assigns fields directly or through
+// converters without an error return, so the mapping fails on a nil source alone
+func CannotFail(
+	dto types.DTOMapping,
+	source types.SourceStruct,
+	cfg *config.Config,
+	functions map[string]types.FunctionInfo,
+) bool {
+	converterMap := buildConverterMap(cfg)
+	m := matcher.New(cfg, dto)
+
+	for _, dtoField := range dto.Fields {
+		if dtoField.Ignore {
+			continue
+		}
+		if _, _, exists := m.Resolve(dtoField, source); !exists {
+			continue
+		}
+
+		// Nested DTOs and type switches report errors of their own
+		if dtoField.NestedDTO != "" || len(dtoField.TypeCases) > 0 {
+			return false
+		}
+		if dtoField.Redact == "" && dtoField.ConverterTag != "" {
+			conv, exists := converterMap[dtoField.ConverterTag]
+			if !exists {
+				return false
+			}
+			fn, fnExists := functions[conv.Function]
+			if !fnExists || !parser.IsSafeConverterSignature(fn) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// GenerateSafeMethod generates the variant of a MapFrom method or mapping function without an error
+// return, for mappings that cannot fail. It panics on a nil source, the only error left.
+func GenerateSafeMethod(
+	f *jen.File,
+	dto types.DTOMapping,
+	sourceName, methodName string,
+	cfg *config.Config,
+	importMap map[string]string,
+	calls callContext,
+) {
+	safeName := SafeMethodName(dto.Name, sourceName, methodName, calls)
+	params := mapParams(cfg, jen.Id("src").Op("*").Add(ParseTypeRefForJen(sourceName, importMap)))
+	args := append([]jen.Code{jen.Id("src")}, optionsArgs(cfg.Options)...)
+
+	if calls.functions[dto.Name] {
+		funcName := MapFunctionName(sourceName, dto.Name)
+
+		f.Comment(fmt.Sprintf("%s is %s for a mapping that cannot fail, it panics on a nil source", safeName, funcName))
+		f.Func().Id(safeName).Params(params...).Id(dto.Name).Block(
+			jen.List(jen.Id("d"), jen.Err()).Op(":=").Id(funcName).Call(args...),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Panic(jen.Err()),
+			),
+			jen.Return(jen.Id("d")),
+		)
+		f.Line()
+		return
+	}
+
+	f.Comment(fmt.Sprintf("%s is %s for a mapping that cannot fail, it panics on a nil source", safeName, methodName))
+	f.Func().Params(
+		jen.Id("d").Op("*").Id(dto.Name),
+	).Id(safeName).Params(params...).Block(
+		jen.If(jen.Err().Op(":=").Id("d").Dot(methodName).Call(args...), jen.Err().Op("!=").Nil()).Block(
+			jen.Panic(jen.Err()),
+		),
+	)
+	f.Line()
+}
@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+)
+
+// Generic helpers mapping slices of nested DTOs, one per pointer combination of the elements
+const (
+	mapSliceHelper           = "mapSlice"           // []T -> []DTO
+	mapPointerSliceHelper    = "mapPointerSlice"    // []*T -> []*DTO, nil elements stay nil
+	mapSliceToPointersHelper = "mapSliceToPointers" // []T -> []*DTO
+	mapNonNilSliceHelper     = "mapNonNilSlice"     // []*T -> []DTO, nil elements are skipped
+)
+
+// sliceHelperName returns the generic helper mapping a slice with the given element pointers
+func sliceHelperName(srcElemIsPointer, dtoElemIsPointer bool) string {
+	switch {
+	case srcElemIsPointer && dtoElemIsPointer:
+		return mapPointerSliceHelper
+	case srcElemIsPointer:
+		return mapNonNilSliceHelper
+	case dtoElemIsPointer:
+		return mapSliceToPointersHelper
+	default:
+		return mapSliceHelper
+	}
+}
+
+// sliceElementMapper returns the function filling a nested DTO from a source element: the method
+// expression or the package-level function, or a closure when the call needs more arguments
+func sliceElementMapper(dtoTypeName, methodName string, srcElemType jen.Code, calls callContext) jen.Code {
+	if !calls.depthGuard[dtoTypeName] && !calls.options {
+		if calls.functions[dtoTypeName] {
+			return jen.Id(mapFunctionImplName(strings.TrimPrefix(methodName, "MapFrom"), dtoTypeName))
+		}
+		return jen.Parens(jen.Op("*").Id(dtoTypeName)).Dot(methodName)
+	}
+
+	return jen.Func().Params(
+		jen.Id("nested").Op("*").Id(dtoTypeName),
+		jen.Id("item").Op("*").Add(srcElemType),
+	).Error().Block(
+		jen.Return(nestedCall(jen.Id("nested"), true, dtoTypeName, methodName, jen.Id("item"), calls)),
+	)
+}
+
+// buildSliceHelperMapping creates statements mapping a slice of nested DTOs through a generic helper
+func buildSliceHelperMapping(
+	dtoFieldName, sourceFieldName, dtoTypeName, methodName string,
+	srcElemType jen.Code,
+	srcElemIsPointer, dtoElemIsPointer bool,
+	calls callContext,
+) []jen.Code {
+	helper := sliceHelperName(srcElemIsPointer, dtoElemIsPointer)
+	calls.sliceHelpers[helper] = true
+
+	return []jen.Code{
+		jen.Block(
+			jen.Var().Id("err").Error(),
+			jen.List(jen.Id("d").Dot(dtoFieldName), jen.Id("err")).Op("=").Id(helper).Call(
+				sourceAccess(sourceFieldName),
+				sliceElementMapper(dtoTypeName, methodName, srcElemType, calls),
+			),
+			jen.If(jen.Id("err").Op("!=").Nil()).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(
+					jen.Lit(fmt.Sprintf("mapping nested field %s: %%w", dtoFieldName)),
+					jen.Id("err"),
+				)),
+			),
+		),
+	}
+}
+
+// usedSliceHelpers returns the recorded generic slice helpers in a stable order
+func usedSliceHelpers(calls callContext) []string {
+	var helpers []string
+	for helper := range calls.sliceHelpers {
+		helpers = append(helpers, helper)
+	}
+	slices.Sort(helpers)
+	return helpers
+}
+
+// GenerateSliceHelper generates one of the generic helpers mapping slices of nested DTOs
+func GenerateSliceHelper(f *jen.File, helper string) {
+	fill := jen.Id("fill").Func().Params(jen.Op("*").Id("D"), jen.Op("*").Id("S")).Error()
+	typeParams := []jen.Code{jen.List(jen.Id("S"), jen.Id("D")).Any()}
+	indexErr := func() jen.Code {
+		return jen.Return(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("index %d: %w"), jen.Id("i"), jen.Err()))
+	}
+
+	switch helper {
+	case mapSliceHelper:
+		f.Comment(fmt.Sprintf("%s maps every element of src into a new slice with fill", helper))
+		f.Func().Id(helper).Types(typeParams...).Params(
+			jen.Id("src").Index().Id("S"), fill,
+		).Params(jen.Index().Id("D"), jen.Error()).Block(
+			jen.Id("dst").Op(":=").Make(jen.Index().Id("D"), jen.Len(jen.Id("src"))),
+			jen.For(jen.Id("i").Op(":=").Range().Id("src")).Block(
+				jen.If(
+					jen.Err().Op(":=").Id("fill").Call(jen.Op("&").Id("dst").Index(jen.Id("i")), jen.Op("&").Id("src").Index(jen.Id("i"))),
+					jen.Err().Op("!=").Nil(),
+				).Block(indexErr()),
+			),
+			jen.Return(jen.Id("dst"), jen.Nil()),
+		)
+
+	case mapPointerSliceHelper:
+		f.Comment(fmt.Sprintf("%s maps every element of src into a new slice of pointers with fill, keeping nil elements", helper))
+		f.Func().Id(helper).Types(typeParams...).Params(
+			jen.Id("src").Index().Op("*").Id("S"), fill,
+		).Params(jen.Index().Op("*").Id("D"), jen.Error()).Block(
+			jen.Id("dst").Op(":=").Make(jen.Index().Op("*").Id("D"), jen.Len(jen.Id("src"))),
+			jen.For(jen.List(jen.Id("i"), jen.Id("item")).Op(":=").Range().Id("src")).Block(
+				jen.If(jen.Id("item").Op("==").Nil()).Block(jen.Continue()),
+				jen.Id("dst").Index(jen.Id("i")).Op("=").New(jen.Id("D")),
+				jen.If(
+					jen.Err().Op(":=").Id("fill").Call(jen.Id("dst").Index(jen.Id("i")), jen.Id("item")),
+					jen.Err().Op("!=").Nil(),
+				).Block(indexErr()),
+			),
+			jen.Return(jen.Id("dst"), jen.Nil()),
+		)
+
+	case mapSliceToPointersHelper:
+		f.Comment(fmt.Sprintf("%s maps every element of src into a new slice of pointers with fill", helper))
+		f.Func().Id(helper).Types(typeParams...).Params(
+			jen.Id("src").Index().Id("S"), fill,
+		).Params(jen.Index().Op("*").Id("D"), jen.Error()).Block(
+			jen.Id("dst").Op(":=").Make(jen.Index().Op("*").Id("D"), jen.Len(jen.Id("src"))),
+			jen.For(jen.Id("i").Op(":=").Range().Id("src")).Block(
+				jen.Id("dst").Index(jen.Id("i")).Op("=").New(jen.Id("D")),
+				jen.If(
+					jen.Err().Op(":=").Id("fill").Call(jen.Id("dst").Index(jen.Id("i")), jen.Op("&").Id("src").Index(jen.Id("i"))),
+					jen.Err().Op("!=").Nil(),
+				).Block(indexErr()),
+			),
+			jen.Return(jen.Id("dst"), jen.Nil()),
+		)
+
+	case mapNonNilSliceHelper:
+		f.Comment(fmt.Sprintf("%s maps the non-nil elements of src into a new slice with fill", helper))
+		f.Func().Id(helper).Types(typeParams...).Params(
+			jen.Id("src").Index().Op("*").Id("S"), fill,
+		).Params(jen.Index().Id("D"), jen.Error()).Block(
+			jen.Id("dst").Op(":=").Make(jen.Index().Id("D"), jen.Lit(0), jen.Len(jen.Id("src"))),
+			jen.For(jen.List(jen.Id("i"), jen.Id("item")).Op(":=").Range().Id("src")).Block(
+				jen.If(jen.Id("item").Op("==").Nil()).Block(jen.Continue()),
+				jen.Var().Id("nested").Id("D"),
+				jen.If(
+					jen.Err().Op(":=").Id("fill").Call(jen.Op("&").Id("nested"), jen.Id("item")),
+					jen.Err().Op("!=").Nil(),
+				).Block(indexErr()),
+				jen.Id("dst").Op("=").Append(jen.Id("dst"), jen.Id("nested")),
+			),
+			jen.Return(jen.Id("dst"), jen.Nil()),
+		)
+	}
+	f.Line()
+}
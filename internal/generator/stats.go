@@ -0,0 +1,181 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"slices"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+)
+
+// MappingStats counts how the fields of a DTO are mapped from one of its sources
+type MappingStats struct {
+	DTO          string   `json:"dto"`
+	Source       string   `json:"source"`
+	Through      string   `json:"through,omitempty"` // via=DTO or sources=A>B for mappings through a DTO or fallback
+	DTOFields    int      `json:"dtoFields"`
+	Mapped       int      `json:"mapped"`       // fields assigned from the source, converted ones included
+	Converted    int      `json:"converted"`    // fields assigned through a converter
+	Ignored      int      `json:"ignored"`      // fields tagged automapper:"-"
+	ZeroValued   int      `json:"zeroValued"`   // fields without a source field, left at their zero value
+	SourceFields int      `json:"sourceFields"` // exported source fields, the others can't be mapped
+	Consumed     int      `json:"consumed"`     // source fields read by the mapping
+	Unused       []string `json:"unused"`       // source fields the mapping never reads
+}
+
+// Coverage returns the share of the source fields read by the mapping, in percent
+func (s MappingStats) Coverage() float64 {
+	if s.SourceFields == 0 {
+		return 100
+	}
+	return float64(s.Consumed) * 100 / float64(s.SourceFields)
+}
+
+// PackageStats gathers the mapping statistics of the DTOs of a package
+type PackageStats struct {
+	Mappings   []MappingStats `json:"mappings"`
+	Converters map[string]int `json:"converters"` // fields converted by each converter, unused ones included
+}
+
+// Stats counts, for every DTO and source, the source fields consumed and the DTO fields mapped,
+// ignored or left at their zero value, along with the number of fields each converter converts.
+// Mappings through another DTO and priority fallbacks count as mappings from each of their sources.
+func Stats(
+	dtos []types.DTOMapping,
+	sources map[string]types.SourceStruct,
+	cfg *config.Config,
+) (PackageStats, error) {
+	converterMap := buildConverterMap(cfg)
+	stats := PackageStats{Mappings: []MappingStats{}, Converters: make(map[string]int)}
+	for _, conv := range cfg.Converters {
+		stats.Converters[conv.Name] = 0
+	}
+	dtoIndex := make(map[string]types.DTOMapping, len(dtos))
+	for _, dto := range dtos {
+		dtoIndex[dto.Name] = dto
+	}
+
+	// read resolves the source fields a mapping reads, by DTO field, counting the DTO fields, and the
+	// converters when the mapping is one of its own rather than a hop of a mapping through a DTO
+	read := func(dto types.DTOMapping, sourceName string, mapping *MappingStats, countConverters bool) (map[string]string, error) {
+		source, ok := sources[sourceName]
+		if !ok {
+			return nil, fmt.Errorf("source struct %s not found for DTO %s", sourceName, dto.Name)
+		}
+		m := matcher.New(cfg, dto)
+		reads := make(map[string]string)
+		for _, dtoField := range dto.Fields {
+			name, _, exists := m.Resolve(dtoField, source)
+			switch {
+			case dtoField.Ignore:
+				mapping.Ignored++
+			case !exists || strings.HasPrefix(describeMapping(dtoField, converterMap), "skipped"):
+				mapping.ZeroValued++
+			default:
+				mapping.Mapped++
+				reads[dtoField.Name] = strings.TrimSuffix(name, "()")
+				if dtoField.ConverterTag != "" && dtoField.NestedDTO == "" && dtoField.Redact == "" {
+					mapping.Converted++
+					if countConverters {
+						stats.Converters[dtoField.ConverterTag]++
+					}
+				}
+			}
+		}
+		return reads, nil
+	}
+
+	add := func(mapping MappingStats, consumed map[string]bool) {
+		for fieldName := range sources[mapping.Source].Fields {
+			if !ast.IsExported(fieldName) {
+				continue
+			}
+			mapping.SourceFields++
+			if consumed[fieldName] {
+				mapping.Consumed++
+			} else {
+				mapping.Unused = append(mapping.Unused, fieldName)
+			}
+		}
+		slices.Sort(mapping.Unused)
+		stats.Mappings = append(stats.Mappings, mapping)
+	}
+
+	for _, dto := range dtos {
+		for _, sourceName := range dto.Sources {
+			mapping := newMappingStats(dto, sourceName, "")
+			reads, err := read(dto, sourceName, &mapping, true)
+			if err != nil {
+				return PackageStats{}, err
+			}
+			add(mapping, valueSet(reads))
+		}
+
+		if len(dto.PrioritySources) > 1 {
+			through := "sources=" + strings.Join(dto.PrioritySources, ">")
+			for _, sourceName := range dto.PrioritySources {
+				mapping := newMappingStats(dto, sourceName, through)
+				reads, err := read(dto, sourceName, &mapping, true)
+				if err != nil {
+					return PackageStats{}, err
+				}
+				add(mapping, valueSet(reads))
+			}
+		}
+
+		// A field mapped through a DTO reads the source field the intermediate DTO maps its field from
+		viaDTO, ok := dtoIndex[dto.Via]
+		if dto.Via == "" || !ok {
+			continue
+		}
+		var viaReads MappingStats
+		reads, err := read(dto, dto.Via, &viaReads, false)
+		if err != nil {
+			return PackageStats{}, err
+		}
+		for _, sourceName := range viaDTO.Sources {
+			var first MappingStats
+			firstReads, err := read(viaDTO, sourceName, &first, false)
+			if err != nil {
+				return PackageStats{}, err
+			}
+			mapping := newMappingStats(dto, sourceName, "via="+dto.Via)
+			consumed := make(map[string]bool)
+			for _, dtoField := range dto.Fields {
+				sourceField, ok := firstReads[reads[dtoField.Name]]
+				switch {
+				case dtoField.Ignore:
+					mapping.Ignored++
+				case !ok:
+					mapping.ZeroValued++
+				default:
+					mapping.Mapped++
+					consumed[sourceField] = true
+					if dtoField.ConverterTag != "" && dtoField.NestedDTO == "" && dtoField.Redact == "" {
+						mapping.Converted++
+					}
+				}
+			}
+			add(mapping, consumed)
+		}
+	}
+
+	return stats, nil
+}
+
+// newMappingStats starts the statistics of a mapping
+func newMappingStats(dto types.DTOMapping, sourceName, through string) MappingStats {
+	return MappingStats{DTO: dto.Name, Source: sourceName, Through: through, DTOFields: len(dto.Fields), Unused: []string{}}
+}
+
+// valueSet returns the set of the values of a map
+func valueSet(m map[string]string) map[string]bool {
+	set := make(map[string]bool, len(m))
+	for _, value := range m {
+		set[value] = true
+	}
+	return set
+}
@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"fmt"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// StreamFunctionName returns the name of the channel mapping function, e.g. StreamUserDTOsFromUserDBs
+func StreamFunctionName(sourceName, dtoName string) string {
+	return "Stream" + dtoName + "sFrom" + ExtractTypeNameWithoutPackage(sourceName) + "s"
+}
+
+// GenerateStreamFunction generates a function mapping every source received from a channel to a DTO.
+// Mapping stops at the first error or when the context is cancelled, the error is sent on the error channel.
+func GenerateStreamFunction(
+	f *jen.File,
+	dto types.DTOMapping,
+	sourceName, methodName string,
+	cfg *config.Config,
+	importMap map[string]string,
+	calls callContext,
+) {
+	funcName := StreamFunctionName(sourceName, dto.Name)
+	sourceType := ParseTypeRefForJen(sourceName, importMap)
+
+	// Function-style DTOs have no MapFrom method to call
+	mapCall := jen.Id("dto").Dot(methodName).Call(jen.Op("&").Id("item"))
+	if calls.functions[dto.Name] {
+		mapCall = jen.Id(mapFunctionImplName(sourceName, dto.Name)).Call(jen.Op("&").Id("dto"), jen.Op("&").Id("item"))
+	}
+	if cfg.Tracing {
+		mapCall = tracedCall(spanName(dto.Name, sourceName, methodName, calls), mapCall)
+	}
+
+	cancelled := jen.Case(jen.Op("<-").Id("ctx").Dot("Done").Call()).Block(
+		jen.Id("errs").Op("<-").Id("ctx").Dot("Err").Call(),
+		jen.Return(),
+	)
+
+	f.Comment(fmt.Sprintf("%s maps every %s received from in to %s until in is closed.", funcName, sourceName, dto.Name))
+	f.Comment("Mapping stops at the first error or when ctx is cancelled, the error is sent on the error channel.")
+	f.Func().Id(funcName).Params(
+		jen.Id("ctx").Qual("context", "Context"),
+		jen.Id("in").Op("<-").Chan().Add(sourceType),
+	).Params(
+		jen.Op("<-").Chan().Id(dto.Name),
+		jen.Op("<-").Chan().Error(),
+	).Block(
+		jen.Id("out").Op(":=").Make(jen.Chan().Id(dto.Name)),
+		jen.Id("errs").Op(":=").Make(jen.Chan().Error(), jen.Lit(1)),
+		jen.Line(),
+		jen.Go().Func().Params().Block(
+			jen.Defer().Close(jen.Id("out")),
+			jen.Defer().Close(jen.Id("errs")),
+			jen.Line(),
+			jen.For().Block(
+				jen.Select().Block(
+					cancelled,
+					jen.Case(jen.List(jen.Id("item"), jen.Id("ok")).Op(":=").Op("<-").Id("in")).Block(
+						jen.If(jen.Op("!").Id("ok")).Block(jen.Return()),
+						jen.Line(),
+						jen.Var().Id("dto").Id(dto.Name),
+						jen.If(jen.Err().Op(":=").Add(mapCall), jen.Err().Op("!=").Nil()).Block(
+							jen.Id("errs").Op("<-").Err(),
+							jen.Return(),
+						),
+						jen.Line(),
+						jen.Select().Block(
+							jen.Case(jen.Id("out").Op("<-").Id("dto")).Block(),
+							cancelled,
+						),
+					),
+				),
+			),
+		).Call(),
+		jen.Line(),
+		jen.Return(jen.Id("out"), jen.Id("errs")),
+	)
+	f.Line()
+}
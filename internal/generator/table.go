@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// mappingTable describes how every DTO field is mapped from a source, or back into it when reverse is set,
+// as aligned rows for the doc comment of the generated method
+func mappingTable(dto types.DTOMapping, source types.SourceStruct, cfg *config.Config, reverse bool) []string {
+	converterMap := buildConverterMap(cfg)
+	m := matcher.New(cfg, dto)
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	if reverse {
+		fmt.Fprintln(w, "DTO field\tDestination field\tMapping")
+	} else {
+		fmt.Fprintln(w, "DTO field\tSource field\tMapping")
+	}
+
+	for _, dtoField := range dto.Fields {
+		sourceFieldName := "-"
+		var mapping string
+		if dtoField.Ignore {
+			mapping = "skipped: ignored"
+		} else if name, _, exists := m.Resolve(dtoField, source); !exists {
+			mapping = "skipped: not found in source"
+			if reverse {
+				mapping = "skipped: not found in destination"
+			}
+		} else {
+			sourceFieldName = name
+			if reverse {
+				mapping = describeReverseMapping(dtoField, name, converterMap)
+			} else {
+				mapping = describeMapping(dtoField, converterMap)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", dtoField.Name, sourceFieldName, mapping)
+	}
+	w.Flush()
+
+	return strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+}
+
+// describeMapping describes how a DTO field is mapped from its source field, following buildFieldStatements
+func describeMapping(dtoField types.FieldInfo, converterMap map[string]config.ConverterDef) string {
+	switch {
+	case len(dtoField.TypeCases) > 0:
+		cases := make([]string, len(dtoField.TypeCases))
+		for i, typeCase := range dtoField.TypeCases {
+			cases[i] = typeCase.Source + ":" + typeCase.DTO
+		}
+		return "by type " + strings.Join(cases, ", ")
+	case dtoField.NestedDTO != "":
+		return "nested " + dtoField.NestedDTO
+	case dtoField.Redact != "":
+		return "redacted " + dtoField.Redact
+	case dtoField.ConverterTag != "":
+		if _, exists := converterMap[dtoField.ConverterTag]; !exists {
+			return "skipped: unknown converter " + dtoField.ConverterTag
+		}
+		return "converter " + dtoField.ConverterTag
+	}
+	return "direct"
+}
+
+// describeReverseMapping describes how a DTO field is mapped back into its destination field, following buildMapToMethodBody
+func describeReverseMapping(dtoField types.FieldInfo, targetFieldName string, converterMap map[string]config.ConverterDef) string {
+	switch {
+	case strings.HasSuffix(targetFieldName, "()"):
+		return "skipped: read through getter"
+	case len(dtoField.TypeCases) > 0:
+		return "skipped: interface field"
+	case dtoField.Redact != "":
+		return "skipped: redacted"
+	case dtoField.NestedDTO != "":
+		return "nested " + dtoField.NestedDTO
+	case dtoField.ConverterTag != "":
+		conv, ok := converterMap[dtoField.ConverterTag]
+		if !ok || conv.Inverter == "" {
+			return "skipped: converter " + dtoField.ConverterTag + " has no inverter"
+		}
+		return "inverter " + funcName(conv.Inverter)
+	}
+	return "direct"
+}
+
+// commentMappingTable appends a mapping table to the doc comment of a generated method
+func commentMappingTable(f *jen.File, rows []string) {
+	f.Comment("")
+	for _, row := range rows {
+		f.Comment("\t" + row)
+	}
+}
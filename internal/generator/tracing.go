@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"fmt"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+const (
+	otelPath      = "go.opentelemetry.io/otel"
+	otelCodesPath = "go.opentelemetry.io/otel/codes"
+)
+
+// ContextMethodName returns the name of the traced variant of a mapping method or function,
+// e.g. MapFromUserDBContext or MapUserDBToUserDTOContext
+func ContextMethodName(dtoName, sourceName, methodName string, calls callContext) string {
+	if calls.functions[dtoName] {
+		return MapFunctionName(sourceName, dtoName) + "Context"
+	}
+	return methodName + "Context"
+}
+
+// spanName returns the name of the span tracing a mapping, e.g. UserDTO.MapFromUserDB
+func spanName(dtoName, sourceName, methodName string, calls callContext) string {
+	if calls.functions[dtoName] {
+		return MapFunctionName(sourceName, dtoName)
+	}
+	return dtoName + "." + methodName
+}
+
+// tracedCall wraps a mapping call returning an error into a span started from ctx
+func tracedCall(name string, call jen.Code) *jen.Statement {
+	return jen.Id("traceMapping").Call(
+		jen.Id("ctx"),
+		jen.Lit(name),
+		jen.Func().Params().Error().Block(jen.Return(call)),
+	)
+}
+
+// GenerateContextMethod generates the context-taking variant of a MapFrom method or mapping function,
+// tracing the mapping in a span
+func GenerateContextMethod(
+	f *jen.File,
+	dto types.DTOMapping,
+	sourceName, methodName string,
+	cfg *config.Config,
+	importMap map[string]string,
+	calls callContext,
+) {
+	name := spanName(dto.Name, sourceName, methodName, calls)
+	contextName := ContextMethodName(dto.Name, sourceName, methodName, calls)
+	params := mapParams(cfg,
+		jen.Id("ctx").Qual("context", "Context"),
+		jen.Id("src").Op("*").Add(ParseTypeRefForJen(sourceName, importMap)),
+	)
+
+	f.Comment(fmt.Sprintf("%s is %s traced in a span started from ctx", contextName, name))
+
+	if calls.functions[dto.Name] {
+		implCall := jen.Id(mapFunctionImplName(sourceName, dto.Name)).Call(
+			append([]jen.Code{jen.Op("&").Id("d"), jen.Id("src")}, optionsArgs(cfg.Options)...)...,
+		)
+		f.Func().Id(contextName).Params(params...).Params(jen.Id(dto.Name), jen.Error()).Block(
+			jen.Var().Id("d").Id(dto.Name),
+			jen.If(jen.Err().Op(":=").Add(tracedCall(name, implCall)), jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Id(dto.Name).Values(), jen.Err()),
+			),
+			jen.Return(jen.Id("d"), jen.Nil()),
+		)
+		f.Line()
+		return
+	}
+
+	call := jen.Id("d").Dot(methodName).Call(append([]jen.Code{jen.Id("src")}, optionsArgs(cfg.Options)...)...)
+	f.Func().Params(
+		jen.Id("d").Op("*").Id(dto.Name),
+	).Id(contextName).Params(params...).Error().Block(
+		jen.Return(tracedCall(name, call)),
+	)
+	f.Line()
+}
+
+// GenerateTracingHelper generates the package tracer and the helper running a mapping in a span
+func GenerateTracingHelper(f *jen.File, pkgName string) {
+	f.Comment("mappingTracer starts the spans of traced mappings")
+	f.Var().Id("mappingTracer").Op("=").Qual(otelPath, "Tracer").Call(jen.Lit(pkgName))
+	f.Line()
+
+	f.Comment("traceMapping runs a mapping in a span, recording its error")
+	f.Func().Id("traceMapping").Params(
+		jen.Id("ctx").Qual("context", "Context"),
+		jen.Id("name").String(),
+		jen.Id("mapping").Func().Params().Error(),
+	).Error().Block(
+		jen.List(jen.Id("_"), jen.Id("span")).Op(":=").Id("mappingTracer").Dot("Start").Call(jen.Id("ctx"), jen.Id("name")),
+		jen.Defer().Id("span").Dot("End").Call(),
+		jen.Line(),
+		jen.If(jen.Err().Op(":=").Id("mapping").Call(), jen.Err().Op("!=").Nil()).Block(
+			jen.Id("span").Dot("RecordError").Call(jen.Err()),
+			jen.Id("span").Dot("SetStatus").Call(jen.Qual(otelCodesPath, "Error"), jen.Err().Dot("Error").Call()),
+			jen.Return(jen.Err()),
+		),
+		jen.Return(jen.Nil()),
+	)
+	f.Line()
+}
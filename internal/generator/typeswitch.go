@@ -0,0 +1,77 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// buildTypeSwitchMapping creates a type switch mapping an interface-typed source field
+// to the DTO configured for each concrete type
+func buildTypeSwitchMapping(
+	dtoField types.FieldInfo,
+	sourceFieldName string,
+	source types.SourceStruct,
+	importMap map[string]string,
+	calls callContext,
+) []jen.Code {
+	cases := []jen.Code{
+		jen.Case(jen.Nil()).Block(),
+	}
+
+	for _, typeCase := range dtoField.TypeCases {
+		srcIsPointer := strings.HasPrefix(typeCase.Source, "*")
+		dtoIsPointer := strings.HasPrefix(typeCase.DTO, "*")
+		srcTypeName := QualifySourceType(strings.TrimPrefix(typeCase.Source, "*"), source)
+		dtoTypeName := strings.TrimPrefix(typeCase.DTO, "*")
+		methodName := calls.mapFromName(dtoTypeName, ExtractTypeNameWithoutPackage(srcTypeName))
+
+		caseType := ParseTypeForJen(srcTypeName, importMap)
+		arg := jen.Op("&").Id("v")
+		if srcIsPointer {
+			caseType = jen.Op("*").Add(caseType)
+			arg = jen.Id("v")
+		}
+
+		nested := jen.Var().Id("nested").Id(dtoTypeName)
+		if dtoIsPointer {
+			nested = jen.Id("nested").Op(":=").Op("&").Id(dtoTypeName).Values()
+		}
+
+		cases = append(cases, jen.Case(caseType).Block(
+			nested,
+			jen.If(
+				jen.Err().Op(":=").Add(nestedCall(jen.Id("nested"), dtoIsPointer, dtoTypeName, methodName, arg, calls)),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(
+					jen.Lit(fmt.Sprintf("mapping nested field %s: %%w", dtoField.Name)),
+					jen.Err(),
+				)),
+			),
+			jen.Id("d").Dot(dtoField.Name).Op("=").Id("nested"),
+		))
+	}
+
+	cases = append(cases, jen.Default().Block(
+		jen.Return(jen.Qual("fmt", "Errorf").Call(
+			jen.Lit(fmt.Sprintf("mapping field %s: unsupported type %%T", dtoField.Name)),
+			jen.Id("v"),
+		)),
+	))
+
+	return []jen.Code{
+		jen.Switch(jen.Id("v").Op(":=").Add(sourceAccess(sourceFieldName)).Assert(jen.Type())).Block(cases...),
+		jen.Comment(fmt.Sprintf("%s: nil interface will result in zero value", dtoField.Name)),
+	}
+}
+
+// QualifySourceType qualifies a type name used inside a source struct with the source's package alias
+func QualifySourceType(typeName string, source types.SourceStruct) string {
+	if source.IsExternal && !strings.Contains(typeName, ".") {
+		return source.Alias + "." + typeName
+	}
+	return typeName
+}
@@ -0,0 +1,238 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// isZeroHelperName is the name of the generated generic zero-value check
+const isZeroHelperName = "automapperIsZero"
+
+// GenerateUpdateFromMethod generates an UpdateFrom method that only assigns non-zero source fields.
+// It reports whether the generated code relies on the generic zero-value helper.
+func GenerateUpdateFromMethod(
+	f *jen.File,
+	dto types.DTOMapping,
+	source types.SourceStruct,
+	sourceName, methodName string,
+	cfg *config.Config,
+	importMap map[string]string,
+	functions map[string]types.FunctionInfo,
+	sources map[string]types.SourceStruct,
+	calls callContext,
+) bool {
+	paramType := ParseTypeRefForJen(sourceName, importMap)
+
+	f.Comment(fmt.Sprintf("%s updates %s with the non-zero fields of %s", methodName, dto.Name, sourceName))
+	commentMappingTable(f, mappingTable(dto, source, cfg, false))
+
+	methodBody, usesHelper := buildUpdateMethodBody(dto, source, cfg, importMap, functions, sources, calls)
+	methodBody = withObserver(cfg, dto.Name, source.Name, methodBody)
+
+	f.Func().Params(
+		jen.Id("d").Op("*").Id(dto.Name),
+	).Id(methodName).Params(
+		jen.Id("src").Op("*").Add(paramType),
+	).Add(mappingResult(cfg)).Block(methodBody...)
+
+	f.Line()
+
+	return usesHelper
+}
+
+// GenerateIsZeroHelper generates the generic zero-value check used by update methods, a reflection
+// based one for Go versions where types holding interfaces don't satisfy comparable
+func GenerateIsZeroHelper(f *jen.File, cfg *config.Config) {
+	f.Comment(fmt.Sprintf("%s reports whether v holds the zero value of its type", isZeroHelperName))
+	if !cfg.GoAtLeast(config.GoComparable) {
+		f.Func().Id(isZeroHelperName).Params(
+			jen.Id("v").Interface(),
+		).Bool().Block(
+			jen.Return(jen.Id("v").Op("==").Nil().Op("||").Qual("reflect", "ValueOf").Call(jen.Id("v")).Dot("IsZero").Call()),
+		)
+		f.Line()
+		return
+	}
+	f.Func().Id(isZeroHelperName).Types(
+		jen.Id("T").Comparable(),
+	).Params(
+		jen.Id("v").Id("T"),
+	).Bool().Block(
+		jen.Var().Id("zero").Id("T"),
+		jen.Return(jen.Id("v").Op("==").Id("zero")),
+	)
+	f.Line()
+}
+
+// buildUpdateMethodBody constructs the update method body where each mapping is guarded by a non-zero check
+func buildUpdateMethodBody(
+	dto types.DTOMapping,
+	source types.SourceStruct,
+	cfg *config.Config,
+	importMap map[string]string,
+	functions map[string]types.FunctionInfo,
+	sources map[string]types.SourceStruct,
+	calls callContext,
+) ([]jen.Code, bool) {
+	statements := []jen.Code{
+		jen.If(jen.Id("src").Op("==").Nil()).Block(
+			jen.Return(nilSourceError(cfg, dto.Name, source.Name)),
+		),
+		jen.Line(),
+	}
+
+	converterMap := buildConverterMap(cfg)
+	m := matcher.New(cfg, dto)
+	usesHelper := false
+	// Nil source pointers leave fields unchanged rather than zeroing them
+	calls.nilPointers = true
+
+	for _, dtoField := range dto.Fields {
+		if dtoField.Ignore {
+			continue
+		}
+
+		sourceFieldName, sourceField, exists := m.Resolve(dtoField, source)
+		if !exists {
+			statements = append(statements,
+				jen.Comment(fmt.Sprintf("%s: not found in source, left unchanged", dtoField.Name)),
+			)
+			continue
+		}
+
+		fieldStatements := buildFieldStatements(dto.Name, dtoField, sourceField, sourceFieldName, source, converterMap, functions, importMap, calls)
+		for i, statement := range fieldStatements {
+			if _, ok := statement.(nilPointerNote); ok {
+				fieldStatements[i] = jen.Comment(fmt.Sprintf("// %s: nil pointer leaves the field unchanged", dtoField.Name))
+			}
+		}
+
+		// Nil source pointers are already skipped unless assigned directly to a pointer field
+		directPointer := dtoField.ConverterTag == "" && dtoField.NestedDTO == "" && strings.HasPrefix(dtoField.Type, "*")
+		if sourceField.IsPointer && !directPointer {
+			statements = append(statements, fieldStatements...)
+			continue
+		}
+
+		condition, helper, ok := buildNonZeroCondition(sourceField, sourceFieldName, source, sources)
+		if !ok {
+			statements = append(statements,
+				jen.Comment(fmt.Sprintf("%s: %s is not comparable, always assigned", dtoField.Name, sourceField.Type)),
+			)
+			statements = append(statements, fieldStatements...)
+			continue
+		}
+
+		usesHelper = usesHelper || helper
+		statements = append(statements, jen.If(condition).Block(fieldStatements...))
+	}
+
+	statements = append(statements, jen.Line(), jen.Return(jen.Nil()))
+	return statements, usesHelper
+}
+
+// buildNonZeroCondition creates a condition that holds when the source field is non-zero.
+// The second result reports whether the generic helper is used, the third whether a check is possible at all.
+func buildNonZeroCondition(
+	sourceField types.FieldTypeInfo,
+	sourceFieldName string,
+	source types.SourceStruct,
+	sources map[string]types.SourceStruct,
+) (jen.Code, bool, bool) {
+	return buildZeroCheck(sourceAccess(sourceFieldName), sourceField, source, sources, false)
+}
+
+// buildZeroCheck creates a condition that holds when the value of a field of the owner struct
+// is zero, or non-zero when zero is false. The results match buildNonZeroCondition.
+func buildZeroCheck(
+	value *jen.Statement,
+	field types.FieldTypeInfo,
+	owner types.SourceStruct,
+	sources map[string]types.SourceStruct,
+	zero bool,
+) (jen.Code, bool, bool) {
+	typeName := field.Type
+	compare := "!="
+	if zero {
+		compare = "=="
+	}
+
+	switch {
+	case field.IsPointer:
+		return value.Op(compare).Nil(), false, true
+	case field.IsSlice, strings.HasPrefix(typeName, "map["):
+		return jen.Len(value).Op(compare).Lit(0), false, true
+	case strings.HasPrefix(typeName, "interface{"), typeName == "any",
+		strings.HasPrefix(field.BaseIdentity.Underlying, "interface{"),
+		strings.HasPrefix(typeName, "func"), strings.Contains(typeName, "chan "):
+		return value.Op(compare).Nil(), false, true
+	case typeName == "string":
+		return value.Op(compare).Lit(""), false, true
+	case typeName == "bool":
+		if zero {
+			return jen.Op("!").Add(value), false, true
+		}
+		return value, false, true
+	case isNumericType(typeName):
+		return value.Op(compare).Lit(0), false, true
+	}
+
+	if !isComparableType(typeName, owner, sources, map[string]bool{}) {
+		return nil, false, false
+	}
+
+	if zero {
+		return jen.Id(isZeroHelperName).Call(value), true, true
+	}
+	return jen.Op("!").Id(isZeroHelperName).Call(value), true, true
+}
+
+// isNumericType reports whether a type name is a predeclared numeric type
+func isNumericType(typeName string) bool {
+	switch typeName {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"float32", "float64", "complex64", "complex128", "byte", "rune":
+		return true
+	}
+	return false
+}
+
+// isComparableType reports whether values of a type can be compared with ==.
+// Types that are not known source structs are assumed to be comparable.
+func isComparableType(
+	typeName string,
+	owner types.SourceStruct,
+	sources map[string]types.SourceStruct,
+	visited map[string]bool,
+) bool {
+	if strings.HasPrefix(typeName, "*") || strings.Contains(typeName, "chan ") {
+		return true
+	}
+	if strings.HasPrefix(typeName, "[]") || strings.HasPrefix(typeName, "map[") ||
+		strings.HasPrefix(typeName, "func") || typeName == "struct{...}" {
+		return false
+	}
+	if strings.HasPrefix(typeName, "[") {
+		return isComparableType(typeName[strings.Index(typeName, "]")+1:], owner, sources, visited)
+	}
+
+	key := QualifySourceType(typeName, owner)
+	nested, ok := sources[key]
+	if !ok || visited[key] {
+		return true
+	}
+	visited[key] = true
+
+	for _, field := range nested.Fields {
+		if !isComparableType(field.Type, nested, sources, visited) {
+			return false
+		}
+	}
+	return true
+}
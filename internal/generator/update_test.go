@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	goparser "go/parser"
+	"go/token"
+	gotypes "go/types"
+	"testing"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+)
+
+func TestUpdateFromCompilesBeforeComparableInterfaces(t *testing.T) {
+	models := `package update
+
+import "fmt"
+
+type Status struct {
+	Err  error
+	Code int
+}
+
+type Job struct {
+	Name   string
+	Err    error
+	Label  fmt.Stringer
+	Status Status
+}
+
+type JobDTO struct {
+	Name   string
+	Err    error
+	Label  fmt.Stringer
+	Status Status
+}
+`
+	fields := map[string]types.FieldTypeInfo{
+		"Name": {Type: "string", BaseType: "string",
+			BaseIdentity: types.TypeIdentity{Type: "string", Underlying: "string"}},
+		"Err": {Type: "error", BaseType: "error",
+			BaseIdentity: types.TypeIdentity{Type: "error", Underlying: "interface{Error() string}"}},
+		"Label": {Type: "fmt.Stringer", BaseType: "fmt.Stringer",
+			BaseIdentity: types.TypeIdentity{Type: "fmt.Stringer", Underlying: "interface{String() string}"}},
+		"Status": {Type: "Status", BaseType: "Status",
+			BaseIdentity: types.TypeIdentity{Type: "example.com/update.Status", Underlying: "struct{Err error; Code int}"}},
+	}
+	dto := types.DTOMapping{Name: "JobDTO", Sources: []string{"Job"}, PackageName: "update", Modes: []string{types.ModeUpdate}}
+	for _, name := range []string{"Name", "Err", "Label", "Status"} {
+		dto.Fields = append(dto.Fields, types.FieldInfo{Name: name, Type: fields[name].Type, BaseIdentity: fields[name].BaseIdentity})
+	}
+	sources := map[string]types.SourceStruct{"Job": {Name: "Job", Package: "update", Fields: fields}}
+
+	cfg, err := config.Parse([]byte(`{"goVersion": "1.19"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := Generate([]types.DTOMapping{dto}, sources, cfg, "update", nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var output bytes.Buffer
+	if err := generated[cfg.Output].Render(&output); err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	var syntax []*ast.File
+	for _, source := range []struct{ name, content string }{
+		{"models.go", models},
+		{cfg.Output, output.String()},
+	} {
+		file, err := goparser.ParseFile(fset, source.name, source.content, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		syntax = append(syntax, file)
+	}
+
+	typeChecker := gotypes.Config{GoVersion: "go1.19", Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := typeChecker.Check("example.com/update", fset, syntax, nil); err != nil {
+		t.Errorf("generated code does not compile with Go 1.19: %v\n%s", err, output.String())
+	}
+}
@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"github.com/dave/jennifer/jen"
+)
+
+// generateViaMethods generates a MapFrom method for every source of the intermediate DTO,
+// mapping into the intermediate DTO first and from there into the target DTO.
+// It returns the registry entries of the generated methods.
+func generateViaMethods(
+	f *jen.File,
+	dto types.DTOMapping,
+	dtos []types.DTOMapping,
+	sources map[string]types.SourceStruct,
+	dtoNames map[string]bool,
+	cfg *config.Config,
+	importMap map[string]string,
+	calls callContext,
+) ([]registryEntry, error) {
+	viaIdx := slices.IndexFunc(dtos, func(d types.DTOMapping) bool { return d.Name == dto.Via })
+	if viaIdx == -1 {
+		return nil, fmt.Errorf("intermediate DTO %s not found for DTO %s", dto.Via, dto.Name)
+	}
+	viaDTO := dtos[viaIdx]
+
+	secondHop := mapFromMethodName(dto, dto.Via, sources[dto.Via], dtoNames, calls)
+
+	var entries []registryEntry
+
+	for _, sourceName := range viaDTO.Sources {
+		source, ok := sources[sourceName]
+		if !ok {
+			return nil, fmt.Errorf("source struct %s not found for DTO %s", sourceName, viaDTO.Name)
+		}
+
+		firstHop := mapFromMethodName(viaDTO, sourceName, source, dtoNames, calls)
+		methodName := calls.mapFromName(dto.Name, ExtractTypeNameWithoutPackage(sourceName))
+
+		logger.Debug("  Generating %s.%s (via %s)", dto.Name, methodName, dto.Via)
+		GenerateViaMethod(f, dto, source, sourceName, methodName, firstHop, secondHop, cfg, importMap)
+		entries = append(entries, newMapFromEntry(dto.Name, sourceName, methodName, importMap, calls))
+	}
+
+	return entries, nil
+}
+
+// GenerateViaMethod generates a MapFrom method that composes the mapping into the intermediate DTO
+// with the mapping from the intermediate DTO into the target DTO, reported to MapObserver as a
+// mapping from the source
+func GenerateViaMethod(
+	f *jen.File,
+	dto types.DTOMapping,
+	source types.SourceStruct,
+	sourceName, methodName, firstHop, secondHop string,
+	cfg *config.Config,
+	importMap map[string]string,
+) {
+	paramType := ParseTypeRefForJen(sourceName, importMap)
+
+	f.Comment(fmt.Sprintf("%s maps from %s to %s via %s", methodName, sourceName, dto.Name, dto.Via))
+
+	methodBody := withObserver(cfg, dto.Name, source.Name, []jen.Code{
+		jen.Var().Id("via").Id(dto.Via),
+		jen.If(
+			jen.Err().Op(":=").Id("via").Dot(firstHop).Call(jen.Id("src")),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return(jen.Qual("fmt", "Errorf").Call(
+				jen.Lit(fmt.Sprintf("mapping via %s: %%w", dto.Via)),
+				jen.Err(),
+			)),
+		),
+		jen.Return(jen.Id("d").Dot(secondHop).Call(jen.Op("&").Id("via"))),
+	})
+
+	f.Func().Params(
+		jen.Id("d").Op("*").Id(dto.Name),
+	).Id(methodName).Params(
+		jen.Id("src").Op("*").Add(paramType),
+	).Add(mappingResult(cfg)).Block(methodBody...)
+
+	f.Line()
+}
@@ -0,0 +1,223 @@
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+)
+
+// Output formats of the graph
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+)
+
+// Node kinds of the graph
+const (
+	nodeDTO       = "dto"
+	nodeSource    = "source"
+	nodeConverter = "converter"
+)
+
+// Edge kinds of the graph
+const (
+	edgeMapsFrom  = "from"
+	edgeBoth      = "bidirectional"
+	edgeNested    = "nested"
+	edgeConverter = "converter"
+	edgeVia       = "via"
+)
+
+type node struct {
+	id    string
+	label string
+	kind  string
+}
+
+type edge struct {
+	from  string
+	to    string
+	label string
+	kind  string
+}
+
+// Graph holds the DTOs of a package with their sources, nested DTOs and converters
+type Graph struct {
+	nodes []node
+	edges []edge
+	seen  map[string]bool
+}
+
+// Build collects the nodes and edges of the mapping graph of a package: sources point to the DTOs
+// mapped from them, DTOs point to their nested DTOs and to the converters their fields use
+func Build(dtos []types.DTOMapping, cfg *config.Config) *Graph {
+	g := &Graph{seen: make(map[string]bool)}
+
+	dtoNames := make(map[string]bool)
+	for _, dto := range dtos {
+		dtoNames[dto.Name] = true
+		g.addNode(node{id: "dto:" + dto.Name, label: dto.Name, kind: nodeDTO})
+	}
+	// A DTO mapped from another DTO of the package points to its node
+	sourceID := func(name string) string {
+		if dtoNames[name] {
+			return "dto:" + name
+		}
+		id := "source:" + name
+		g.addNode(node{id: id, label: name, kind: nodeSource})
+		return id
+	}
+
+	converters := make(map[string]config.ConverterDef)
+	for _, conv := range cfg.Converters {
+		converters[conv.Name] = conv
+	}
+
+	for _, dto := range dtos {
+		dtoID := "dto:" + dto.Name
+
+		for _, sourceName := range dto.Sources {
+			kind, label := edgeMapsFrom, ""
+			if dto.HasMode(types.ModeBidirectional) {
+				kind = edgeBoth
+			}
+			if sourceName == dto.Via {
+				kind, label = edgeVia, "via"
+			}
+			g.edges = append(g.edges, edge{from: sourceID(sourceName), to: dtoID, label: label, kind: kind})
+		}
+
+		for _, field := range dto.Fields {
+			if field.Ignore {
+				continue
+			}
+
+			var nested []string
+			if field.NestedDTO != "" {
+				nested = append(nested, field.NestedDTO)
+			}
+			for _, typeCase := range field.TypeCases {
+				// Cases may map to DTO pointers, e.g. *AdminDB:*AdminDTO
+				name := strings.TrimPrefix(typeCase.DTO, "*")
+				if !slices.Contains(nested, name) {
+					nested = append(nested, name)
+				}
+			}
+			for _, name := range nested {
+				g.edges = append(g.edges, edge{from: dtoID, to: sourceID(name), label: field.Name, kind: edgeNested})
+			}
+
+			if field.ConverterTag != "" && field.NestedDTO == "" {
+				label := field.ConverterTag
+				if conv, ok := converters[field.ConverterTag]; ok && conv.Function != conv.Name {
+					label += "\n" + conv.Function
+				}
+				convID := "converter:" + field.ConverterTag
+				g.addNode(node{id: convID, label: label, kind: nodeConverter})
+				g.edges = append(g.edges, edge{from: dtoID, to: convID, label: field.Name, kind: edgeConverter})
+			}
+		}
+	}
+
+	return g
+}
+
+// addNode adds a node once
+func (g *Graph) addNode(n node) {
+	if !g.seen[n.id] {
+		g.seen[n.id] = true
+		g.nodes = append(g.nodes, n)
+	}
+}
+
+// Render formats the graph as DOT or Mermaid
+func (g *Graph) Render(format string) (string, error) {
+	switch format {
+	case FormatDOT:
+		return g.dot(), nil
+	case FormatMermaid:
+		return g.mermaid(), nil
+	}
+	return "", fmt.Errorf("unknown graph format %q, expected %s or %s", format, FormatDOT, FormatMermaid)
+}
+
+// dot formats the graph for Graphviz
+func (g *Graph) dot() string {
+	shapes := map[string]string{
+		nodeDTO:       "shape=box, style=bold",
+		nodeSource:    "shape=ellipse",
+		nodeConverter: "shape=diamond, style=dashed",
+	}
+	styles := map[string]string{
+		edgeMapsFrom:  "",
+		edgeBoth:      ", dir=both",
+		edgeNested:    ", style=dashed",
+		edgeConverter: ", style=dotted, arrowhead=none",
+		edgeVia:       ", style=bold",
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph automapper {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, n := range g.nodes {
+		fmt.Fprintf(&b, "  %q [label=%q, %s];\n", n.id, n.label, shapes[n.kind])
+	}
+	for _, e := range g.edges {
+		attrs := styles[e.kind]
+		if e.label != "" {
+			attrs = fmt.Sprintf(", label=%q", e.label) + attrs
+		}
+		if attrs == "" {
+			fmt.Fprintf(&b, "  %q -> %q;\n", e.from, e.to)
+			continue
+		}
+		fmt.Fprintf(&b, "  %q -> %q [%s];\n", e.from, e.to, strings.TrimPrefix(attrs, ", "))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// mermaid formats the graph as a Mermaid flowchart
+func (g *Graph) mermaid() string {
+	shapes := map[string][2]string{
+		nodeDTO:       {"[", "]"},
+		nodeSource:    {"([", "])"},
+		nodeConverter: {"{{", "}}"},
+	}
+	arrows := map[string]string{
+		edgeMapsFrom:  "-->",
+		edgeBoth:      "<-->",
+		edgeNested:    "-.->",
+		edgeConverter: "-.-",
+		edgeVia:       "==>",
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, n := range g.nodes {
+		shape := shapes[n.kind]
+		label := strings.ReplaceAll(n.label, "\n", "<br/>")
+		fmt.Fprintf(&b, "  %s%s\"%s\"%s\n", mermaidID(n.id), shape[0], label, shape[1])
+	}
+	for _, e := range g.edges {
+		arrow := arrows[e.kind]
+		if e.label != "" {
+			arrow += "|" + e.label + "|"
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", mermaidID(e.from), arrow, mermaidID(e.to))
+	}
+	return b.String()
+}
+
+// mermaidID turns a node id into a Mermaid identifier, which may only hold letters, digits and underscores
+func mermaidID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, id)
+}
@@ -62,11 +62,21 @@ func SetVerbose(verbose bool) {
 	}
 }
 
+// SetOutput redirects the log messages, errors are always written to the standard error
+func SetOutput(writer io.Writer) {
+	defaultLogger.writer = writer
+}
+
 // SetColors enables or disables color output
 func SetColors(enabled bool) {
 	defaultLogger.colors = enabled
 }
 
+// StdoutSupportsColors reports whether colored text can be written to the standard output
+func StdoutSupportsColors() bool {
+	return detectColorSupport(os.Stdout)
+}
+
 // detectColorSupport checks if the terminal supports colors
 func detectColorSupport(writer io.Writer) bool {
 	// Check for NO_COLOR environment variable (standard: https://no-color.org/)
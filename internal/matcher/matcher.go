@@ -0,0 +1,156 @@
+package matcher
+
+import (
+	"sort"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/naming"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+)
+
+// Matcher resolves which source field a DTO field is mapped from
+type Matcher struct {
+	fieldMatch  string
+	nameSource  string
+	transform   string
+	sourceStrip config.NameStripping
+	dtoStrip    config.NameStripping
+	customNames map[string]string
+	initialisms naming.Initialisms
+}
+
+// New creates a matcher for the mappings of a DTO.
+// A transform annotated on the DTO overrides the configured one.
+func New(cfg *config.Config, dto types.DTOMapping) *Matcher {
+	transform := cfg.FieldNameTransform
+	if dto.Transform != "" {
+		transform = dto.Transform
+	}
+
+	return &Matcher{
+		fieldMatch:  cfg.FieldMatch,
+		nameSource:  cfg.FieldNameSource,
+		transform:   transform,
+		sourceStrip: cfg.NameTransforms.Source,
+		dtoStrip:    cfg.NameTransforms.DTO,
+		customNames: cfg.CustomNames(transform),
+		initialisms: naming.NewInitialisms(cfg.Initialisms),
+	}
+}
+
+// Transform returns the field name transform of the DTO
+func (m *Matcher) Transform() string {
+	return m.transform
+}
+
+// TransformInput returns the name of a DTO field the transform rewrites when no source field
+// matches it exactly
+func (m *Matcher) TransformInput(field types.FieldInfo) string {
+	return strip(m.LookupName(field), m.dtoStrip)
+}
+
+// Resolve returns the source field a DTO field maps from.
+// It reports false when no field or more than one field matches.
+func (m *Matcher) Resolve(field types.FieldInfo, source types.SourceStruct) (string, types.FieldTypeInfo, bool) {
+	candidates := m.Candidates(field, source)
+	if len(candidates) != 1 {
+		return m.LookupName(field), types.FieldTypeInfo{}, false
+	}
+
+	if getter, ok := GetterName(candidates[0]); ok {
+		return candidates[0], source.Getters[getter], true
+	}
+	return candidates[0], source.Fields[candidates[0]], true
+}
+
+// GetterName reports whether a source field name refers to a getter method, e.g. Password(),
+// and returns the method name
+func GetterName(sourceFieldName string) (string, bool) {
+	return strings.CutSuffix(sourceFieldName, "()")
+}
+
+// LookupName returns the name used to look up the source field of a DTO field
+func (m *Matcher) LookupName(field types.FieldInfo) string {
+	if field.FieldTag != "" {
+		return field.FieldTag
+	}
+
+	if m.nameSource == config.FieldNameSourceJSON && field.JSONName != "" {
+		return field.JSONName
+	}
+
+	return field.Name
+}
+
+// Candidates returns the names of all source fields matching a DTO field.
+// An exact match always wins over looser matches.
+func (m *Matcher) Candidates(field types.FieldInfo, source types.SourceStruct) []string {
+	name := m.LookupName(field)
+	if getter, ok := GetterName(name); ok {
+		if _, exists := source.Getters[getter]; exists {
+			return []string{name}
+		}
+		return nil
+	}
+
+	if _, exists := source.Fields[name]; exists {
+		return []string{name}
+	}
+
+	// Explicit field tags are only matched exactly
+	if field.FieldTag != "" {
+		return nil
+	}
+
+	key := m.normalize(m.transformName(m.TransformInput(field)))
+	candidates := []string{}
+	for sourceFieldName := range source.Fields {
+		if m.normalize(strip(sourceFieldName, m.sourceStrip)) == key {
+			candidates = append(candidates, sourceFieldName)
+		}
+	}
+
+	sort.Strings(candidates)
+	return candidates
+}
+
+// normalize reduces a field name to the form compared in the configured match mode
+func (m *Matcher) normalize(name string) string {
+	switch m.fieldMatch {
+	case config.FieldMatchInsensitive:
+		return strings.ToLower(name)
+	case config.FieldMatchFuzzy:
+		return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	}
+	return name
+}
+
+// strip removes the first matching prefix and suffix from a name, never leaving it empty
+func strip(name string, rules config.NameStripping) string {
+	for _, prefix := range rules.StripPrefixes {
+		if trimmed, ok := strings.CutPrefix(name, prefix); ok && trimmed != "" {
+			name = trimmed
+			break
+		}
+	}
+	for _, suffix := range rules.StripSuffixes {
+		if trimmed, ok := strings.CutSuffix(name, suffix); ok && trimmed != "" {
+			name = trimmed
+			break
+		}
+	}
+	return name
+}
+
+// transformName applies the field name transform to a name looked up in the source struct.
+// Custom transforms give the names resolved when the package was parsed.
+func (m *Matcher) transformName(name string) string {
+	if config.IsCustomTransform(m.transform) {
+		if rewritten, ok := m.customNames[name]; ok {
+			return rewritten
+		}
+		return name
+	}
+	return naming.Apply(name, m.transform, m.initialisms)
+}
@@ -0,0 +1,116 @@
+package naming
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+)
+
+// customProgram calls the function of a custom strategy on the names read from the standard input
+const customProgram = `package main
+
+import (
+	"encoding/json"
+	"os"
+
+	strategy %q
+)
+
+func main() {
+	var names []string
+	if err := json.NewDecoder(os.Stdin).Decode(&names); err != nil {
+		panic(err)
+	}
+	rewritten := make(map[string]string, len(names))
+	for _, name := range names {
+		rewritten[name] = strategy.%s(name)
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(rewritten); err != nil {
+		panic(err)
+	}
+}
+`
+
+// Custom rewrites names with the Go function of a custom strategy: func:Name for a function
+// of the package in dir or func:import/path.Name for another package. The function takes and
+// returns a string; it's called from a temporary program run with the go tool inside the module,
+// so its package must build and can't be a main package.
+func Custom(dir, strategy string, names []string) (map[string]string, error) {
+	function := strings.TrimPrefix(strategy, config.TransformFuncPrefix)
+	importPath, funcName := "", function
+	if i := strings.LastIndex(function, "."); i >= 0 {
+		importPath, funcName = function[:i], function[i+1:]
+	}
+
+	if importPath == "" {
+		output, err := goCommand(dir, "list", "-f", "{{.ImportPath}} {{.Name}}", ".")
+		if err != nil {
+			return nil, fmt.Errorf("resolving the package of %s: %w", strategy, err)
+		}
+		path, name, _ := strings.Cut(strings.TrimSpace(output), " ")
+		if name == "main" {
+			return nil, fmt.Errorf("%s: functions of a main package can't be called, move %s to another package",
+				strategy, funcName)
+		}
+		importPath = path
+	}
+
+	goMod, err := goCommand(dir, "env", "GOMOD")
+	goMod = strings.TrimSpace(goMod)
+	if err != nil || goMod == "" || goMod == os.DevNull {
+		return nil, fmt.Errorf("%s: custom naming strategies require a Go module", strategy)
+	}
+
+	// The program lives in the module to import its packages, in a directory ./... patterns skip
+	programDir, err := os.MkdirTemp(filepath.Dir(goMod), ".automapper-naming-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(programDir)
+
+	program := fmt.Sprintf(customProgram, importPath, funcName)
+	if err := os.WriteFile(filepath.Join(programDir, "main.go"), []byte(program), 0o644); err != nil {
+		return nil, err
+	}
+
+	input, err := json.Marshal(names)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Verbose("Running naming strategy %s on %d names", strategy, len(names))
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = programDir
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("running naming strategy %s: %w\n%s", strategy, err, strings.TrimSpace(stderr.String()))
+	}
+
+	rewritten := make(map[string]string)
+	if err := json.Unmarshal(stdout.Bytes(), &rewritten); err != nil {
+		return nil, fmt.Errorf("reading the names of strategy %s: %w", strategy, err)
+	}
+	return rewritten, nil
+}
+
+// goCommand runs a go subcommand in a directory and returns its standard output
+func goCommand(dir string, args ...string) (string, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
@@ -0,0 +1,144 @@
+package naming
+
+import (
+	"strings"
+	"text/template"
+	"unicode"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+)
+
+// commonInitialisms are the words Go names spell in capitals, upper-cased whole by the
+// snake_to_camel and pascal strategies
+var commonInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS", "ID",
+	"IP", "JSON", "JWT", "QPS", "RAM", "RPC", "SKU", "SLA", "SMTP", "SQL", "SSH", "TCP", "TLS",
+	"TTL", "UDP", "UI", "UID", "URI", "URL", "UTF8", "UUID", "VM", "XML", "XMPP", "XSRF", "XSS",
+}
+
+// Initialisms spells words as initialisms, e.g. user_id giving UserID. Spellings are keyed by
+// the upper-cased word.
+type Initialisms map[string]string
+
+// NewInitialisms returns the common initialisms of Go names with custom ones added, spelled
+// as given, e.g. OAuth
+func NewInitialisms(custom []string) Initialisms {
+	initialisms := make(Initialisms, len(commonInitialisms)+len(custom))
+	for _, word := range commonInitialisms {
+		initialisms[word] = word
+	}
+	for _, word := range custom {
+		initialisms[strings.ToUpper(word)] = word
+	}
+	return initialisms
+}
+
+// capitalize upper-cases the first letter of a word, or spells an initialism
+func (i Initialisms) capitalize(word string) string {
+	if spelling, ok := i[strings.ToUpper(word)]; ok {
+		return spelling
+	}
+	return capitalize(word)
+}
+
+// Apply rewrites a name with a built-in naming strategy. Names are left as they are for
+// none, identity and custom strategies, which are looked up in a table instead.
+func Apply(name, strategy string, initialisms Initialisms) string {
+	switch strategy {
+	case config.TransformSnakeToCamel:
+		return snakeToCamel(name, initialisms)
+	case config.TransformCamelToSnake:
+		return join(Words(name), "_", strings.ToLower)
+	case config.TransformPascal:
+		return join(Words(name), "", initialisms.capitalize)
+	case config.TransformKebab:
+		return join(Words(name), "-", strings.ToLower)
+	case config.TransformScreamingSnake:
+		return join(Words(name), "_", strings.ToUpper)
+	}
+	return name
+}
+
+// TemplateFuncs returns the implementations of the outputPattern functions, e.g. snake giving
+// user_dto for UserDTO
+func TemplateFuncs(initialisms Initialisms) template.FuncMap {
+	apply := func(strategy string) func(string) string {
+		return func(name string) string { return Apply(name, strategy, initialisms) }
+	}
+	return template.FuncMap{
+		"snake":     apply(config.TransformCamelToSnake),
+		"kebab":     apply(config.TransformKebab),
+		"pascal":    apply(config.TransformPascal),
+		"screaming": apply(config.TransformScreamingSnake),
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+	}
+}
+
+// snakeToCamel turns created_at into CreatedAt, leaving the rest of every part as is but for
+// initialisms: user_id gives UserID
+func snakeToCamel(name string, initialisms Initialisms) string {
+	var sb strings.Builder
+	for part := range strings.SplitSeq(name, "_") {
+		if part == "" {
+			continue
+		}
+		if spelling, ok := initialisms[strings.ToUpper(part)]; ok {
+			sb.WriteString(spelling)
+			continue
+		}
+		sb.WriteString(strings.ToUpper(part[:1]))
+		sb.WriteString(part[1:])
+	}
+	return sb.String()
+}
+
+// Words splits a name into its words at underscores, dashes, spaces and case changes,
+// keeping initialisms together: UserID, user_id and user-id all give user and id
+// in their original case, HTTPServer gives HTTP and Server.
+func Words(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := -1
+	for i, r := range runes {
+		if r == '_' || r == '-' || unicode.IsSpace(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+
+		prev := runes[i-1]
+		boundary := unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)) ||
+			// The last capital of an initialism starts the next word: HTTPServer
+			unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if boundary {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// join joins words with a separator after rewriting each of them
+func join(words []string, separator string, rewrite func(string) string) string {
+	for i, word := range words {
+		words[i] = rewrite(word)
+	}
+	return strings.Join(words, separator)
+}
+
+// capitalize upper-cases the first letter of a word, keeping initialisms such as ID intact
+func capitalize(word string) string {
+	runes := []rune(word)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
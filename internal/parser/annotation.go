@@ -1,33 +1,211 @@
 package parser
 
 import (
+	"fmt"
 	"go/ast"
+	"go/token"
 	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
 )
 
 // ExtractAnnotation extracts the automapper annotation from comments
 func ExtractAnnotation(doc *ast.CommentGroup) string {
+	return ExtractDirective(doc, "from")
+}
+
+// ExtractDirective extracts the value of an automapper:<key>= directive from comments
+func ExtractDirective(doc *ast.CommentGroup, key string) string {
 	if doc == nil {
 		return ""
 	}
 
 	for _, comment := range doc.List {
-		text := comment.Text
-		text = strings.TrimSpace(text)
+		if directiveKey, value, ok := parseDirective(comment.Text); ok && directiveKey == key {
+			return value
+		}
+	}
+	return ""
+}
 
-		if strings.HasPrefix(text, "//") {
-			text = strings.TrimSpace(text[2:])
-		} else if strings.HasPrefix(text, "/*") && strings.HasSuffix(text, "*/") {
-			text = strings.TrimSpace(text[2 : len(text)-2])
+// directiveText returns the text of a comment after automapper:, reporting whether the comment
+// looks like a directive at all. Spaces around the colon are tolerated.
+func directiveText(comment string) (string, bool) {
+	text := strings.TrimSpace(comment)
+	if strings.HasPrefix(text, "//") {
+		text = strings.TrimSpace(text[2:])
+	} else if strings.HasPrefix(text, "/*") && strings.HasSuffix(text, "*/") {
+		text = strings.TrimSpace(text[2 : len(text)-2])
+	}
+
+	text, ok := strings.CutPrefix(text, "automapper")
+	if !ok {
+		return "", false
+	}
+	return strings.CutPrefix(strings.TrimSpace(text), ":")
+}
+
+// parseDirective splits a comment holding an automapper:<key>=<value> directive, tolerating spaces
+// around the colon and the equals sign, e.g. // automapper: from = db.UserDB
+func parseDirective(comment string) (string, string, bool) {
+	text, ok := directiveText(comment)
+	if !ok {
+		return "", "", false
+	}
+	key, value, ok := strings.Cut(text, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" || strings.ContainsAny(key, " \t") {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(value), true
+}
+
+// hasDirective reports whether a comment group holds something looking like an automapper directive
+func hasDirective(doc *ast.CommentGroup) bool {
+	for _, comment := range doc.List {
+		if _, ok := directiveText(comment.Text); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// typeAnnotations holds the comments annotating the type declarations of a file: the doc of a type,
+// the doc of a declaration of a single type, or a comment separated from either by blank lines only
+type typeAnnotations struct {
+	docs    map[*ast.TypeSpec][]*ast.CommentGroup
+	ignored []ignoredAnnotation
+}
+
+// ignoredAnnotation is a comment holding automapper directives that annotates no type
+type ignoredAnnotation struct {
+	Position token.Position
+	Reason   string
+}
+
+// newTypeAnnotations resolves the annotations of the type declarations of a file, recording the
+// comments holding directives that annotate none of them
+func newTypeAnnotations(fset *token.FileSet, file *ast.File) *typeAnnotations {
+	a := &typeAnnotations{docs: make(map[*ast.TypeSpec][]*ast.CommentGroup)}
+	claimed := make(map[*ast.CommentGroup]bool)
+	claim := func(spec *ast.TypeSpec, doc *ast.CommentGroup) {
+		if doc == nil {
+			return
+		}
+		a.docs[spec] = append(a.docs[spec], doc)
+		claimed[doc] = true
+		for _, comment := range doc.List {
+			if _, looksLike := directiveText(comment.Text); looksLike {
+				if _, _, ok := parseDirective(comment.Text); !ok {
+					a.ignored = append(a.ignored, ignoredAnnotation{
+						Position: fset.Position(comment.Pos()),
+						Reason:   "it isn't an automapper:key=value directive",
+					})
+				}
+			}
 		}
+	}
 
-		if after, ok := strings.CutPrefix(text, "automapper:from="); ok {
-			return strings.TrimSpace(after)
+	// detached returns the last comment group holding directives between two positions
+	detached := func(from, to token.Pos) *ast.CommentGroup {
+		var last *ast.CommentGroup
+		for _, group := range file.Comments {
+			if group.Pos() > from && group.End() < to {
+				last = group
+			}
+		}
+		if last == nil || !hasDirective(last) {
+			return nil
+		}
+		return last
+	}
+
+	prevEnd := file.Name.End()
+	for _, decl := range file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Doc != nil {
+			// Converters are annotated on their functions
+			claimed[funcDecl.Doc] = true
+		}
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			prevEnd = decl.End()
+			continue
+		}
+
+		declStart := genDecl.Pos()
+		if genDecl.Doc != nil {
+			declStart = genDecl.Doc.Pos()
+		}
+		declDocs := []*ast.CommentGroup{genDecl.Doc, detached(prevEnd, declStart)}
+
+		specEnd := genDecl.Lparen
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			claim(typeSpec, typeSpec.Doc)
+			// Fields are annotated by the comments above or beside them
+			ast.Inspect(typeSpec.Type, func(node ast.Node) bool {
+				if field, ok := node.(*ast.Field); ok {
+					claimed[field.Doc], claimed[field.Comment] = true, true
+				}
+				return true
+			})
+			if genDecl.Lparen.IsValid() {
+				specStart := typeSpec.Pos()
+				if typeSpec.Doc != nil {
+					specStart = typeSpec.Doc.Pos()
+				}
+				claim(typeSpec, detached(specEnd, specStart))
+				specEnd = typeSpec.End()
+			}
+			if len(genDecl.Specs) == 1 {
+				for _, doc := range declDocs {
+					claim(typeSpec, doc)
+				}
+			}
+		}
+
+		if len(genDecl.Specs) > 1 {
+			for _, doc := range declDocs {
+				if doc != nil && hasDirective(doc) {
+					claimed[doc] = true
+					a.ignored = append(a.ignored, ignoredAnnotation{
+						Position: fset.Position(doc.Pos()),
+						Reason:   fmt.Sprintf("it annotates a group of %d types, annotate each type inside the group", len(genDecl.Specs)),
+					})
+				}
+			}
+		}
+		prevEnd = genDecl.End()
+	}
+
+	for _, group := range file.Comments {
+		if !claimed[group] && hasDirective(group) {
+			a.ignored = append(a.ignored, ignoredAnnotation{
+				Position: fset.Position(group.Pos()),
+				Reason:   "it doesn't precede a type declaration",
+			})
+		}
+	}
+	return a
+}
+
+// directive looks up a directive on the type first, then on its declaration and detached comments
+func (a *typeAnnotations) directive(typeSpec *ast.TypeSpec, key string) string {
+	for _, doc := range a.docs[typeSpec] {
+		if value := ExtractDirective(doc, key); value != "" {
+			return value
 		}
 	}
 	return ""
 }
 
+// warnIgnored warns about the comments holding directives that annotate no type
+func (a *typeAnnotations) warnIgnored() {
+	for _, ignored := range a.ignored {
+		logger.Warning("%s: automapper annotation ignored, %s", ignored.Position, ignored.Reason)
+	}
+}
+
 // ParseSourceList parses a comma-separated list of source types
 func ParseSourceList(annotation string) []string {
 	parts := strings.Split(annotation, ",")
@@ -40,3 +218,15 @@ func ParseSourceList(annotation string) []string {
 	}
 	return sources
 }
+
+// ParsePriorityList parses a '>'-separated list of source types ordered by priority
+func ParsePriorityList(annotation string) []string {
+	sources := []string{}
+	for part := range strings.SplitSeq(annotation, ">") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			sources = append(sources, part)
+		}
+	}
+	return sources
+}
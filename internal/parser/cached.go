@@ -0,0 +1,254 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/cache"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"golang.org/x/tools/go/packages"
+)
+
+// packageEntry is a parsed main package as stored in the cache
+type packageEntry struct {
+	DTOs      []types.DTOMapping
+	Sources   map[string]types.SourceStruct
+	Functions map[string]types.FunctionInfo
+	Name      string
+	Positions map[string]types.Position // by DTO name and by DTO.Field, positions aren't part of the JSON of DTOs
+	Promoted  map[string]string         // files hash of the packages fields are promoted from, by import path
+}
+
+// externalEntry is a parsed external package as stored in the cache
+type externalEntry struct {
+	Sources  map[string]types.SourceStruct
+	Promoted map[string]string // files hash of the packages fields are promoted from, by import path
+}
+
+// loadExternalSources parses an external package, or reuses the structs parsed from the same files by an earlier run
+func loadExternalSources(
+	pkgPath string, extPkg config.ExternalPackage, alias string, cfg *config.Config, c *cache.Cache,
+) (map[string]types.SourceStruct, error) {
+	key := externalCacheKey(pkgPath, extPkg, alias, cfg, c)
+	dir := LocalPackageDir(pkgPath, extPkg)
+
+	var entry externalEntry
+	if c.Get(key, &entry) && !promotedChanged(dir, entry.Promoted, cfg) {
+		logger.Verbose("  Loaded from cache")
+		return entry.Sources, nil
+	}
+
+	extSources, err := parseExternalPackage(pkgPath, extPkg, alias, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		promoted, err := hashPromoted(dir, extSources, cfg)
+		if err != nil {
+			logger.Debug("Hashing packages embedded by %s: %v", extPkg.ImportPath, err)
+			return extSources, nil
+		}
+		c.Put(key, externalEntry{Sources: extSources, Promoted: promoted})
+	}
+	return extSources, nil
+}
+
+// parseMainPackage parses the package of the DTOs, or reuses the result of an earlier run on the same files
+func parseMainPackage(
+	pkgPath string, cfg *config.Config, targets map[string][]string, c *cache.Cache,
+) (
+	[]types.DTOMapping,
+	map[string]types.SourceStruct,
+	map[string]types.FunctionInfo,
+	string,
+	error,
+) {
+	key, indexKey := "", ""
+	var files []string
+	if c != nil {
+		var filesHash string
+		var err error
+		files, err = packageFiles(pkgPath, ".", cfg, cfg.TestFiles, true)
+		if err == nil {
+			filesHash, err = cache.HashFiles(files)
+		}
+		targetsJSON, _ := json.Marshal(targets)
+		mappingsJSON, _ := json.Marshal(cfg.Mappings)
+		absPath, _ := filepath.Abs(pkgPath)
+		if err != nil {
+			logger.Debug("Hashing files of %s: %v", pkgPath, err)
+		} else {
+			settings := []string{absPath, cfg.Output, string(targetsJSON), string(mappingsJSON),
+				strconv.FormatBool(cfg.IncludeGenerated), strconv.FormatBool(cfg.TestFiles)}
+			key = c.Key(append([]string{"package", filesHash}, settings...)...)
+			indexKey = c.Key(append([]string{"package-files"}, settings...)...)
+		}
+	}
+
+	var entry packageEntry
+	found := c.Get(key, &entry)
+	if found && !promotedChanged(pkgPath, entry.Promoted, cfg) {
+		logger.Verbose("Main package loaded from cache")
+		entry.restorePositions()
+		return entry.DTOs, entry.Sources, entry.Functions, entry.Name, nil
+	}
+
+	// Files changed only inside function bodies parse as they did, unless the entry is stale anyway
+	var index packageIndex
+	if !found && c.Get(indexKey, &index) {
+		if changed, ok := index.update(files); ok && c.Get(index.Entry, &entry) && !promotedChanged(pkgPath, entry.Promoted, cfg) {
+			logger.Verbose("Main package loaded from cache, %s only changed inside function bodies", strings.Join(changed, ", "))
+			c.Put(key, entry)
+			index.Entry = key
+			c.Put(indexKey, index)
+			entry.restorePositions()
+			return entry.DTOs, entry.Sources, entry.Functions, entry.Name, nil
+		}
+	}
+
+	dtos, sources, functions, pkgName, err := parsePackageWithGoPackages(pkgPath, "", "", false, cfg, targets)
+	if err != nil {
+		return nil, nil, nil, "", err
+	}
+	if key == "" {
+		return dtos, sources, functions, pkgName, nil
+	}
+	promoted, err := hashPromoted(pkgPath, sources, cfg)
+	if err != nil {
+		logger.Debug("Hashing packages embedded by %s: %v", pkgPath, err)
+		return dtos, sources, functions, pkgName, nil
+	}
+	entry = newPackageEntry(dtos, sources, functions, pkgName)
+	entry.Promoted = promoted
+	c.Put(key, entry)
+	if indexKey != "" {
+		if index, err := newPackageIndex(key, files); err != nil {
+			logger.Debug("Indexing files of %s: %v", pkgPath, err)
+		} else {
+			c.Put(indexKey, index)
+		}
+	}
+	return dtos, sources, functions, pkgName, nil
+}
+
+// externalCacheKey identifies the parsed structs of an external package by the files they are parsed from
+func externalCacheKey(pkgPath string, extPkg config.ExternalPackage, alias string, cfg *config.Config, c *cache.Cache) string {
+	if c == nil {
+		return ""
+	}
+
+	dir, pattern := LocalPackageDir(pkgPath, extPkg), "."
+	if dir == "" {
+		pattern = extPkg.ImportPath
+	}
+
+	filesHash, err := hashPackageFiles(dir, pattern, cfg, false, true)
+	if err != nil {
+		logger.Debug("Hashing files of %s: %v", extPkg.ImportPath, err)
+		return ""
+	}
+	filtersJSON, _ := json.Marshal([][]string{extPkg.Include, extPkg.Exclude})
+	return c.Key("external", extPkg.ImportPath, dir, alias, cfg.Output, filesHash, string(filtersJSON),
+		strconv.FormatBool(cfg.IncludeGenerated))
+}
+
+// hashPromoted hashes the files of the other packages declaring the embedded structs the fields of
+// sources are promoted from, which the cache keys of a package don't cover, by import path
+func hashPromoted(dir string, sources map[string]types.SourceStruct, cfg *config.Config) (map[string]string, error) {
+	var promoted map[string]string
+	for _, source := range sources {
+		for _, importPath := range source.PromotedFrom {
+			if _, ok := promoted[importPath]; ok {
+				continue
+			}
+			filesHash, err := hashPackageFiles(dir, importPath, cfg, false, false)
+			if err != nil {
+				return nil, err
+			}
+			if promoted == nil {
+				promoted = make(map[string]string)
+			}
+			promoted[importPath] = filesHash
+		}
+	}
+	return promoted, nil
+}
+
+// promotedChanged reports whether the files of a package fields are promoted from changed since
+// the entry hashing them was stored, the fields it holds being stale
+func promotedChanged(dir string, promoted map[string]string, cfg *config.Config) bool {
+	for importPath, filesHash := range promoted {
+		if current, err := hashPackageFiles(dir, importPath, cfg, false, false); err != nil || current != filesHash {
+			logger.Verbose("Cached fields promoted from %s are stale", importPath)
+			return true
+		}
+	}
+	return false
+}
+
+// hashPackageFiles hashes the Go files of a package, listed by packageFiles
+func hashPackageFiles(dir, pattern string, cfg *config.Config, tests, skipOutputs bool) (string, error) {
+	files, err := packageFiles(dir, pattern, cfg, tests, skipOutputs)
+	if err != nil {
+		return "", err
+	}
+	return cache.HashFiles(files)
+}
+
+// packageFiles lists the Go files of a package, its _test.go files too when tests is set, but the
+// output files of the configuration when skipOutputs is set, without the type checking of a full load
+func packageFiles(dir, pattern string, cfg *config.Config, tests, skipOutputs bool) ([]string, error) {
+	pkgCfg := packagesConfig(cfg, dir, packages.NeedName|packages.NeedFiles)
+	pkgCfg.Tests = tests
+	pkgs, err := packages.Load(pkgCfg, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("listing files of %s failed", pattern)
+	}
+	pkg := packageVariant(pkgs, tests)
+	if len(pkg.Errors) > 0 {
+		return nil, fmt.Errorf("listing files of %s failed", pattern)
+	}
+
+	var files []string
+	for _, file := range pkg.GoFiles {
+		if !skipOutputs || !cfg.IsOutputFile(filepath.Base(file)) {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// newPackageEntry prepares a parsed package for the cache
+func newPackageEntry(
+	dtos []types.DTOMapping,
+	sources map[string]types.SourceStruct,
+	functions map[string]types.FunctionInfo,
+	pkgName string,
+) packageEntry {
+	entry := packageEntry{DTOs: dtos, Sources: sources, Functions: functions, Name: pkgName, Positions: make(map[string]types.Position)}
+	for _, dto := range dtos {
+		entry.Positions[dto.Name] = dto.Position
+		for _, field := range dto.Fields {
+			entry.Positions[dto.Name+"."+field.Name] = field.Position
+		}
+	}
+	return entry
+}
+
+// restorePositions puts the positions back into the DTOs and their fields
+func (e *packageEntry) restorePositions() {
+	for i, dto := range e.DTOs {
+		e.DTOs[i].Position = e.Positions[dto.Name]
+		for j, field := range dto.Fields {
+			e.DTOs[i].Fields[j].Position = e.Positions[dto.Name+"."+field.Name]
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// SourceCandidates lists the exported structs of a package that DTOs may be mapped from
+type SourceCandidates struct {
+	Name       string // package name, the default alias of external packages
+	ImportPath string
+	Structs    []string
+	Imported   bool // whether the DTO package already imports the package
+}
+
+// FindSourceCandidates lists the exported structs of the package in a directory and of the
+// other packages of its module, which are the likely sources of its DTOs
+func FindSourceCandidates(pkgPath string) (SourceCandidates, []SourceCandidates, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName |
+			packages.NeedFiles |
+			packages.NeedSyntax |
+			packages.NeedImports |
+			packages.NeedModule,
+		Dir: pkgPath,
+	}, ".")
+	if err != nil {
+		return SourceCandidates{}, nil, fmt.Errorf("loading package: %w", err)
+	}
+	if len(pkgs) == 0 {
+		return SourceCandidates{}, nil, fmt.Errorf("no packages found in: %s", pkgPath)
+	}
+
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		return SourceCandidates{}, nil, fmt.Errorf("package has errors: %v", pkg.Errors[0])
+	}
+	local := structCandidates(pkg)
+	if pkg.Module == nil {
+		return local, nil, nil
+	}
+
+	// Structs are looked up in the syntax alone, the module doesn't need to type check
+	modulePkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax,
+		Dir:  pkg.Module.Dir,
+	}, "./...")
+	if err != nil {
+		return SourceCandidates{}, nil, fmt.Errorf("loading module %s: %w", pkg.Module.Path, err)
+	}
+
+	var module []SourceCandidates
+	for _, modulePkg := range modulePkgs {
+		// Commands and internal packages out of reach can't be imported by the DTO package
+		if modulePkg.PkgPath == pkg.PkgPath || modulePkg.Name == "main" || !canImport(pkg.PkgPath, modulePkg.PkgPath) {
+			continue
+		}
+		if candidates := structCandidates(modulePkg); len(candidates.Structs) > 0 {
+			_, candidates.Imported = pkg.Imports[modulePkg.PkgPath]
+			module = append(module, candidates)
+		}
+	}
+	slices.SortFunc(module, func(a, b SourceCandidates) int {
+		return strings.Compare(a.ImportPath, b.ImportPath)
+	})
+
+	return local, module, nil
+}
+
+// canImport reports whether a package may import another, the go command allowing internal packages
+// to be imported only from the tree rooted at the parent of their internal directory
+func canImport(importer, imported string) bool {
+	var parent string
+	switch {
+	case strings.HasSuffix(imported, "/internal"):
+		parent = strings.TrimSuffix(imported, "/internal")
+	case strings.Contains(imported, "/internal/"):
+		parent = imported[:strings.LastIndex(imported, "/internal/")]
+	default:
+		// A module rooted internal directory is reachable from the whole module
+		return true
+	}
+	return importer == parent || strings.HasPrefix(importer, parent+"/")
+}
+
+// structCandidates returns the exported structs declared in the syntax of a package, skipping generated files
+func structCandidates(pkg *packages.Package) SourceCandidates {
+	candidates := SourceCandidates{Name: pkg.Name, ImportPath: pkg.PkgPath}
+
+	for _, file := range pkg.Syntax {
+		if isGeneratedFile(file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if _, isStruct := typeSpec.Type.(*ast.StructType); isStruct && typeSpec.Name.IsExported() {
+					candidates.Structs = append(candidates.Structs, typeSpec.Name.Name)
+				}
+			}
+		}
+	}
+	slices.Sort(candidates.Structs)
+	return candidates
+}
+
+// isGeneratedFile reports whether a file carries a generated code notice before its package clause,
+// including the block comment notice of automapper-gen
+func isGeneratedFile(file *ast.File) bool {
+	if ast.IsGenerated(file) {
+		return true
+	}
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		if text := group.Text(); strings.Contains(text, "Code generated") && strings.Contains(text, "DO NOT EDIT") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package parser
+
+import (
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// configDirectivePrefix starts the configuration directives of a package doc comment
+const configDirectivePrefix = "automapper-config:"
+
+// FindConfigDirectives returns the automapper-config directives of the package doc comments of the
+// Go files in a directory, as key=value strings in file order. Test files are skipped.
+func FindConfigDirectives(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var directives []string
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := goparser.ParseFile(fset, filepath.Join(dir, name), nil, goparser.PackageClauseOnly|goparser.ParseComments)
+		if err != nil || file.Doc == nil {
+			continue
+		}
+		for _, comment := range file.Doc.List {
+			text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+			if directive, ok := strings.CutPrefix(text, configDirectivePrefix); ok {
+				directives = append(directives, strings.TrimSpace(directive))
+			}
+		}
+	}
+	return directives, nil
+}
@@ -0,0 +1,258 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	gotypes "go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/cache"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+	"golang.org/x/tools/go/packages"
+)
+
+// converterPackage holds the converters declared in a converter package, as stored in the cache
+type converterPackage struct {
+	Converters []config.ConverterDef
+	Functions  map[string]types.FunctionInfo
+}
+
+// loadConverterPackages scans the converter packages of the configuration for annotated converter functions.
+// Their converters are added to the configuration unless it declares one of the same name, and their
+// functions are returned by qualified name, e.g. example.com/shared/convert.ToLower.
+func loadConverterPackages(pkgPath string, cfg *config.Config, c *cache.Cache) (map[string]types.FunctionInfo, error) {
+	functions := make(map[string]types.FunctionInfo)
+	for _, importPath := range cfg.ConverterPackages {
+		logger.Verbose("Loading converter package: %s", importPath)
+
+		key := ""
+		if c != nil {
+			filesHash, err := hashPackageFiles(pkgPath, importPath, cfg, false, false)
+			if err != nil {
+				logger.Debug("Hashing files of %s: %v", importPath, err)
+			} else {
+				key = c.Key("converters", importPath, filesHash)
+			}
+		}
+
+		var converters converterPackage
+		if !c.Get(key, &converters) {
+			var err error
+			converters, err = parseConverterPackage(pkgPath, importPath, cfg)
+			if err != nil {
+				return nil, err
+			}
+			c.Put(key, converters)
+		}
+
+		for _, conv := range converters.Converters {
+			if slices.ContainsFunc(cfg.Converters, func(declared config.ConverterDef) bool { return declared.Name == conv.Name }) {
+				logger.Verbose("  Converter %s of %s overridden by the configuration", conv.Name, importPath)
+				continue
+			}
+			cfg.Converters = append(cfg.Converters, conv)
+			logger.Debug("  Found converter: %s -> %s", conv.Name, conv.Function)
+		}
+		for name, fn := range converters.Functions {
+			functions[name] = fn
+		}
+		logger.Verbose("  Loaded %d converters from %s", len(converters.Converters), importPath)
+	}
+	return functions, nil
+}
+
+// parseConverterPackage collects the functions of a package annotated with automapper:converter=Name,
+// and those annotated with automapper:inverter=Name as the inverters of these converters
+func parseConverterPackage(pkgPath, importPath string, cfg *config.Config) (converterPackage, error) {
+	pkgs, err := packages.Load(packagesConfig(cfg, pkgPath, packages.NeedName|packages.NeedFiles|packages.NeedSyntax), importPath)
+	if err != nil {
+		return converterPackage{}, fmt.Errorf("loading converter package %s: %w", importPath, err)
+	}
+	if len(pkgs) == 0 {
+		return converterPackage{}, fmt.Errorf("no packages found for converter package: %s", importPath)
+	}
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		var errMsgs []string
+		for _, e := range pkg.Errors {
+			errMsgs = append(errMsgs, e.Error())
+		}
+		return converterPackage{}, fmt.Errorf("converter package %s: %s", importPath, strings.Join(errMsgs, "; "))
+	}
+
+	result := converterPackage{Functions: make(map[string]types.FunctionInfo)}
+	inverters := make(map[string]string)
+	for _, file := range pkg.Syntax {
+		fileFunctions := ParseFunctions(file, nil)
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Recv != nil || !funcDecl.Name.IsExported() {
+				continue
+			}
+
+			name := funcDecl.Name.Name
+			qualified := pkg.PkgPath + "." + name
+			converter := ExtractDirective(funcDecl.Doc, "converter")
+			inverterOf := ExtractDirective(funcDecl.Doc, "inverter")
+			if converter == "" && inverterOf == "" {
+				continue
+			}
+
+			fn := fileFunctions[name]
+			fn.Name = qualified
+			result.Functions[qualified] = fn
+			if converter != "" {
+				result.Converters = append(result.Converters, config.ConverterDef{Name: converter, Function: qualified})
+			}
+			if inverterOf != "" {
+				inverters[inverterOf] = qualified
+			}
+		}
+	}
+
+	for i, conv := range result.Converters {
+		result.Converters[i].Inverter = inverters[conv.Name]
+		delete(inverters, conv.Name)
+	}
+	for converter, inverter := range inverters {
+		logger.Warning("Inverter %s declared for unknown converter %s of %s", inverter, converter, importPath)
+	}
+	return result, nil
+}
+
+// resolveImportedConverters resolves the converter tags of the fields of a DTO naming a function of
+// a package its file imports, e.g. converter=convert.TimeToString, to the qualified function. The
+// signature of the function is read from the type information of the imported package.
+func resolveImportedConverters(
+	dto *types.DTOMapping, file *ast.File, info *gotypes.Info, cfg *config.Config, functions map[string]types.FunctionInfo,
+) {
+	for i, field := range dto.Fields {
+		pkgName, funcName, ok := strings.Cut(field.ConverterTag, ".")
+		if !ok || slices.ContainsFunc(cfg.Converters, func(conv config.ConverterDef) bool { return conv.Name == field.ConverterTag }) {
+			continue
+		}
+
+		imported := importedPackage(file, info, pkgName)
+		if imported == nil {
+			continue
+		}
+		fn, ok := imported.Scope().Lookup(funcName).(*gotypes.Func)
+		if !ok || !fn.Exported() {
+			logger.Debug("  %s.%s: %s is not a function of %s", dto.Name, field.Name, funcName, imported.Path())
+			continue
+		}
+
+		qualified := imported.Path() + "." + funcName
+		functions[qualified] = signatureInfo(qualified, fn.Signature())
+		dto.Fields[i].ConverterFunc = qualified
+		logger.Debug("  %s.%s: converter %s resolved to %s", dto.Name, field.Name, field.ConverterTag, qualified)
+	}
+}
+
+// importedPackage returns the package a file imports under a name, if any
+func importedPackage(file *ast.File, info *gotypes.Info, name string) *gotypes.Package {
+	if info == nil {
+		return nil
+	}
+	for _, spec := range file.Imports {
+		if pkgName := info.PkgNameOf(spec); pkgName != nil && pkgName.Name() == name {
+			return pkgName.Imported()
+		}
+	}
+	return nil
+}
+
+// signatureInfo describes a function from its signature, its types qualified by package name
+func signatureInfo(name string, signature *gotypes.Signature) types.FunctionInfo {
+	qualifier := func(pkg *gotypes.Package) string { return pkg.Name() }
+	fn := types.FunctionInfo{Name: name}
+	for typeParam := range signature.TypeParams().TypeParams() {
+		fn.TypeParams = append(fn.TypeParams, typeParam.Obj().Name())
+	}
+	for param := range signature.Params().Variables() {
+		fn.ParamTypes = append(fn.ParamTypes, gotypes.TypeString(param.Type(), qualifier))
+		fn.Params = append(fn.Params, typeShape(param.Type(), qualifier))
+	}
+	for result := range signature.Results().Variables() {
+		fn.ReturnTypes = append(fn.ReturnTypes, gotypes.TypeString(result.Type(), qualifier))
+		fn.Results = append(fn.Results, typeShape(result.Type(), qualifier))
+	}
+	return fn
+}
+
+// typeShape breaks a type down into pointer, slice and base type, like extractTypeInfo does with
+// the expression of a type
+func typeShape(t gotypes.Type, qualifier gotypes.Qualifier) types.FieldTypeInfo {
+	base := t
+	typeInfo := types.FieldTypeInfo{Type: gotypes.TypeString(t, qualifier)}
+	switch u := gotypes.Unalias(t).(type) {
+	case *gotypes.Pointer:
+		typeInfo.IsPointer = true
+		base = u.Elem()
+	case *gotypes.Slice:
+		typeInfo.IsSlice = true
+		base = u.Elem()
+	}
+	typeInfo.BaseType = gotypes.TypeString(base, qualifier)
+	typeInfo.BaseIdentity = identityOf(base)
+	describeTypeShape(&typeInfo, base, qualifier)
+	return typeInfo
+}
+
+// describeTypeShape breaks a base type down like describeTypeLiteral when it is a type literal
+func describeTypeShape(typeInfo *types.FieldTypeInfo, base gotypes.Type, qualifier gotypes.Qualifier) {
+	switch t := gotypes.Unalias(base).(type) {
+	case *gotypes.Map:
+		typeInfo.Kind = types.KindMap
+		key, elem := typeShape(t.Key(), qualifier), typeShape(t.Elem(), qualifier)
+		typeInfo.Key, typeInfo.Elem = &key, &elem
+	case *gotypes.Chan:
+		typeInfo.Kind = types.KindChan
+		elem := typeShape(t.Elem(), qualifier)
+		typeInfo.Elem = &elem
+		switch t.Dir() {
+		case gotypes.SendOnly:
+			typeInfo.Dir = "chan<-"
+		case gotypes.RecvOnly:
+			typeInfo.Dir = "<-chan"
+		default:
+			typeInfo.Dir = "chan"
+		}
+	case *gotypes.Array:
+		typeInfo.Kind = types.KindArray
+		typeInfo.Len = strconv.FormatInt(t.Len(), 10)
+		elem := typeShape(t.Elem(), qualifier)
+		typeInfo.Elem = &elem
+	case *gotypes.Signature:
+		typeInfo.Kind = types.KindFunc
+		for v := range t.Params().Variables() {
+			typeInfo.Params = append(typeInfo.Params, typeShape(v.Type(), qualifier))
+		}
+		for v := range t.Results().Variables() {
+			typeInfo.Results = append(typeInfo.Results, typeShape(v.Type(), qualifier))
+		}
+	case *gotypes.Struct:
+		typeInfo.Kind = types.KindStruct
+	case *gotypes.Interface:
+		typeInfo.Kind = types.KindInterface
+	}
+}
+
+// addImportedConverters declares the converters of the tags naming functions of imported packages,
+// unless the configuration declares one of the same name
+func addImportedConverters(cfg *config.Config, dtos []types.DTOMapping) {
+	for _, dto := range dtos {
+		for _, field := range dto.Fields {
+			if field.ConverterFunc == "" ||
+				slices.ContainsFunc(cfg.Converters, func(conv config.ConverterDef) bool { return conv.Name == field.ConverterTag }) {
+				continue
+			}
+			cfg.Converters = append(cfg.Converters, config.ConverterDef{Name: field.ConverterTag, Function: field.ConverterFunc})
+			logger.Verbose("  Converter %s -> %s", field.ConverterTag, field.ConverterFunc)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+)
+
+// ConfigFileName is the name of the configuration file of a package
+const ConfigFileName = "automapper.json"
+
+// configBaseName is the name of the configuration file without its extension
+const configBaseName = "automapper"
+
+// FindConfigFile returns the configuration file of the package in a directory, automapper.json or
+// automapper.toml in that order of preference, or an empty string when it has none
+func FindConfigFile(dir string) string {
+	for _, ext := range config.Extensions {
+		path := filepath.Join(dir, configBaseName+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// dtoDirectives are the directives declaring a DTO of the package
+var dtoDirectives = []string{"from", "sources", "via"}
+
+// FindPackages returns the directories under root holding a package to generate for:
+// those with a configuration file or with annotated DTOs. Like the go tool, it skips
+// vendor and testdata directories and directories starting with . or _.
+func FindPackages(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+
+		if path != root && isIgnoredDir(entry.Name()) {
+			return filepath.SkipDir
+		}
+
+		if FindConfigFile(path) != "" {
+			dirs = append(dirs, path)
+			return nil
+		}
+
+		annotated, err := HasAnnotatedDTOs(path)
+		if err != nil {
+			return err
+		}
+		if annotated {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// isIgnoredDir reports whether the go tool ignores a directory in ./... patterns
+func isIgnoredDir(name string) bool {
+	return name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// HasAnnotatedDTOs reports whether the Go files of a directory declare DTOs with automapper directives.
+// Files that don't parse are skipped, the package is reported by the full parse later on.
+func HasAnnotatedDTOs(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := goparser.ParseFile(fset, filepath.Join(dir, name), nil, goparser.ParseComments|goparser.SkipObjectResolution)
+		if err != nil {
+			logger.Debug("Skipping %s: %v", filepath.Join(dir, name), err)
+			continue
+		}
+
+		annotations := newTypeAnnotations(fset, file)
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				for _, key := range dtoDirectives {
+					if annotations.directive(typeSpec, key) != "" {
+						return true, nil
+					}
+				}
+			}
+		}
+	}
+	return false, nil
+}
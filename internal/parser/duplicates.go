@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// checkDuplicateStructs reports the structs a package declares more than once, e.g. a test double
+// left next to the struct it stands for, with the position of every declaration. It runs ahead of
+// the type errors of the package, the redeclarations being reported more clearly.
+func checkDuplicateStructs(fset *token.FileSet, files []*ast.File) error {
+	declarations := make(map[string][]token.Pos)
+	var names []string
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok || typeSpec.Name.Name == "_" {
+					continue
+				}
+				if _, ok := typeSpec.Type.(*ast.StructType); !ok {
+					continue
+				}
+				name := typeSpec.Name.Name
+				if _, seen := declarations[name]; !seen {
+					names = append(names, name)
+				}
+				declarations[name] = append(declarations[name], typeSpec.Name.Pos())
+			}
+		}
+	}
+
+	var errs []error
+	for _, name := range names {
+		positions := declarations[name]
+		if len(positions) < 2 {
+			continue
+		}
+		declared := make([]string, len(positions))
+		for i, pos := range positions {
+			declared[i] = fset.Position(pos).String()
+		}
+		errs = append(errs, fmt.Errorf("struct %s is declared %d times: at %s", name, len(positions), strings.Join(declared, " and ")))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"go/ast"
+	gotypes "go/types"
+	"slices"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+)
+
+// addPromotedFields adds the fields a struct embeds: each embedded field under the name of its type,
+// e.g. Model for gorm.Model, and the exported fields promoted from the structs embedded by value,
+// whatever their package, e.g. ID and CreatedAt. Promotion follows Go: a shallower field hides the
+// deeper ones of its name and fields of the same name at the same depth hide each other. Fields of
+// structs embedded by pointer aren't promoted, reading them through a nil pointer would panic.
+// It returns the import paths of the other packages declaring the structs fields are promoted from,
+// whose changes change the fields.
+func addPromotedFields(fields map[string]types.FieldTypeInfo, embedded []*ast.Field, info *gotypes.Info) []string {
+	hidden := make(map[string]bool, len(fields))
+	for name := range fields {
+		hidden[name] = true
+	}
+
+	var level []gotypes.Type
+	var pkg *gotypes.Package // package of the struct, its types are written unqualified
+	for _, field := range embedded {
+		ident := embeddedIdent(field.Type)
+		if ident == nil {
+			continue
+		}
+		typeInfo := extractTypeInfo(field.Type)
+		typeInfo.BaseIdentity = identify(info, sourceBaseExpr(field.Type))
+		fields[ident.Name] = typeInfo
+		hidden[ident.Name] = true
+
+		if info == nil {
+			continue
+		}
+		if v, ok := info.Defs[ident].(*gotypes.Var); ok {
+			pkg = v.Pkg()
+		}
+		if t := info.TypeOf(field.Type); t != nil && !typeInfo.IsPointer {
+			level = append(level, t)
+		}
+	}
+	qualifier := func(p *gotypes.Package) string {
+		if p == pkg {
+			return ""
+		}
+		return p.Name()
+	}
+
+	var from []string
+	for len(level) > 0 {
+		promoted := make(map[string]types.FieldTypeInfo)
+		count := make(map[string]int)
+		var next []gotypes.Type
+		for _, t := range level {
+			st, ok := t.Underlying().(*gotypes.Struct)
+			if !ok {
+				continue
+			}
+			if named, ok := gotypes.Unalias(t).(*gotypes.Named); ok {
+				if p := named.Obj().Pkg(); p != nil && p != pkg && !slices.Contains(from, p.Path()) {
+					from = append(from, p.Path())
+				}
+			}
+			for v := range st.Fields() {
+				if hidden[v.Name()] {
+					continue
+				}
+				promoted[v.Name()] = typeShape(v.Type(), qualifier)
+				count[v.Name()]++
+				if _, isPointer := gotypes.Unalias(v.Type()).(*gotypes.Pointer); v.Embedded() && !isPointer {
+					next = append(next, v.Type())
+				}
+			}
+		}
+		for name, typeInfo := range promoted {
+			hidden[name] = true
+			if count[name] == 1 && ast.IsExported(name) {
+				fields[name] = typeInfo
+			}
+		}
+		level = next
+	}
+	return from
+}
+
+// embeddedIdent returns the identifier naming an embedded field, e.g. Model for *gorm.Model
+func embeddedIdent(expr ast.Expr) *ast.Ident {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t
+	case *ast.StarExpr:
+		return embeddedIdent(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel
+	case *ast.IndexExpr:
+		return embeddedIdent(t.X)
+	case *ast.IndexListExpr:
+		return embeddedIdent(t.X)
+	}
+	return nil
+}
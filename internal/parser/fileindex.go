@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/cache"
+)
+
+// packageIndex records the files the cached main package was last parsed from, so a run whose
+// files only changed inside function bodies, which parsing extracts nothing from, reuses the entry
+type packageIndex struct {
+	Entry string               // key of the package entry
+	Files map[string]fileState // by file name
+}
+
+// fileState identifies the content of a file and its declarations
+type fileState struct {
+	Hash         string
+	Declarations string // hash of the content outside the function bodies
+}
+
+// newPackageIndex indexes the files a package entry is parsed from
+func newPackageIndex(entry string, files []string) (packageIndex, error) {
+	index := packageIndex{Entry: entry, Files: make(map[string]fileState, len(files))}
+	for _, file := range files {
+		hash, err := cache.HashFile(file)
+		if err != nil {
+			return packageIndex{}, err
+		}
+		declarations, err := hashDeclarations(file)
+		if err != nil {
+			return packageIndex{}, err
+		}
+		index.Files[filepath.Base(file)] = fileState{Hash: hash, Declarations: declarations}
+	}
+	return index, nil
+}
+
+// update records the current state of the files of the package, reporting whether the entry still
+// holds, none of them being added, removed or changed outside its function bodies, and the files
+// changed. Only those are read again, and parsed without type checking.
+func (index *packageIndex) update(files []string) ([]string, bool) {
+	if len(files) != len(index.Files) {
+		return nil, false
+	}
+
+	var changed []string
+	for _, file := range files {
+		name := filepath.Base(file)
+		state, ok := index.Files[name]
+		if !ok {
+			return nil, false
+		}
+		hash, err := cache.HashFile(file)
+		if err != nil {
+			return nil, false
+		}
+		if hash == state.Hash {
+			continue
+		}
+		declarations, err := hashDeclarations(file)
+		if err != nil || declarations != state.Declarations {
+			return nil, false
+		}
+		index.Files[name] = fileState{Hash: hash, Declarations: declarations}
+		changed = append(changed, name)
+	}
+	return changed, true
+}
+
+// hashDeclarations hashes the content of a Go file outside its function bodies. The line breaks
+// of the bodies are kept, a declaration moving to another line changes the hash.
+func hashDeclarations(path string) (string, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	fset := token.NewFileSet()
+	file, err := goparser.ParseFile(fset, path, src, goparser.SkipObjectResolution)
+	if err != nil {
+		return "", err
+	}
+
+	var declarations []byte
+	start := 0
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		lbrace := fset.Position(fn.Body.Lbrace).Offset + 1
+		rbrace := fset.Position(fn.Body.Rbrace).Offset
+		declarations = append(declarations, src[start:lbrace]...)
+		declarations = append(declarations, bytes.Repeat([]byte("\n"), bytes.Count(src[lbrace:rbrace], []byte("\n")))...)
+		start = rbrace
+	}
+	declarations = append(declarations, src[start:]...)
+
+	sum := sha256.Sum256(declarations)
+	return hex.EncodeToString(sum[:]), nil
+}
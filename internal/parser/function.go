@@ -2,12 +2,14 @@ package parser
 
 import (
 	"go/ast"
+	gotypes "go/types"
 
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
 )
 
-// ParseFunctions extracts function declarations from AST
-func ParseFunctions(file *ast.File) map[string]types.FunctionInfo {
+// ParseFunctions extracts function declarations from AST, identifying the types of their signatures
+// with the type information of the package when given
+func ParseFunctions(file *ast.File, info *gotypes.Info) map[string]types.FunctionInfo {
 	functions := make(map[string]types.FunctionInfo)
 
 	for _, decl := range file.Decls {
@@ -21,12 +23,21 @@ func ParseFunctions(file *ast.File) map[string]types.FunctionInfo {
 				Name: funcDecl.Name.Name,
 			}
 
+			if funcDecl.Type.TypeParams != nil {
+				for _, typeParam := range funcDecl.Type.TypeParams.List {
+					for _, name := range typeParam.Names {
+						funcInfo.TypeParams = append(funcInfo.TypeParams, name.Name)
+					}
+				}
+			}
+
 			// Analyze function signature
 			if funcDecl.Type.Params != nil && len(funcDecl.Type.Params.List) > 0 {
 				// Get parameter types
 				for _, param := range funcDecl.Type.Params.List {
 					paramType := exprToString(param.Type)
 					funcInfo.ParamTypes = append(funcInfo.ParamTypes, paramType)
+					funcInfo.Params = append(funcInfo.Params, signatureType(param.Type, info))
 				}
 			}
 
@@ -35,6 +46,7 @@ func ParseFunctions(file *ast.File) map[string]types.FunctionInfo {
 				for _, result := range funcDecl.Type.Results.List {
 					resultType := exprToString(result.Type)
 					funcInfo.ReturnTypes = append(funcInfo.ReturnTypes, resultType)
+					funcInfo.Results = append(funcInfo.Results, signatureType(result.Type, info))
 				}
 			}
 
@@ -45,6 +57,79 @@ func ParseFunctions(file *ast.File) map[string]types.FunctionInfo {
 	return functions
 }
 
+// signatureType breaks a type of a function signature down like the type of a source field
+func signatureType(expr ast.Expr, info *gotypes.Info) types.FieldTypeInfo {
+	typeInfo := extractTypeInfo(expr)
+	typeInfo.BaseIdentity = identify(info, sourceBaseExpr(expr))
+	return typeInfo
+}
+
+// ParseGetters extracts getter methods (no parameters, a single result) grouped by receiver type name,
+// identifying their result types with the type information of the package when given. Unexported
+// methods are left out when exportedOnly, the DTOs of another package can't call them.
+func ParseGetters(file *ast.File, info *gotypes.Info, exportedOnly bool) map[string]map[string]types.FieldTypeInfo {
+	getters := make(map[string]map[string]types.FieldTypeInfo)
+
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Recv == nil || len(funcDecl.Recv.List) != 1 {
+			continue
+		}
+		if exportedOnly && !funcDecl.Name.IsExported() {
+			continue
+		}
+
+		if funcDecl.Type.Params != nil && len(funcDecl.Type.Params.List) > 0 {
+			continue
+		}
+		results := funcDecl.Type.Results
+		if results == nil || len(results.List) != 1 || len(results.List[0].Names) > 1 {
+			continue
+		}
+
+		// Accept both value and pointer receivers
+		recvType := funcDecl.Recv.List[0].Type
+		if star, ok := recvType.(*ast.StarExpr); ok {
+			recvType = star.X
+		}
+		ident, ok := recvType.(*ast.Ident)
+		if !ok {
+			continue
+		}
+
+		if getters[ident.Name] == nil {
+			getters[ident.Name] = make(map[string]types.FieldTypeInfo)
+		}
+		getter := extractTypeInfo(results.List[0].Type)
+		getter.BaseIdentity = identify(info, sourceBaseExpr(results.List[0].Type))
+		getters[ident.Name][funcDecl.Name.Name] = getter
+	}
+
+	return getters
+}
+
+// mergeGetters adds the getters parsed from one file to the getters collected so far
+func mergeGetters(dst, src map[string]map[string]types.FieldTypeInfo) {
+	for recv, methods := range src {
+		if dst[recv] == nil {
+			dst[recv] = make(map[string]types.FieldTypeInfo)
+		}
+		for name, info := range methods {
+			dst[recv][name] = info
+		}
+	}
+}
+
+// attachGetters adds the collected getter methods to the source structs they belong to
+func attachGetters(sources map[string]types.SourceStruct, getters map[string]map[string]types.FieldTypeInfo) {
+	for key, source := range sources {
+		if structGetters, ok := getters[source.Name]; ok {
+			source.Getters = structGetters
+			sources[key] = source
+		}
+	}
+}
+
 // IsSafeConverterSignature checks if a function matches safe converter signature: func(T) U
 func IsSafeConverterSignature(fn types.FunctionInfo) bool {
 	return len(fn.ParamTypes) == 1 && len(fn.ReturnTypes) == 1
@@ -58,3 +143,13 @@ func IsErrorReturningConverterSignature(fn types.FunctionInfo) bool {
 	// Check if second return type is error
 	return fn.ReturnTypes[1] == "error"
 }
+
+// TakesPointer checks if a converter takes its value by pointer: func(*T) U
+func TakesPointer(fn types.FunctionInfo) bool {
+	return len(fn.Params) == 1 && fn.Params[0].IsPointer
+}
+
+// ReturnsPointer checks if a converter returns its value by pointer: func(T) *U or func(T) (*U, error)
+func ReturnsPointer(fn types.FunctionInfo) bool {
+	return len(fn.Results) > 0 && fn.Results[0].IsPointer
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"errors"
+	"go/ast"
+	goparser "go/parser"
+	"go/scanner"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// GeneratedNotice starts the header of every file written by automapper-gen
+const GeneratedNotice = "Code generated by automapper-gen"
+
+// FindGeneratedFiles returns the Go files written by automapper-gen in a directory, and in its
+// subdirectories when recursive. Files are recognized by their header, whatever their name.
+func FindGeneratedFiles(root string, recursive bool) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != root && (!recursive || isIgnoredDir(entry.Name())) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		generated, err := IsGeneratedByAutomapper(path)
+		if err != nil {
+			return err
+		}
+		if generated {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// IsGeneratedByAutomapper reports whether a Go file carries the header of automapper-gen before its
+// package clause. Files that don't parse aren't considered generated.
+func IsGeneratedByAutomapper(path string) (bool, error) {
+	file, err := goparser.ParseFile(token.NewFileSet(), path, nil, goparser.PackageClauseOnly|goparser.ParseComments)
+	if err != nil {
+		var syntaxErrors scanner.ErrorList
+		if errors.As(err, &syntaxErrors) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return hasAutomapperHeader(file), nil
+}
+
+// hasAutomapperHeader reports whether a parsed file carries the header of automapper-gen before its
+// package clause
+func hasAutomapperHeader(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		if strings.HasPrefix(strings.TrimSpace(group.Text()), GeneratedNotice) ||
+			strings.Contains(group.Text(), "\n"+GeneratedNotice) {
+			return true
+		}
+	}
+	return false
+}
+
+// generatedByOthers reports whether a parsed file is generated by another tool, e.g. stringer or
+// mockgen, carrying a // Code generated ... DO NOT EDIT. line before its package clause
+func generatedByOthers(file *ast.File) bool {
+	return ast.IsGenerated(file) && !hasAutomapperHeader(file)
+}
@@ -0,0 +1,182 @@
+package parser
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	gotypes "go/types"
+	"regexp"
+	"slices"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+)
+
+// typeIdent matches the identifiers of a type, qualified ones included, e.g. T or time.Time
+var typeIdent = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?`)
+
+// ConverterTypeArgs returns the type arguments a call of a generic converter has to give, e.g.
+// string for func To[D, S any](S) D: those up to the last type parameter its argument doesn't
+// determine, the rest being inferred. They are bound by matching the signature with the type of
+// the argument and of the result, the one written in the package of the DTOs, dtoSide being the
+// type on that side; the type on the other side only binds predeclared types. It reports false
+// when a type parameter to give can't be bound.
+func ConverterTypeArgs(fn types.FunctionInfo, argType, resultType string, argIsDTO bool) ([]string, bool) {
+	if len(fn.TypeParams) == 0 || len(fn.ParamTypes) != 1 || len(fn.ReturnTypes) == 0 {
+		return nil, true
+	}
+
+	inferred := typeIdent.FindAllString(fn.ParamTypes[0], -1)
+	last := -1
+	for i, typeParam := range fn.TypeParams {
+		if !slices.Contains(inferred, typeParam) {
+			last = i
+		}
+	}
+	if last < 0 {
+		return nil, true
+	}
+
+	bindings := make(map[string]string)
+	bind := func(pattern, concrete string, fromDTO bool) {
+		for typeParam, typeArg := range unifyType(pattern, concrete, fn.TypeParams) {
+			if _, bound := bindings[typeParam]; !bound && (fromDTO || isPredeclaredType(typeArg)) {
+				bindings[typeParam] = typeArg
+			}
+		}
+	}
+	bind(fn.ParamTypes[0], argType, argIsDTO)
+	bind(fn.ReturnTypes[0], resultType, !argIsDTO)
+
+	typeArgs := make([]string, last+1)
+	for i, typeParam := range fn.TypeParams[:last+1] {
+		typeArg, ok := bindings[typeParam]
+		if !ok {
+			return nil, false
+		}
+		typeArgs[i] = typeArg
+	}
+	return typeArgs, true
+}
+
+// unifyType binds the type parameters of a type written with them, e.g. map[K]V, by matching it
+// with a concrete type, e.g. map[string]int. Both are parsed, so a type parameter binds a whole
+// type, e.g. K binds string and V map[int]bool in map[string]map[int]bool. It returns no bindings
+// when the types don't match.
+func unifyType(pattern, concrete string, typeParams []string) map[string]string {
+	patternExpr, err := goparser.ParseExpr(pattern)
+	if err != nil {
+		return nil
+	}
+	concreteExpr, err := goparser.ParseExpr(concrete)
+	if err != nil {
+		return nil
+	}
+
+	bindings := make(map[string]string)
+	if !unifyExpr(patternExpr, concreteExpr, typeParams, bindings) {
+		return nil
+	}
+	return bindings
+}
+
+// unifyExpr matches the structure of a type written with type parameters with a concrete type,
+// adding the types the parameters stand for to bindings
+func unifyExpr(pattern, concrete ast.Expr, typeParams []string, bindings map[string]string) bool {
+	pattern, concrete = ast.Unparen(pattern), ast.Unparen(concrete)
+
+	if ident, ok := pattern.(*ast.Ident); ok && slices.Contains(typeParams, ident.Name) {
+		typeArg := gotypes.ExprString(concrete)
+		// A type parameter used twice binds the same type both times
+		if bound, ok := bindings[ident.Name]; ok {
+			return bound == typeArg
+		}
+		bindings[ident.Name] = typeArg
+		return true
+	}
+
+	switch p := pattern.(type) {
+	case *ast.StarExpr:
+		c, ok := concrete.(*ast.StarExpr)
+		return ok && unifyExpr(p.X, c.X, typeParams, bindings)
+	case *ast.ArrayType:
+		c, ok := concrete.(*ast.ArrayType)
+		if !ok || (p.Len == nil) != (c.Len == nil) {
+			return false
+		}
+		if p.Len != nil && gotypes.ExprString(p.Len) != gotypes.ExprString(c.Len) {
+			return false
+		}
+		return unifyExpr(p.Elt, c.Elt, typeParams, bindings)
+	case *ast.Ellipsis:
+		c, ok := concrete.(*ast.Ellipsis)
+		return ok && unifyExpr(p.Elt, c.Elt, typeParams, bindings)
+	case *ast.MapType:
+		c, ok := concrete.(*ast.MapType)
+		return ok && unifyExpr(p.Key, c.Key, typeParams, bindings) && unifyExpr(p.Value, c.Value, typeParams, bindings)
+	case *ast.ChanType:
+		c, ok := concrete.(*ast.ChanType)
+		return ok && p.Dir == c.Dir && unifyExpr(p.Value, c.Value, typeParams, bindings)
+	case *ast.FuncType:
+		c, ok := concrete.(*ast.FuncType)
+		return ok && unifyFields(p.Params, c.Params, typeParams, bindings) &&
+			unifyFields(p.Results, c.Results, typeParams, bindings)
+	case *ast.IndexExpr:
+		c, ok := concrete.(*ast.IndexExpr)
+		return ok && unifyExpr(p.X, c.X, typeParams, bindings) && unifyExpr(p.Index, c.Index, typeParams, bindings)
+	case *ast.IndexListExpr:
+		c, ok := concrete.(*ast.IndexListExpr)
+		if !ok || len(p.Indices) != len(c.Indices) || !unifyExpr(p.X, c.X, typeParams, bindings) {
+			return false
+		}
+		for i := range p.Indices {
+			if !unifyExpr(p.Indices[i], c.Indices[i], typeParams, bindings) {
+				return false
+			}
+		}
+		return true
+	}
+	// Identifiers, qualified ones and struct and interface literals match when written the same
+	return gotypes.ExprString(pattern) == gotypes.ExprString(concrete)
+}
+
+// unifyFields matches the parameters or results of two func types, a field per name
+func unifyFields(pattern, concrete *ast.FieldList, typeParams []string, bindings map[string]string) bool {
+	patternTypes, concreteTypes := fieldTypes(pattern), fieldTypes(concrete)
+	if len(patternTypes) != len(concreteTypes) {
+		return false
+	}
+	for i := range patternTypes {
+		if !unifyExpr(patternTypes[i], concreteTypes[i], typeParams, bindings) {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldTypes lists the type of every parameter or result of a field list, once per name
+func fieldTypes(list *ast.FieldList) []ast.Expr {
+	if list == nil {
+		return nil
+	}
+	var exprs []ast.Expr
+	for _, field := range list.List {
+		for range max(len(field.Names), 1) {
+			exprs = append(exprs, field.Type)
+		}
+	}
+	return exprs
+}
+
+// isPredeclaredType reports whether a type is written with predeclared identifiers only, e.g.
+// []string, so it reads the same in any package
+func isPredeclaredType(typeName string) bool {
+	for _, ident := range typeIdent.FindAllString(typeName, -1) {
+		if token.IsKeyword(ident) {
+			continue
+		}
+		if _, ok := gotypes.Universe.Lookup(ident).(*gotypes.TypeName); !ok {
+			return false
+		}
+	}
+	return true
+}
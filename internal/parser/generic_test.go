@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestUnifyType(t *testing.T) {
+	tests := []struct {
+		name       string
+		pattern    string
+		concrete   string
+		typeParams []string
+		want       map[string]string
+	}{
+		{
+			name:       "type parameter",
+			pattern:    "T",
+			concrete:   "time.Time",
+			typeParams: []string{"T"},
+			want:       map[string]string{"T": "time.Time"},
+		},
+		{
+			name:       "slice of pointers",
+			pattern:    "[]*T",
+			concrete:   "[]*db.User",
+			typeParams: []string{"T"},
+			want:       map[string]string{"T": "db.User"},
+		},
+		{
+			name:       "map",
+			pattern:    "map[K]V",
+			concrete:   "map[string]int",
+			typeParams: []string{"K", "V"},
+			want:       map[string]string{"K": "string", "V": "int"},
+		},
+		{
+			name:       "nested map value",
+			pattern:    "map[K]V",
+			concrete:   "map[string]map[int]bool",
+			typeParams: []string{"K", "V"},
+			want:       map[string]string{"K": "string", "V": "map[int]bool"},
+		},
+		{
+			name:       "nested map key and value",
+			pattern:    "map[K]map[L]V",
+			concrete:   "map[string]map[int][]bool",
+			typeParams: []string{"K", "L", "V"},
+			want:       map[string]string{"K": "string", "L": "int", "V": "[]bool"},
+		},
+		{
+			name:       "func returning a func",
+			pattern:    "func(A) B",
+			concrete:   "func(int) func(string) bool",
+			typeParams: []string{"A", "B"},
+			want:       map[string]string{"A": "int", "B": "func(string) bool"},
+		},
+		{
+			name:       "func taking a func",
+			pattern:    "func(A, B) error",
+			concrete:   "func(func(int) string, []byte) error",
+			typeParams: []string{"A", "B"},
+			want:       map[string]string{"A": "func(int) string", "B": "[]byte"},
+		},
+		{
+			name:       "generic type",
+			pattern:    "Page[T]",
+			concrete:   "Page[map[string]int]",
+			typeParams: []string{"T"},
+			want:       map[string]string{"T": "map[string]int"},
+		},
+		{
+			name:       "type parameter used twice",
+			pattern:    "map[T]T",
+			concrete:   "map[string]string",
+			typeParams: []string{"T"},
+			want:       map[string]string{"T": "string"},
+		},
+		{
+			name:       "type parameter bound to different types",
+			pattern:    "map[T]T",
+			concrete:   "map[string]int",
+			typeParams: []string{"T"},
+			want:       nil,
+		},
+		{
+			name:       "different structure",
+			pattern:    "[]T",
+			concrete:   "map[string]int",
+			typeParams: []string{"T"},
+			want:       nil,
+		},
+		{
+			name:       "different func arity",
+			pattern:    "func(A) B",
+			concrete:   "func(int, int) bool",
+			typeParams: []string{"A", "B"},
+			want:       nil,
+		},
+		{
+			name:       "different channel direction",
+			pattern:    "<-chan T",
+			concrete:   "chan int",
+			typeParams: []string{"T"},
+			want:       nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unifyType(tt.pattern, tt.concrete, tt.typeParams)
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("unifyType(%q, %q) = %v, want %v", tt.pattern, tt.concrete, got, tt.want)
+			}
+		})
+	}
+}
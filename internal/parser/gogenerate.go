@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	goparser "go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// generatePrefix starts the go:generate directives, which the go tool only accepts at the start of a line
+const generatePrefix = "//go:generate "
+
+// GenerateDirective is a go:generate directive running automapper-gen
+type GenerateDirective struct {
+	File    string   // file declaring the directive
+	Line    int      // line of the directive, $GOLINE
+	Package string   // package of the file, $GOPACKAGE
+	Args    []string // arguments following the command, with variables not yet expanded
+}
+
+// FindGenerateDirectives returns the go:generate directives running automapper-gen in the Go files
+// of a directory, and of its subdirectories when recursive, skipping the directories the go tool ignores
+func FindGenerateDirectives(root string, recursive bool) ([]GenerateDirective, error) {
+	var directives []GenerateDirective
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != root && (!recursive || isIgnoredDir(entry.Name())) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		found, err := fileGenerateDirectives(path)
+		if err != nil {
+			return err
+		}
+		directives = append(directives, found...)
+		return nil
+	})
+	return directives, err
+}
+
+// fileGenerateDirectives returns the go:generate directives running automapper-gen in a file
+func fileGenerateDirectives(path string) ([]GenerateDirective, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var directives []GenerateDirective
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text, ok := strings.CutPrefix(scanner.Text(), generatePrefix)
+		if !ok {
+			continue
+		}
+
+		words, err := splitDirective(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if n := generatorCommandLength(words); n > 0 {
+			directives = append(directives, GenerateDirective{File: path, Line: line, Args: words[n:]})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(directives) == 0 {
+		return nil, nil
+	}
+
+	// Only the package clause is needed for $GOPACKAGE
+	parsed, err := goparser.ParseFile(token.NewFileSet(), path, nil, goparser.PackageClauseOnly)
+	if err != nil {
+		return nil, err
+	}
+	for i := range directives {
+		directives[i].Package = parsed.Name.Name
+	}
+	return directives, nil
+}
+
+// generatorCommandLength returns the number of words naming automapper-gen at the start of a directive,
+// either the binary itself or go run of its package, or 0 for the directives of other generators
+func generatorCommandLength(words []string) int {
+	isGenerator := func(word string) bool {
+		name, _, _ := strings.Cut(path.Base(filepath.ToSlash(word)), "@")
+		return name == "automapper-gen"
+	}
+
+	switch {
+	case len(words) > 0 && isGenerator(words[0]):
+		return 1
+	case len(words) > 2 && words[0] == "go" && words[1] == "run" && isGenerator(words[2]):
+		return 3
+	}
+	return 0
+}
+
+// splitDirective splits the arguments of a directive on spaces like the go tool does,
+// a double-quoted argument is a Go string and may contain spaces
+func splitDirective(text string) ([]string, error) {
+	var words []string
+	for {
+		text = strings.TrimLeft(text, " \t")
+		if text == "" {
+			return words, nil
+		}
+
+		if text[0] != '"' {
+			end := strings.IndexAny(text, " \t")
+			if end < 0 {
+				end = len(text)
+			}
+			words = append(words, text[:end])
+			text = text[end:]
+			continue
+		}
+
+		quoted, err := strconv.QuotedPrefix(text)
+		if err != nil {
+			return nil, fmt.Errorf("unterminated quoted string in go:generate directive")
+		}
+		word, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, word)
+		text = text[len(quoted):]
+	}
+}
@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"go/ast"
+	gotypes "go/types"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+)
+
+// identify identifies the type of an expression, leaving the identity empty without type information
+func identify(info *gotypes.Info, expr ast.Expr) types.TypeIdentity {
+	if info == nil {
+		return types.TypeIdentity{}
+	}
+	t := info.TypeOf(expr)
+	if t == nil {
+		return types.TypeIdentity{}
+	}
+	return identityOf(t)
+}
+
+// identityOf identifies a type
+func identityOf(t gotypes.Type) types.TypeIdentity {
+	t = gotypes.Unalias(t)
+	return types.TypeIdentity{
+		Type:       gotypes.TypeString(t, nil),
+		Underlying: gotypes.TypeString(t.Underlying(), nil),
+	}
+}
+
+// sourceBaseExpr returns the expression of the base type of a source field, under one pointer or
+// slice like extractTypeInfo takes it
+func sourceBaseExpr(expr ast.Expr) ast.Expr {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return t.X
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return t.Elt
+		}
+	}
+	return expr
+}
+
+// dtoBaseExpr returns the expression of the base type of a DTO field, under a pointer and then a slice
+// like the validator and the generator take it
+func dtoBaseExpr(expr ast.Expr) ast.Expr {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if array, ok := expr.(*ast.ArrayType); ok && array.Len == nil {
+		expr = array.Elt
+	}
+	return expr
+}
@@ -6,27 +6,26 @@ import (
 	"go/token"
 	"strings"
 
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
 	"golang.org/x/tools/go/packages"
 )
 
 // LoadExternalPackage loads a package from the module system (can be remote)
-func LoadExternalPackage(importPath, alias string) (map[string]types.SourceStruct, error) {
+func LoadExternalPackage(importPath, alias string, cfg *config.Config) (map[string]types.SourceStruct, error) {
 	logger.Debug("Loading package via go/packages: %s", importPath)
 
 	// Configure package loading
-	cfg := &packages.Config{
-		Mode: packages.NeedName |
-			packages.NeedFiles |
-			packages.NeedSyntax |
-			packages.NeedTypes |
-			packages.NeedTypesInfo,
-	}
+	pkgCfg := packagesConfig(cfg, "", packages.NeedName|
+		packages.NeedFiles|
+		packages.NeedSyntax|
+		packages.NeedTypes|
+		packages.NeedTypesInfo)
 
 	// Load the package
 	logger.Debug("Invoking packages.Load for: %s", importPath)
-	pkgs, err := packages.Load(cfg, importPath)
+	pkgs, err := packages.Load(pkgCfg, importPath)
 	if err != nil {
 		return nil, fmt.Errorf("loading package %s: %w", importPath, err)
 	}
@@ -38,6 +37,11 @@ func LoadExternalPackage(importPath, alias string) (map[string]types.SourceStruc
 	pkg := pkgs[0]
 	logger.Debug("Package loaded: %s (files: %d)", pkg.Name, len(pkg.Syntax))
 
+	// Structs declared twice fail type checking, their declarations are reported instead
+	if err := checkDuplicateStructs(pkg.Fset, pkg.Syntax); err != nil {
+		return nil, err
+	}
+
 	// Check for errors
 	if len(pkg.Errors) > 0 {
 		var errMsgs []string
@@ -59,9 +63,17 @@ func LoadExternalPackage(importPath, alias string) (map[string]types.SourceStruc
 	// Parse all syntax trees in the package
 	fileCount := 0
 	totalStructs := 0
+	getters := make(map[string]map[string]types.FieldTypeInfo)
 	for _, file := range pkg.Syntax {
 		fileCount++
 		structsInFile := 0
+		annotations := newTypeAnnotations(pkg.Fset, file)
+		if !cfg.IncludeGenerated && generatedByOthers(file) {
+			logger.Verbose("  File %d: skipped, generated by another tool", fileCount)
+			continue
+		}
+
+		mergeGetters(getters, ParseGetters(file, pkg.TypesInfo, true))
 
 		for _, decl := range file.Decls {
 			if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.TYPE {
@@ -71,12 +83,13 @@ func LoadExternalPackage(importPath, alias string) (map[string]types.SourceStruc
 							structsInFile++
 							totalStructs++
 
-							sourceStruct := ParseStruct(structType)
+							sourceStruct := ParseStruct(structType, pkg.TypesInfo)
 							sourceStruct.Name = typeSpec.Name.Name
 							sourceStruct.IsExternal = true
 							sourceStruct.ImportPath = importPath
 							sourceStruct.Alias = alias
 							sourceStruct.Package = alias
+							sourceStruct.Targets = ParseSourceList(annotations.directive(typeSpec, "to"))
 
 							// Store with alias prefix
 							key := alias + "." + typeSpec.Name.Name
@@ -105,6 +118,35 @@ func LoadExternalPackage(importPath, alias string) (map[string]types.SourceStruc
 		return nil, fmt.Errorf("no structs found in package: %s", importPath)
 	}
 
+	attachGetters(sources, getters)
+
 	logger.Verbose("Successfully loaded %d structs from %s", totalStructs, importPath)
 	return sources, nil
 }
+
+// ResolvePackage lists the files of a package without type checking it, returning its name.
+// It checks an import path resolves, from dir or the working directory when dir is empty.
+func ResolvePackage(dir, pattern string, cfg *config.Config) (string, error) {
+	pkgs, err := packages.Load(packagesConfig(cfg, dir, packages.NeedName|packages.NeedFiles), pattern)
+	if err != nil {
+		return "", err
+	}
+	if len(pkgs) == 0 {
+		return "", fmt.Errorf("no packages found for: %s", pattern)
+	}
+	if len(pkgs[0].Errors) > 0 {
+		return "", pkgs[0].Errors[0]
+	}
+	return pkgs[0].Name, nil
+}
+
+// packagesConfig returns the configuration loading packages from a directory, the working
+// directory when empty, with the build tags, flags and environment of the configuration
+func packagesConfig(cfg *config.Config, dir string, mode packages.LoadMode) *packages.Config {
+	return &packages.Config{
+		Mode:       mode,
+		Dir:        dir,
+		BuildFlags: cfg.Load.BuildFlags(),
+		Env:        cfg.Load.Environ(),
+	}
+}
@@ -4,18 +4,23 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/cache"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/naming"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
 	"golang.org/x/tools/go/packages"
 )
 
 // ParsePackage parses the main package and external packages
 func ParsePackage(
-	pkgPath string, cfg *config.Config,
+	pkgPath string, cfg *config.Config, c *cache.Cache,
 ) (
 	[]types.DTOMapping,
 	map[string]types.SourceStruct,
@@ -23,73 +28,121 @@ func ParsePackage(
 	string,
 	error,
 ) {
-	// Parse main package using go/packages
-	logger.Verbose("Parsing main package: %s", pkgPath)
-	dtos, sources, functions, pkgName, err := parsePackageWithGoPackages(pkgPath, "", "", false, cfg)
-	if err != nil {
-		return nil, nil, nil, "", err
-	}
-
-	logger.Verbose("Main package parsed: %d DTOs, %d sources, %d functions", len(dtos), len(sources), len(functions))
-
-	// Parse external packages
+	// External packages are parsed first, their structs may declare DTOs of the main package as targets
 	if len(cfg.ExternalPackages) > 0 {
 		logger.Verbose("Loading %d external packages...", len(cfg.ExternalPackages))
 	}
 
+	externalSources := make(map[string]types.SourceStruct)
 	for i, extPkg := range cfg.ExternalPackages {
 		logger.Verbose("[%d/%d] Loading external package: %s", i+1, len(cfg.ExternalPackages), extPkg.ImportPath)
 
-		alias := extPkg.Alias
-		if alias == "" {
-			parts := strings.Split(extPkg.ImportPath, "/")
-			alias = parts[len(parts)-1]
+		alias := extPkg.Name()
+		if alias != extPkg.Alias {
 			logger.Debug("  Using default alias: %s", alias)
 		}
 
-		var extSources map[string]types.SourceStruct
-		var parseErr error
-
-		// Try local path first if provided (for development)
-		if extPkg.LocalPath != "" {
-			localPath := extPkg.LocalPath
-			if !filepath.IsAbs(localPath) {
-				localPath = filepath.Join(pkgPath, localPath)
-			}
-
-			logger.Verbose("  Loading from local path: %s", localPath)
-			_, extSources, _, _, parseErr = parsePackageWithGoPackages(localPath, alias, extPkg.ImportPath, true, cfg)
-		}
-
-		// Load from module cache if local path not available or failed
-		if extPkg.LocalPath == "" || parseErr != nil {
-			if parseErr != nil {
-				logger.Verbose("  Local path failed, trying module cache")
-			} else {
-				logger.Verbose("  Loading from module cache")
-			}
-			extSources, parseErr = LoadExternalPackage(extPkg.ImportPath, alias)
+		extSources, err := loadExternalSources(pkgPath, extPkg, alias, cfg, c)
+		if err != nil {
+			return nil, nil, nil, "", err
 		}
 
-		if parseErr != nil {
-			return nil, nil, nil, "", fmt.Errorf("loading external package %s: %w", extPkg.ImportPath, parseErr)
-		}
-
-		// Merge sources
 		for k, v := range extSources {
-			sources[k] = v
-			logger.Debug("  Added external struct: %s", k)
+			externalSources[k] = v
 		}
 
 		logger.Verbose("  Loaded %d structs from %s", len(extSources), extPkg.ImportPath)
 	}
 
+	converterFunctions, err := loadConverterPackages(pkgPath, cfg, c)
+	if err != nil {
+		return nil, nil, nil, "", err
+	}
+
+	// Parse main package using go/packages
+	logger.Verbose("Parsing main package: %s", pkgPath)
+	dtos, sources, functions, pkgName, err := parseMainPackage(pkgPath, cfg, collectTargets(externalSources), c)
+	if err != nil {
+		return nil, nil, nil, "", err
+	}
+
+	logger.Verbose("Main package parsed: %d DTOs, %d sources, %d functions", len(dtos), len(sources), len(functions))
+	checkMappings(cfg.Mappings, dtos)
+	ignoreFields(cfg, dtos)
+	if err := resolveCustomNames(pkgPath, cfg, dtos); err != nil {
+		return nil, nil, nil, "", err
+	}
+
+	// outputPattern names the output files after the DTOs
+	names := make([]string, len(dtos))
+	for i, dto := range dtos {
+		names[i] = dto.Name
+	}
+	if err := cfg.RenderOutputNames(names, naming.TemplateFuncs(naming.NewInitialisms(cfg.Initialisms))); err != nil {
+		return nil, nil, nil, "", err
+	}
+
+	for name, fn := range converterFunctions {
+		functions[name] = fn
+	}
+	addImportedConverters(cfg, dtos)
+
+	// Merge sources
+	for k, v := range externalSources {
+		sources[k] = v
+		logger.Debug("  Added external struct: %s", k)
+	}
+	resolveSourceRefs(cfg, dtos, sources)
+	applyTypeConverters(cfg, dtos, sources)
+
 	return dtos, sources, functions, pkgName, nil
 }
 
-// parsePackageWithGoPackages uses go/packages to parse a package
+// parseExternalPackage parses the structs of an external package, from its local path, given or found
+// in the Go workspace, when it has one
+func parseExternalPackage(
+	pkgPath string, extPkg config.ExternalPackage, alias string, cfg *config.Config,
+) (map[string]types.SourceStruct, error) {
+	var extSources map[string]types.SourceStruct
+	var parseErr error
+
+	// Try the local path or the Go workspace first (for development)
+	localPath := LocalPackageDir(pkgPath, extPkg)
+	if localPath != "" {
+		logger.Verbose("  Loading from local path: %s", localPath)
+		_, extSources, _, _, parseErr = parsePackageWithGoPackages(localPath, alias, extPkg.ImportPath, true, cfg, nil)
+	}
+
+	// Load from module cache if local path not available or failed
+	if localPath == "" || parseErr != nil {
+		if parseErr != nil {
+			logger.Verbose("  Local path failed, trying module cache")
+		} else if version := ModuleVersion(pkgPath, extPkg.ImportPath); version != "" {
+			logger.Verbose("  Loading from module cache (%s)", version)
+		} else {
+			logger.Verbose("  Loading from module cache")
+		}
+		extSources, parseErr = LoadExternalPackage(extPkg.ImportPath, alias, cfg)
+	}
+
+	if parseErr != nil {
+		return nil, fmt.Errorf("loading external package %s: %w", extPkg.ImportPath, parseErr)
+	}
+
+	// Structs left out by include and exclude are neither validated nor cached
+	for key, source := range extSources {
+		if !extPkg.Includes(source.Name) {
+			logger.Debug("  Skipping external struct: %s", key)
+			delete(extSources, key)
+		}
+	}
+	return extSources, nil
+}
+
+// parsePackageWithGoPackages uses go/packages to parse a package.
+// Targets lists, by DTO name, the sources of other packages declaring the DTO with automapper:to.
 func parsePackageWithGoPackages(
-	pkgPath string, alias string, importPath string, isExternal bool, cfg *config.Config,
+	pkgPath string, alias string, importPath string, isExternal bool, cfg *config.Config, targets map[string][]string,
 ) (
 	[]types.DTOMapping,
 	map[string]types.SourceStruct,
@@ -100,14 +153,13 @@ func parsePackageWithGoPackages(
 	logger.Debug("Parsing package with go/packages: %s (external: %v)", pkgPath, isExternal)
 
 	// Configure package loading
-	pkgCfg := &packages.Config{
-		Mode: packages.NeedName |
-			packages.NeedFiles |
-			packages.NeedSyntax |
-			packages.NeedTypes |
-			packages.NeedTypesInfo,
-		Dir: pkgPath,
-	}
+	pkgCfg := packagesConfig(cfg, pkgPath, packages.NeedName|
+		packages.NeedFiles|
+		packages.NeedSyntax|
+		packages.NeedTypes|
+		packages.NeedTypesInfo)
+	// The test variant of the package holds its _test.go files too
+	pkgCfg.Tests = !isExternal && cfg.TestFiles
 
 	// Load the package - use "." to load the package in the current directory
 	logger.Debug("Invoking packages.Load for directory: %s", pkgPath)
@@ -120,8 +172,12 @@ func parsePackageWithGoPackages(
 		return nil, nil, nil, "", fmt.Errorf("no packages found in: %s", pkgPath)
 	}
 
-	// Use the first package (there should typically be only one when loading ".")
-	pkg := pkgs[0]
+	pkg := packageVariant(pkgs, pkgCfg.Tests)
+
+	// Structs declared twice fail type checking, their declarations are reported instead
+	if err := checkDuplicateStructs(pkg.Fset, pkg.Syntax); err != nil {
+		return nil, nil, nil, "", err
+	}
 
 	// Check for errors
 	if len(pkg.Errors) > 0 {
@@ -138,6 +194,8 @@ func parsePackageWithGoPackages(
 	dtos := []types.DTOMapping{}
 	sources := make(map[string]types.SourceStruct)
 	functions := make(map[string]types.FunctionInfo)
+	getters := make(map[string]map[string]types.FieldTypeInfo)
+	testStructs := make(map[string]bool) // structs of _test.go files, by name
 	pkgName := pkg.Name
 
 	if importPath == "" {
@@ -148,6 +206,13 @@ func parsePackageWithGoPackages(
 		alias = pkg.Name
 	}
 
+	type parsedFile struct {
+		file        *ast.File
+		baseName    string
+		annotations *typeAnnotations
+	}
+	var parsedFiles []parsedFile
+
 	fileCount := 0
 	totalStructs := 0
 	totalFunctions := 0
@@ -168,7 +233,7 @@ func parsePackageWithGoPackages(
 		}
 		fileName := fileList[i]
 		baseName := filepath.Base(fileName)
-		if !strings.HasSuffix(baseName, "_test.go") && baseName != cfg.Output {
+		if !skipFile(pkg.Syntax[i], baseName, cfg) {
 			totalFiles++
 		}
 	}
@@ -183,8 +248,8 @@ func parsePackageWithGoPackages(
 		fileName := fileList[i]
 		baseName := filepath.Base(fileName)
 
-		// Skip test files and the output file
-		if strings.HasSuffix(baseName, "_test.go") || baseName == cfg.Output {
+		// Skip test files, unless testFiles parses them, and the output files
+		if skipFile(file, baseName, cfg) {
 			logger.Debug("  Skipping file: %s", baseName)
 			continue
 		}
@@ -195,21 +260,35 @@ func parsePackageWithGoPackages(
 		logger.Debug("  [%d/%d] Parsing file: %s", fileCount, totalFiles, baseName)
 
 		structsInFile := 0
+		annotations := newTypeAnnotations(pkg.Fset, file)
+		if !isExternal {
+			annotations.warnIgnored()
+		}
+		// Structs of files generated by other tools, such as mocks, aren't sources
+		skipSources := !cfg.IncludeGenerated && generatedByOthers(file)
+		if skipSources {
+			logger.Verbose("    Skipping the structs of %s, generated by another tool", baseName)
+		}
 
 		// Extract structs from the file
 		for _, decl := range file.Decls {
 			if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.TYPE {
 				for _, spec := range genDecl.Specs {
-					if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+					if typeSpec, ok := spec.(*ast.TypeSpec); ok && !skipSources {
 						if structType, ok := typeSpec.Type.(*ast.StructType); ok {
 							structsInFile++
 							totalStructs++
 
-							sourceStruct := ParseStruct(structType)
+							sourceStruct := ParseStruct(structType, pkg.TypesInfo)
 							sourceStruct.Name = typeSpec.Name.Name
 							sourceStruct.IsExternal = isExternal
 							sourceStruct.ImportPath = importPath
 							sourceStruct.Alias = alias
+							sourceStruct.Targets = ParseSourceList(annotations.directive(typeSpec, "to"))
+
+							if isTestFile(baseName) {
+								testStructs[typeSpec.Name.Name] = true
+							}
 
 							if isExternal {
 								sourceStruct.Package = alias
@@ -231,9 +310,12 @@ func parsePackageWithGoPackages(
 			logger.Verbose("    Found %d structs in %s", structsInFile, baseName)
 		}
 
+		// Getters may be declared in a different file than their struct
+		mergeGetters(getters, ParseGetters(file, pkg.TypesInfo, isExternal))
+
 		// Parse functions (only in non-external packages)
 		if !isExternal {
-			fileFunctions := ParseFunctions(file)
+			fileFunctions := ParseFunctions(file, pkg.TypesInfo)
 			for name, fn := range fileFunctions {
 				functions[name] = fn
 				totalFunctions++
@@ -241,33 +323,94 @@ func parsePackageWithGoPackages(
 			}
 		}
 
-		// Parse DTOs (only in non-external packages)
-		if !isExternal {
+		parsedFiles = append(parsedFiles, parsedFile{file: file, baseName: baseName, annotations: annotations})
+	}
+
+	// DTOs are parsed once every struct is known, so structs of any file can declare them as targets
+	if !isExternal {
+		aliases := typeAliases(pkg.Syntax, pkg.TypesInfo, pkg.PkgPath)
+		for name, sourceNames := range collectTargets(sources) {
+			targets[name] = append(targets[name], sourceNames...)
+		}
+		for name, sourceNames := range targets {
+			if _, ok := sources[name]; !ok {
+				logger.Verbose("  Target %s of %v is not a struct of this package, skipped", name, sourceNames)
+			}
+		}
+
+		for _, parsed := range parsedFiles {
+			file, baseName := parsed.file, parsed.baseName
 			dtoCount := 0
 			for _, decl := range file.Decls {
 				if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.TYPE {
 					for _, spec := range genDecl.Specs {
 						if typeSpec, ok := spec.(*ast.TypeSpec); ok {
-							var annotation string
-							if genDecl.Doc != nil {
-								annotation = ExtractAnnotation(genDecl.Doc)
-							}
-							if annotation == "" && typeSpec.Doc != nil {
-								annotation = ExtractAnnotation(typeSpec.Doc)
+							// The configuration may declare the DTO instead of its annotations
+							mapping, mapped := cfg.MappingOf(typeSpec.Name.Name)
+							directive := func(key string) string {
+								if value := parsed.annotations.directive(typeSpec, key); value != "" {
+									return value
+								}
+								return mapping.Directive(key)
 							}
 
-							if annotation != "" {
+							annotation := directive("from")
+							priority := directive("sources")
+							via := directive("via")
+							targetOf := targets[typeSpec.Name.Name]
+
+							if annotation != "" || priority != "" || via != "" || len(targetOf) > 0 || mapped {
 								dtoCount++
 								if structType, ok := typeSpec.Type.(*ast.StructType); ok {
 									dto := types.DTOMapping{
-										Name:        typeSpec.Name.Name,
-										Sources:     ParseSourceList(annotation),
-										Fields:      ParseFields(structType),
-										PackageName: pkgName,
+										Name:            typeSpec.Name.Name,
+										Sources:         ParseSourceList(annotation),
+										Fields:          ParseFields(structType, pkg.TypesInfo),
+										PackageName:     pkgName,
+										Modes:           ParseSourceList(directive("mode")),
+										PrioritySources: ParsePriorityList(priority),
+										Transform:       directive("transform"),
+										Via:             via,
+										Style:           directive("style"),
+									}
+									setPositions(&dto, typeSpec, structType, pkg.Fset)
+									applyFieldOverrides(&dto, mapping.Fields)
+									resolveImportedConverters(&dto, file, pkg.TypesInfo, cfg, functions)
+									// Mapping through a DTO requires a mapping from it
+									if via != "" && !slices.Contains(dto.Sources, via) {
+										dto.Sources = append(dto.Sources, via)
 									}
+									// Sources declaring the DTO as their target map like listed ones
+									for _, sourceName := range targetOf {
+										if !slices.Contains(dto.Sources, sourceName) {
+											dto.Sources = append(dto.Sources, sourceName)
+										}
+									}
+									resolveAliasRefs(&dto, aliases)
+									// Mappings involving a struct of a _test.go file only build in tests
+									dto.TestOnly = isTestFile(baseName) ||
+										slices.ContainsFunc(dto.Sources, func(name string) bool { return testStructs[name] })
 									dtos = append(dtos, dto)
 									logger.Verbose("    Found DTO: %s <- %v (%d fields)",
 										dto.Name, dto.Sources, len(dto.Fields))
+									if len(dto.Modes) > 0 {
+										logger.Debug("      Modes: %v", dto.Modes)
+									}
+									if len(dto.PrioritySources) > 0 {
+										logger.Debug("      Priority: %v", dto.PrioritySources)
+									}
+									if dto.Transform != "" {
+										logger.Debug("      Transform: %s", dto.Transform)
+									}
+									if dto.Via != "" {
+										logger.Debug("      Via: %s", dto.Via)
+									}
+									if len(targetOf) > 0 {
+										logger.Debug("      Declared by: %v", targetOf)
+									}
+									if dto.Style != "" {
+										logger.Debug("      Style: %s", dto.Style)
+									}
 
 									// Log field details in debug mode
 									if logger.IsDebugEnabled() {
@@ -301,6 +444,168 @@ func parsePackageWithGoPackages(
 		}
 	}
 
+	attachGetters(sources, getters)
+
 	logger.Debug("Completed parsing package: %d DTOs, %d sources, %d functions", len(dtos), len(sources), len(functions))
 	return dtos, sources, functions, pkgName, nil
 }
+
+// isTestFile reports whether a file is a _test.go file
+func isTestFile(baseName string) bool {
+	return strings.HasSuffix(baseName, "_test.go")
+}
+
+// skipFile reports whether a file of the package is left out: the output files, and the _test.go
+// files unless testFiles parses them, those automapper-gen writes, such as fuzz tests, included
+func skipFile(file *ast.File, baseName string, cfg *config.Config) bool {
+	if isTestFile(baseName) {
+		return !cfg.TestFiles || hasAutomapperHeader(file)
+	}
+	return cfg.IsOutputFile(baseName)
+}
+
+// packageVariant picks the package loaded for a directory among the packages go/packages returns:
+// its test variant, compiled with its _test.go files, when tests are loaded and it has some, or
+// else the package itself. External test packages, package x_test, are left out.
+func packageVariant(pkgs []*packages.Package, tests bool) *packages.Package {
+	if tests {
+		for _, pkg := range pkgs {
+			if strings.HasSuffix(pkg.ID, ".test]") && !strings.HasSuffix(pkg.Name, "_test") {
+				return pkg
+			}
+		}
+	}
+	for _, pkg := range pkgs {
+		if !strings.Contains(pkg.ID, " ") && !strings.HasSuffix(pkg.ID, ".test") {
+			return pkg
+		}
+	}
+	return pkgs[0]
+}
+
+// collectTargets indexes the sources declaring DTOs with automapper:to by DTO name
+func collectTargets(sources map[string]types.SourceStruct) map[string][]string {
+	targets := make(map[string][]string)
+	for key, source := range sources {
+		for _, target := range source.Targets {
+			targets[target] = append(targets[target], key)
+		}
+	}
+	// Map iteration order is random, keep the generated output stable
+	for _, sourceNames := range targets {
+		slices.Sort(sourceNames)
+	}
+	return targets
+}
+
+// setPositions records where a DTO and its fields are declared
+func setPositions(dto *types.DTOMapping, typeSpec *ast.TypeSpec, structType *ast.StructType, fset *token.FileSet) {
+	position := func(pos token.Pos) types.Position {
+		p := fset.Position(pos)
+		return types.Position{File: p.Filename, Line: p.Line, Column: p.Column}
+	}
+
+	dto.Position = position(typeSpec.Name.Pos())
+	fieldPositions := make(map[string]token.Pos)
+	for _, field := range structType.Fields.List {
+		for _, name := range field.Names {
+			fieldPositions[name.Name] = name.Pos()
+		}
+	}
+	for i, field := range dto.Fields {
+		if pos, ok := fieldPositions[field.Name]; ok {
+			dto.Fields[i].Position = position(pos)
+		}
+	}
+}
+
+// ignoreFields skips the DTO fields matching the ignoreFields patterns of the configuration
+func ignoreFields(cfg *config.Config, dtos []types.DTOMapping) {
+	for _, dto := range dtos {
+		for i, field := range dto.Fields {
+			if !field.Ignore && cfg.IsIgnoredField(field.Name, field.JSONName) {
+				dto.Fields[i].Ignore = true
+				logger.Debug("  Field %s.%s ignored by ignoreFields", dto.Name, field.Name)
+			}
+		}
+	}
+}
+
+// resolveCustomNames runs the custom field name transforms of the DTOs once on every name they rewrite,
+// recording the results in the configuration for the matchers
+func resolveCustomNames(pkgPath string, cfg *config.Config, dtos []types.DTOMapping) error {
+	names := make(map[string][]string)
+	for _, dto := range dtos {
+		m := matcher.New(cfg, dto)
+		if !config.IsCustomTransform(m.Transform()) {
+			continue
+		}
+		for _, field := range dto.Fields {
+			if name := m.TransformInput(field); !slices.Contains(names[m.Transform()], name) {
+				names[m.Transform()] = append(names[m.Transform()], name)
+			}
+		}
+	}
+
+	for _, transform := range slices.Sorted(maps.Keys(names)) {
+		rewritten, err := naming.Custom(pkgPath, transform, names[transform])
+		if err != nil {
+			return err
+		}
+		cfg.SetCustomNames(transform, rewritten)
+	}
+	return nil
+}
+
+// applyTypeConverters tags the DTO fields mapped from a field of a type pair covered by a type converter
+// with its converter, leaving fields with their own converter or another kind of mapping alone
+func applyTypeConverters(cfg *config.Config, dtos []types.DTOMapping, sources map[string]types.SourceStruct) {
+	if len(cfg.TypeConverters) == 0 {
+		return
+	}
+	for _, dto := range dtos {
+		m := matcher.New(cfg, dto)
+		for i, field := range dto.Fields {
+			if field.Ignore || field.ConverterTag != "" || field.NestedDTO != "" || len(field.TypeCases) > 0 || field.Redact != "" {
+				continue
+			}
+			for _, sourceName := range dto.Sources {
+				_, sourceField, ok := m.Resolve(field, sources[sourceName])
+				if !ok {
+					continue
+				}
+				if converter := cfg.TypeConverterFor(sourceField.Type, field.Type); converter != "" {
+					dto.Fields[i].ConverterTag = converter
+					logger.Debug("  Field %s.%s converted by type converter %s", dto.Name, field.Name, converter)
+					break
+				}
+			}
+		}
+	}
+}
+
+// checkMappings warns about the configuration mappings that don't match a struct or its fields
+func checkMappings(mappings []config.Mapping, dtos []types.DTOMapping) {
+	for _, mapping := range mappings {
+		i := slices.IndexFunc(dtos, func(dto types.DTOMapping) bool { return dto.Name == mapping.DTO })
+		if i < 0 {
+			logger.Warning("Mapping of %s ignored: no struct %s in the package", mapping.DTO, mapping.DTO)
+			continue
+		}
+		for _, name := range slices.Sorted(maps.Keys(mapping.Fields)) {
+			if !slices.ContainsFunc(dtos[i].Fields, func(field types.FieldInfo) bool { return field.Name == name }) {
+				logger.Warning("Mapping of %s: field %s not found in the DTO", mapping.DTO, name)
+			}
+		}
+	}
+}
+
+// applyFieldOverrides applies the automapper tags a configuration mapping declares for the DTO fields
+// on top of their struct tags
+func applyFieldOverrides(dto *types.DTOMapping, overrides map[string]string) {
+	for i := range dto.Fields {
+		if tag, ok := overrides[dto.Fields[i].Name]; ok {
+			applyAutomapperTag(tag, &dto.Fields[i])
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"go/ast"
+	"go/token"
+	gotypes "go/types"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+)
+
+// sourceRefs resolves the references of annotations to sources: the keys of the sources, Type in the
+// main package and alias.Type in external ones, or a type qualified by its full import path such
+// as example.com/service/models.User
+type sourceRefs struct {
+	keys    map[string]string   // source keys by import path and type name
+	sharing map[string][]string // import paths of the external packages by default alias, if shared
+	aliases map[string]string   // import paths by the default alias the package is named after
+	sources map[string]types.SourceStruct
+}
+
+// newSourceRefs indexes the sources by import path and type name
+func newSourceRefs(cfg *config.Config, sources map[string]types.SourceStruct) *sourceRefs {
+	r := &sourceRefs{
+		keys:    make(map[string]string, len(sources)),
+		sharing: make(map[string][]string),
+		aliases: make(map[string]string),
+		sources: sources,
+	}
+	for key, source := range sources {
+		r.keys[source.ImportPath+"."+source.Name] = key
+	}
+	for _, extPkg := range cfg.ExternalPackages {
+		alias := config.DefaultAlias(extPkg.ImportPath)
+		r.sharing[alias] = append(r.sharing[alias], extPkg.ImportPath)
+		if extPkg.Alias == "" {
+			r.aliases[extPkg.Name()] = extPkg.ImportPath
+		}
+	}
+	return r
+}
+
+// resolve returns the key of the source a reference names, the reference itself when it names none
+// so validation reports it
+func (r *sourceRefs) resolve(dto, ref string) string {
+	if _, ok := r.sources[ref]; ok {
+		r.warnShared(dto, ref)
+		return ref
+	}
+	slash := strings.LastIndex(ref, "/")
+	dot := strings.LastIndex(ref, ".")
+	if slash < 0 || dot < slash {
+		return ref
+	}
+	if key, ok := r.keys[ref]; ok {
+		logger.Debug("  %s: %s resolved to %s", dto, ref, key)
+		return key
+	}
+	return ref
+}
+
+// warnShared warns about a reference through the default alias of a package when packages of other
+// import paths share that last element, the reference picking the first one listed
+func (r *sourceRefs) warnShared(dto, ref string) {
+	alias, name, ok := strings.Cut(ref, ".")
+	if !ok {
+		return
+	}
+	importPath, ok := r.aliases[alias]
+	if !ok || len(r.sharing[alias]) < 2 {
+		return
+	}
+	logger.Warning("%s: %s refers to %s.%s, other external packages are also named %s, refer to it as %s.%s or set its alias",
+		dto, ref, importPath, name, alias, importPath, name)
+}
+
+// resolveSourceRefs rewrites the sources of the DTOs given by import path to the keys of the sources
+func resolveSourceRefs(cfg *config.Config, dtos []types.DTOMapping, sources map[string]types.SourceStruct) {
+	refs := newSourceRefs(cfg, sources)
+	for i := range dtos {
+		dto := &dtos[i]
+		for j, ref := range dto.Sources {
+			dto.Sources[j] = refs.resolve(dto.Name, ref)
+		}
+		for j, ref := range dto.PrioritySources {
+			dto.PrioritySources[j] = refs.resolve(dto.Name, ref)
+		}
+	}
+}
+
+// typeAliases returns the structs the type aliases of a package denote by alias name, e.g. User for
+// type User = db.UserDB: the name of the struct when the package declares it, otherwise the struct
+// qualified by its import path, resolved to a source of an external package by resolveSourceRefs.
+// Aliases of aliases resolve to the struct at the end of the chain.
+func typeAliases(files []*ast.File, info *gotypes.Info, pkgPath string) map[string]string {
+	aliases := make(map[string]string)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok || !typeSpec.Assign.IsValid() {
+					continue
+				}
+				obj, ok := info.Defs[typeSpec.Name].(*gotypes.TypeName)
+				if !ok {
+					continue
+				}
+				named, ok := gotypes.Unalias(obj.Type()).(*gotypes.Named)
+				if !ok || named.TypeArgs().Len() > 0 || named.Obj().Pkg() == nil {
+					continue
+				}
+				if _, ok := named.Underlying().(*gotypes.Struct); !ok {
+					continue
+				}
+				target := named.Obj().Name()
+				if named.Obj().Pkg().Path() != pkgPath {
+					target = named.Obj().Pkg().Path() + "." + target
+				}
+				aliases[typeSpec.Name.Name] = target
+			}
+		}
+	}
+	return aliases
+}
+
+// resolveAliasRefs rewrites the sources of a DTO named by a type alias to the struct the alias denotes
+func resolveAliasRefs(dto *types.DTOMapping, aliases map[string]string) {
+	resolve := func(ref string) string {
+		if target, ok := aliases[ref]; ok {
+			logger.Debug("      %s: alias %s resolved to %s", dto.Name, ref, target)
+			return target
+		}
+		return ref
+	}
+	for i, ref := range dto.Sources {
+		dto.Sources[i] = resolve(ref)
+	}
+	for i, ref := range dto.PrioritySources {
+		dto.PrioritySources[i] = resolve(ref)
+	}
+	if dto.Via != "" {
+		dto.Via = resolve(dto.Via)
+	}
+}
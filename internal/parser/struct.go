@@ -2,32 +2,41 @@ package parser
 
 import (
 	"go/ast"
+	gotypes "go/types"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
 )
 
-// ParseStruct extracts field information from a struct type
-func ParseStruct(structType *ast.StructType) types.SourceStruct {
+// ParseStruct extracts field information from a struct type, identifying the field types with the
+// type information of its package when given, which also finds the fields promoted from embedded structs
+func ParseStruct(structType *ast.StructType, info *gotypes.Info) types.SourceStruct {
 	s := types.SourceStruct{
 		Fields: make(map[string]types.FieldTypeInfo),
 	}
 
+	var embedded []*ast.Field
 	for _, field := range structType.Fields.List {
 		if len(field.Names) == 0 {
+			embedded = append(embedded, field)
 			continue
 		}
 
 		fieldName := field.Names[0].Name
 		typeInfo := extractTypeInfo(field.Type)
+		typeInfo.BaseIdentity = identify(info, sourceBaseExpr(field.Type))
 		s.Fields[fieldName] = typeInfo
 	}
+	s.PromotedFrom = addPromotedFields(s.Fields, embedded, info)
 
 	return s
 }
 
-// ParseFields extracts field information including tags
-func ParseFields(structType *ast.StructType) []types.FieldInfo {
+// ParseFields extracts field information including tags, identifying the field types with the
+// type information of its package when given
+func ParseFields(structType *ast.StructType, info *gotypes.Info) []types.FieldInfo {
 	fields := []types.FieldInfo{}
 
 	for _, field := range structType.Fields.List {
@@ -36,19 +45,22 @@ func ParseFields(structType *ast.StructType) []types.FieldInfo {
 		}
 
 		fieldInfo := types.FieldInfo{
-			Name: field.Names[0].Name,
-			Type: exprToString(field.Type),
+			Name:         field.Names[0].Name,
+			Type:         exprToString(field.Type),
+			BaseIdentity: identify(info, dtoBaseExpr(field.Type)),
 		}
 
 		if field.Tag != nil {
-			tag := field.Tag.Value
-			tag = strings.Trim(tag, "`")
-			fieldInfo.Tag = tag
-
-			if strings.Contains(tag, "automapper:") {
-				fieldInfo.ConverterTag, fieldInfo.FieldTag, fieldInfo.NestedDTO, fieldInfo.Ignore = parseAutomapperTag(tag)
+			// Tags may be raw or interpreted string literals, the latter escaping their quotes
+			tag, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				tag = strings.Trim(field.Tag.Value, "`")
 			}
+			fieldInfo.Tag = tag
+			fieldInfo.JSONName = parseJSONName(tag)
+			parseAutomapperTag(tag, &fieldInfo)
 		}
+		applyFieldComments(field, &fieldInfo)
 
 		fields = append(fields, fieldInfo)
 	}
@@ -56,42 +68,113 @@ func ParseFields(structType *ast.StructType) []types.FieldInfo {
 	return fields
 }
 
-// parseAutomapperTag parses the automapper struct tag
-func parseAutomapperTag(tag string) (converter, field, nestedDTO string, ignore bool) {
-	start := strings.Index(tag, `automapper:"`)
-	if start == -1 {
-		return
-	}
-	start += len(`automapper:"`)
-	end := strings.Index(tag[start:], `"`)
-	if end == -1 {
-		return
+// parseAutomapperTag parses the automapper struct tag into the field info, looked up like
+// reflect.StructTag does so escaped quotes in the tag values don't end them
+func parseAutomapperTag(tag string, fieldInfo *types.FieldInfo) {
+	if value, ok := reflect.StructTag(tag).Lookup("automapper"); ok {
+		applyAutomapperTag(value, fieldInfo)
 	}
+}
 
-	automapperTag := tag[start : start+end]
+// applyFieldComments applies the automapper comments above or beside a field, e.g.
+// //automapper:converter=TimeToString, after its struct tag
+func applyFieldComments(field *ast.Field, fieldInfo *types.FieldInfo) {
+	for _, doc := range []*ast.CommentGroup{field.Doc, field.Comment} {
+		if doc == nil {
+			continue
+		}
+		for _, comment := range doc.List {
+			if text, ok := directiveText(comment.Text); ok {
+				applyAutomapperTag(strings.TrimSpace(text), fieldInfo)
+			}
+		}
+	}
+}
 
+// applyAutomapperTag applies the value of an automapper struct tag, e.g. converter=RoleEnum, to the field info
+func applyAutomapperTag(automapperTag string, fieldInfo *types.FieldInfo) {
 	if automapperTag == "-" {
-		ignore = true
+		fieldInfo.Ignore = true
 		return
 	}
 
-	parts := strings.SplitSeq(automapperTag, ",")
-	for part := range parts {
+	lastKey := ""
+	for _, part := range splitTagOptions(automapperTag) {
 		kv := strings.SplitN(part, "=", 2)
-		if len(kv) == 2 {
-			key := strings.TrimSpace(kv[0])
-			value := strings.TrimSpace(kv[1])
-
-			switch key {
-			case "converter":
-				converter = value
-			case "field":
-				field = value
-			case "dto":
-				nestedDTO = value
+		if len(kv) != 2 {
+			if strings.TrimSpace(part) == "redact" {
+				fieldInfo.Redact = types.RedactFull
+				lastKey = "redact"
+				continue
+			}
+
+			// Type cases are comma-separated themselves: types=A:ADTO,B:BDTO
+			if lastKey == "types" {
+				fieldInfo.TypeCases = append(fieldInfo.TypeCases, parseTypeCase(part))
 			}
+			continue
+		}
+
+		key := strings.TrimSpace(kv[0])
+		value := unquoteTagValue(strings.TrimSpace(kv[1]))
+		lastKey = key
+
+		switch key {
+		case "converter":
+			fieldInfo.ConverterTag = value
+		case "field":
+			fieldInfo.FieldTag = value
+		case "dto":
+			fieldInfo.NestedDTO = value
+		case "types":
+			fieldInfo.TypeCases = append(fieldInfo.TypeCases, parseTypeCase(value))
+		case "redact":
+			fieldInfo.Redact = value
+		}
+	}
+}
+
+// splitTagOptions splits the options of an automapper tag at the commas outside single quotes, so
+// values may hold commas when quoted: field='a,b'. A backslash escapes a quote inside quotes.
+func splitTagOptions(tag string) []string {
+	var options []string
+	start, quoted := 0, false
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && quoted:
+			i++
+		case tag[i] == '\'':
+			quoted = !quoted
+		case tag[i] == ',' && !quoted:
+			options = append(options, tag[start:i])
+			start = i + 1
 		}
 	}
+	return append(options, tag[start:])
+}
 
-	return
+// unquoteTagValue removes the single quotes around a tag value, unescaping the quotes inside
+func unquoteTagValue(value string) string {
+	if len(value) < 2 || value[0] != '\'' || value[len(value)-1] != '\'' {
+		return value
+	}
+	return strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(value[1 : len(value)-1])
+}
+
+// parseTypeCase parses a SourceType:DTOType pair of a types tag
+func parseTypeCase(value string) types.TypeCase {
+	source, dto, _ := strings.Cut(strings.TrimSpace(value), ":")
+	return types.TypeCase{
+		Source: strings.TrimSpace(source),
+		DTO:    strings.TrimSpace(dto),
+	}
+}
+
+// parseJSONName extracts the field name from a json struct tag
+func parseJSONName(tag string) string {
+	name, _, _ := strings.Cut(reflect.StructTag(tag).Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
 }
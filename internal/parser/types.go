@@ -2,6 +2,7 @@ package parser
 
 import (
 	"go/ast"
+	goparser "go/parser"
 	"strings"
 
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
@@ -11,12 +12,14 @@ import (
 func extractTypeInfo(expr ast.Expr) types.FieldTypeInfo {
 	info := types.FieldTypeInfo{}
 
+	base := expr
 	switch t := expr.(type) {
 	case *ast.StarExpr:
 		// Pointer types: *T
 		info.IsPointer = true
 		info.BaseType = exprToString(t.X)
 		info.Type = "*" + info.BaseType
+		base = t.X
 
 	case *ast.ArrayType:
 		// Slice or array types: []T or [N]T
@@ -25,6 +28,7 @@ func extractTypeInfo(expr ast.Expr) types.FieldTypeInfo {
 			info.IsSlice = true
 			info.BaseType = exprToString(t.Elt)
 			info.Type = "[]" + info.BaseType
+			base = t.Elt
 		} else {
 			// Array: [N]T
 			info.Type = exprToString(expr)
@@ -62,9 +66,78 @@ func extractTypeInfo(expr ast.Expr) types.FieldTypeInfo {
 		info.Type = info.BaseType
 	}
 
+	describeTypeLiteral(&info, base)
 	return info
 }
 
+// describeTypeLiteral breaks the base type of a field down when it is a type literal: the key and
+// value of a map, the element and direction of a channel, the length and element of an array, the
+// parameters and results of a func
+func describeTypeLiteral(info *types.FieldTypeInfo, base ast.Expr) {
+	if paren, ok := base.(*ast.ParenExpr); ok {
+		base = paren.X
+	}
+	switch t := base.(type) {
+	case *ast.MapType:
+		info.Kind = types.KindMap
+		key, elem := extractTypeInfo(t.Key), extractTypeInfo(t.Value)
+		info.Key, info.Elem = &key, &elem
+	case *ast.ChanType:
+		info.Kind = types.KindChan
+		elem := extractTypeInfo(t.Value)
+		info.Elem = &elem
+		switch t.Dir {
+		case ast.SEND:
+			info.Dir = "chan<-"
+		case ast.RECV:
+			info.Dir = "<-chan"
+		default:
+			info.Dir = "chan"
+		}
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return
+		}
+		info.Kind = types.KindArray
+		info.Len = exprToString(t.Len)
+		elem := extractTypeInfo(t.Elt)
+		info.Elem = &elem
+	case *ast.FuncType:
+		info.Kind = types.KindFunc
+		info.Params = fieldListTypes(t.Params)
+		info.Results = fieldListTypes(t.Results)
+	case *ast.StructType:
+		info.Kind = types.KindStruct
+	case *ast.InterfaceType:
+		info.Kind = types.KindInterface
+	}
+}
+
+// fieldListTypes breaks down the type of every parameter or result of a func type, once per name
+func fieldListTypes(list *ast.FieldList) []types.FieldTypeInfo {
+	if list == nil {
+		return nil
+	}
+	var fields []types.FieldTypeInfo
+	for _, field := range list.List {
+		typeInfo := extractTypeInfo(field.Type)
+		for range max(len(field.Names), 1) {
+			fields = append(fields, typeInfo)
+		}
+	}
+	return fields
+}
+
+// ParseTypeString breaks a type written out, e.g. the type of a DTO field, down like the type of a
+// source field, reporting whether it parses
+func ParseTypeString(typeStr string) (types.FieldTypeInfo, bool) {
+	expr, err := goparser.ParseExpr(typeStr)
+	if err != nil {
+		return types.FieldTypeInfo{}, false
+	}
+	return extractTypeInfo(expr), true
+}
+
 // exprToString converts an AST expression to its string representation
 func exprToString(expr ast.Expr) string {
 	switch t := expr.(type) {
@@ -0,0 +1,180 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+	"golang.org/x/mod/modfile"
+)
+
+// localModule is a module available in a local directory
+type localModule struct {
+	path string // module path
+	dir  string
+}
+
+// LocalPackageDir returns the local directory an external package is parsed from: its localPath,
+// or else the directory of a local module providing it, if any: a module of the Go workspace of the
+// package, or without a workspace, the module of the package or a local replacement of its go.mod
+func LocalPackageDir(pkgPath string, extPkg config.ExternalPackage) string {
+	if extPkg.LocalPath != "" {
+		if filepath.IsAbs(extPkg.LocalPath) {
+			return extPkg.LocalPath
+		}
+		return filepath.Join(pkgPath, extPkg.LocalPath)
+	}
+
+	// The longest module path wins, nested modules being separate
+	dir, longest := "", 0
+	for _, module := range localModules(pkgPath) {
+		rest, ok := strings.CutPrefix(extPkg.ImportPath, module.path)
+		if ok && (rest == "" || rest[0] == '/') && len(module.path) > longest {
+			dir, longest = filepath.Join(module.dir, filepath.FromSlash(rest)), len(module.path)
+		}
+	}
+	return dir
+}
+
+// localModules returns the modules available in local directories to a package: those of its Go
+// workspace if any, or else its own module and the local replacements of its go.mod
+func localModules(pkgPath string) []localModule {
+	if modules, ok := workspaceModules(pkgPath); ok {
+		return modules
+	}
+	return goModModules(pkgPath)
+}
+
+// workspaceModules returns the modules of the go.work file applying to a package, like the go command
+// finds it: GOWORK if set, off disabling workspaces, or else the closest go.work in a parent directory.
+// Local replacements come first since they take precedence over the used modules. It reports whether
+// a workspace applies.
+func workspaceModules(pkgPath string) ([]localModule, bool) {
+	workFile := os.Getenv("GOWORK")
+	if workFile == "off" {
+		return nil, false
+	}
+	if workFile == "" {
+		workFile = findUp(pkgPath, "go.work")
+	}
+	if workFile == "" {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(workFile)
+	if err != nil {
+		logger.Debug("Reading %s: %v", workFile, err)
+		return nil, true
+	}
+	work, err := modfile.ParseWork(workFile, data, nil)
+	if err != nil {
+		logger.Debug("Parsing %s: %v", workFile, err)
+		return nil, true
+	}
+
+	root := filepath.Dir(workFile)
+	resolve := func(dir string) string {
+		if filepath.IsAbs(dir) {
+			return dir
+		}
+		return filepath.Join(root, dir)
+	}
+
+	var modules []localModule
+	for _, replace := range work.Replace {
+		// Replacements by another module version are left to the go command
+		if replace.New.Version == "" {
+			modules = append(modules, localModule{path: replace.Old.Path, dir: resolve(replace.New.Path)})
+		}
+	}
+	for _, use := range work.Use {
+		dir := resolve(use.Path)
+		goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err != nil {
+			logger.Debug("Reading the go.mod of workspace module %s: %v", dir, err)
+			continue
+		}
+		modules = append(modules, localModule{path: modfile.ModulePath(goMod), dir: dir})
+	}
+	return modules, true
+}
+
+// goModModules returns the module of a package and the modules its go.mod replaces by local
+// directories, the replacements first since they take precedence
+func goModModules(pkgPath string) []localModule {
+	mod := readGoMod(pkgPath)
+	if mod == nil || mod.Module == nil {
+		return nil
+	}
+
+	root := filepath.Dir(mod.Syntax.Name)
+	var modules []localModule
+	for _, replace := range mod.Replace {
+		// Replacements by another module version are left to the go command
+		if replace.New.Version == "" {
+			dir := replace.New.Path
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(root, dir)
+			}
+			modules = append(modules, localModule{path: replace.Old.Path, dir: dir})
+		}
+	}
+	return append(modules, localModule{path: mod.Module.Mod.Path, dir: root})
+}
+
+// ModuleVersion returns the version of the module providing an import path that the go.mod of
+// a package requires, if any
+func ModuleVersion(pkgPath, importPath string) string {
+	mod := readGoMod(pkgPath)
+	if mod == nil {
+		return ""
+	}
+	version, longest := "", 0
+	for _, require := range mod.Require {
+		rest, ok := strings.CutPrefix(importPath, require.Mod.Path)
+		if ok && (rest == "" || rest[0] == '/') && len(require.Mod.Path) > longest {
+			version, longest = require.Mod.Version, len(require.Mod.Path)
+		}
+	}
+	return version
+}
+
+// readGoMod parses the go.mod of the module of a package, nil when it has none
+func readGoMod(pkgPath string) *modfile.File {
+	goModFile := findUp(pkgPath, "go.mod")
+	if goModFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(goModFile)
+	if err != nil {
+		logger.Debug("Reading %s: %v", goModFile, err)
+		return nil
+	}
+	mod, err := modfile.Parse(goModFile, data, nil)
+	if err != nil {
+		logger.Debug("Parsing %s: %v", goModFile, err)
+		return nil
+	}
+	return mod
+}
+
+// findUp returns the file of the closest directory containing one, from a package up
+func findUp(pkgPath, name string) string {
+	dir, err := filepath.Abs(pkgPath)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,34 @@
+package types
+
+import "strings"
+
+// TypeIdentity identifies a type the way type checking sees it, with aliases resolved and packages
+// named by import path. It is empty for packages parsed without type information.
+type TypeIdentity struct {
+	Type       string `json:",omitempty"` // the type, e.g. example.com/app/db.Role
+	Underlying string `json:",omitempty"` // its underlying type, e.g. string
+}
+
+// IsKnown reports whether the type was identified
+func (t TypeIdentity) IsKnown() bool {
+	return t.Type != ""
+}
+
+// Identical reports whether both types were identified as the same type, however their package is named
+func (t TypeIdentity) Identical(other TypeIdentity) bool {
+	return t.IsKnown() && t.Type == other.Type
+}
+
+// Convertible reports whether both types were identified with identical underlying types,
+// so a value of one converts to the other
+func (t TypeIdentity) Convertible(other TypeIdentity) bool {
+	return t.IsKnown() && other.IsKnown() && t.Underlying == other.Underlying
+}
+
+// NeedsConversion reports whether a DTO field and a source field hold distinct types of the same
+// underlying type, which map through a conversion. Slices of such types don't convert.
+func NeedsConversion(field FieldInfo, source FieldTypeInfo) bool {
+	isSlice := source.IsSlice || strings.HasPrefix(strings.TrimPrefix(field.Type, "*"), "[]")
+	return !isSlice && !field.BaseIdentity.Identical(source.BaseIdentity) &&
+		field.BaseIdentity.Convertible(source.BaseIdentity)
+}
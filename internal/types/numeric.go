@@ -0,0 +1,75 @@
+package types
+
+import "strings"
+
+// numericType describes a numeric type by its kind and the range of its size, int and uint being
+// 32 or 64 bits wide depending on the platform
+type numericType struct {
+	kind     byte // i for signed integers, u for unsigned ones, f for floats, c for complex numbers
+	min, max int  // bits
+}
+
+var numericTypes = map[string]numericType{
+	"int":        {'i', 32, 64},
+	"int8":       {'i', 8, 8},
+	"int16":      {'i', 16, 16},
+	"int32":      {'i', 32, 32},
+	"rune":       {'i', 32, 32},
+	"int64":      {'i', 64, 64},
+	"uint":       {'u', 32, 64},
+	"uint8":      {'u', 8, 8},
+	"byte":       {'u', 8, 8},
+	"uint16":     {'u', 16, 16},
+	"uint32":     {'u', 32, 32},
+	"uint64":     {'u', 64, 64},
+	"float32":    {'f', 32, 32},
+	"float64":    {'f', 64, 64},
+	"complex64":  {'c', 64, 64},
+	"complex128": {'c', 128, 128},
+}
+
+// mantissaBits is the precision of the floats, the widest integers they hold exactly
+var mantissaBits = map[int]int{32: 24, 64: 53}
+
+// numericTypesOf returns the numeric types a DTO field and its source field hold, seeing through
+// named types when type checking identified them. Slices don't convert.
+func numericTypesOf(field FieldInfo, source FieldTypeInfo) (numericType, numericType, bool) {
+	dtoType := strings.TrimPrefix(field.Type, "*")
+	if strings.HasPrefix(dtoType, "[]") || source.IsSlice {
+		return numericType{}, numericType{}, false
+	}
+	if field.BaseIdentity.IsKnown() {
+		dtoType = field.BaseIdentity.Underlying
+	}
+	sourceType := source.BaseType
+	if source.BaseIdentity.IsKnown() {
+		sourceType = source.BaseIdentity.Underlying
+	}
+	dst, dstOK := numericTypes[dtoType]
+	src, srcOK := numericTypes[sourceType]
+	return dst, src, dstOK && srcOK
+}
+
+// NumericConversion reports whether a DTO field and its source field hold distinct numeric types
+func NumericConversion(field FieldInfo, source FieldTypeInfo) bool {
+	dst, src, ok := numericTypesOf(field, source)
+	return ok && dst != src
+}
+
+// NumericWidening reports whether a DTO field holds every value of the numeric type of its source
+// field, e.g. int64 for int32 or float64 for float32, so that a cast maps it without loss
+func NumericWidening(field FieldInfo, source FieldTypeInfo) bool {
+	dst, src, ok := numericTypesOf(field, source)
+	if !ok || dst == src {
+		return false
+	}
+	switch {
+	case dst.kind == src.kind:
+		return src.max <= dst.min
+	case dst.kind == 'i' && src.kind == 'u':
+		return src.max < dst.min
+	case dst.kind == 'f' && (src.kind == 'i' || src.kind == 'u'):
+		return src.max <= mantissaBits[dst.min]
+	}
+	return false
+}
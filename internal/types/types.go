@@ -1,22 +1,115 @@
 package types
 
+import "fmt"
+
 // DTOMapping represents a DTO with its mapping configuration
 type DTOMapping struct {
-	Name        string
-	Sources     []string
-	Fields      []FieldInfo
-	PackageName string
+	Name            string
+	Sources         []string
+	Fields          []FieldInfo
+	PackageName     string
+	Modes           []string
+	PrioritySources []string
+	Transform       string
+	Via             string
+	Style           string
+	TestOnly        bool     `json:",omitempty"` // declared in a _test.go file, or mapping from a struct that is
+	Position        Position `json:"-"`          // left out of the input hash, moving a DTO doesn't change the output
+}
+
+// UsesMode reports whether any of the DTOs requested the given mapping mode
+func UsesMode(dtos []DTOMapping, mode string) bool {
+	for _, dto := range dtos {
+		if dto.HasMode(mode) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasMode reports whether the DTO requested the given mapping mode
+func (d DTOMapping) HasMode(mode string) bool {
+	for _, m := range d.Modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
 }
 
+// Mapping modes selected with the automapper:mode= annotation
+const (
+	// ModeUpdate additionally generates UpdateFrom methods for partial updates
+	ModeUpdate = "update"
+	// ModePatch additionally generates ApplyTo methods writing non-nil fields back to the source
+	ModePatch = "patch"
+	// ModeStream additionally generates functions mapping channels of sources to channels of DTOs
+	ModeStream = "stream"
+	// ModeBidirectional additionally generates MapTo methods mapping the DTO back to its sources
+	ModeBidirectional = "bidirectional"
+	// ModeMapper additionally generates a mapper interface with an implementation for dependency injection
+	ModeMapper = "mapper"
+	// ModeClone additionally generates a Clone method returning a deep copy of the DTO
+	ModeClone = "clone"
+	// ModeDiff additionally generates functions reporting the fields that differ between the DTO and a source
+	ModeDiff = "diff"
+	// ModeParallel additionally generates functions mapping slices of sources on a bounded pool of goroutines
+	ModeParallel = "parallel"
+	// ModeMerge additionally generates MergeTo methods mapping the DTO back into the zero-valued fields of its sources
+	ModeMerge = "merge"
+)
+
+// Redaction strategies selected with the redact field tag
+const (
+	// RedactFull replaces non-empty values with a fixed placeholder
+	RedactFull = "full"
+	// RedactMask4 masks everything but the last four characters
+	RedactMask4 = "mask4"
+	// RedactHash replaces values with a short SHA-256 fingerprint
+	RedactHash = "hash"
+)
+
 // FieldInfo contains information about a struct field
 type FieldInfo struct {
-	Name         string
-	Type         string
-	Tag          string
-	ConverterTag string
-	FieldTag     string
-	Ignore       bool
-	NestedDTO    string
+	Name          string
+	Type          string
+	Tag           string
+	ConverterTag  string
+	FieldTag      string
+	Ignore        bool
+	NestedDTO     string
+	JSONName      string
+	TypeCases     []TypeCase
+	Redact        string
+	BaseIdentity  TypeIdentity // type under the pointer and slice of Type
+	ConverterFunc string       // function of an imported package the converter tag names, e.g. example.com/convert.ToString
+	Position      Position     `json:"-"`
+}
+
+// Position locates a declaration in the package sources
+type Position struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// IsValid reports whether the position is known
+func (p Position) IsValid() bool {
+	return p.File != ""
+}
+
+// String formats the position as file:line:column
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// TypeCase maps a concrete type held by an interface-typed source field to a DTO
+type TypeCase struct {
+	Source string
+	DTO    string
 }
 
 // SourceStruct represents a source struct that can be mapped from
@@ -27,19 +120,47 @@ type SourceStruct struct {
 	IsExternal bool
 	ImportPath string
 	Alias      string
+	Getters    map[string]FieldTypeInfo
+	Targets    []string // DTOs the struct maps to, declared with automapper:to
+	// import paths of the other packages declaring the embedded structs fields are promoted from
+	PromotedFrom []string `json:",omitempty"`
 }
 
 // FieldTypeInfo contains detailed type information about a field
 type FieldTypeInfo struct {
-	Type      string
-	IsPointer bool
-	IsSlice   bool
-	BaseType  string
+	Type         string
+	IsPointer    bool
+	IsSlice      bool
+	BaseType     string
+	BaseIdentity TypeIdentity    // BaseType as type checking identifies it
+	Kind         TypeKind        `json:",omitempty"` // structure of BaseType, empty for named and basic types
+	Key          *FieldTypeInfo  `json:",omitempty"` // key type of a map
+	Elem         *FieldTypeInfo  `json:",omitempty"` // value type of a map, element type of a channel or array
+	Len          string          `json:",omitempty"` // length of an array
+	Dir          string          `json:",omitempty"` // direction of a channel: chan, <-chan or chan<-
+	Params       []FieldTypeInfo `json:",omitempty"` // parameter types of a func
+	Results      []FieldTypeInfo `json:",omitempty"` // result types of a func
 }
 
+// TypeKind is the structure of a type literal, which FieldTypeInfo breaks down further than its
+// pointer and slice
+type TypeKind string
+
+const (
+	KindMap       TypeKind = "map"
+	KindChan      TypeKind = "chan"
+	KindFunc      TypeKind = "func"
+	KindArray     TypeKind = "array"
+	KindStruct    TypeKind = "struct"
+	KindInterface TypeKind = "interface"
+)
+
 // FunctionInfo contains information about a function
 type FunctionInfo struct {
 	Name        string
 	ParamTypes  []string
 	ReturnTypes []string
+	TypeParams  []string        `json:",omitempty"` // type parameters of a generic function, in order
+	Params      []FieldTypeInfo `json:",omitempty"` // ParamTypes broken down into pointer, slice and base type
+	Results     []FieldTypeInfo `json:",omitempty"` // ReturnTypes broken down likewise
 }
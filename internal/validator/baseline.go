@@ -0,0 +1,110 @@
+package validator
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// BaselineFileName is the file next to the DTOs recording the findings accepted so far
+const BaselineFileName = "automapper.baseline.json"
+
+// Baseline records accepted findings by DTO, source, field and rule, so that only new ones fail
+// strict runs. Messages aren't recorded, rewording one doesn't bring its findings back.
+type Baseline struct {
+	Findings []BaselineFinding `json:"findings"`
+}
+
+// BaselineFinding identifies an accepted finding
+type BaselineFinding struct {
+	DTO    string `json:"dto"`
+	Source string `json:"source,omitempty"`
+	Field  string `json:"field,omitempty"`
+	Rule   string `json:"rule"`
+}
+
+// LoadBaseline reads a baseline file, returning nil when there is none
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &baseline, nil
+}
+
+// NewBaseline accepts the findings of a validation that have a rule, errors of strict mode included
+func NewBaseline(result *ValidationResult) *Baseline {
+	baseline := &Baseline{Findings: []BaselineFinding{}}
+	for _, finding := range slices.Concat(result.Errors, result.Warnings) {
+		if finding.Rule != "" {
+			baseline.Findings = append(baseline.Findings, baselineFinding(finding))
+		}
+	}
+
+	// Sorted and without duplicates, regenerating an unchanged baseline leaves the file alone
+	slices.SortFunc(baseline.Findings, func(a, b BaselineFinding) int {
+		return cmp.Or(cmp.Compare(a.DTO, b.DTO), cmp.Compare(a.Source, b.Source),
+			cmp.Compare(a.Field, b.Field), cmp.Compare(a.Rule, b.Rule))
+	})
+	baseline.Findings = slices.Compact(baseline.Findings)
+	return baseline
+}
+
+// Save writes the baseline as indented JSON
+func (b *Baseline) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Filter removes the findings the baseline accepts from a validation result, returning their count.
+// Accepted findings that are gone are returned as stale, they can be dropped from the baseline.
+func (b *Baseline) Filter(result *ValidationResult) (int, []BaselineFinding) {
+	accepted := make(map[BaselineFinding]bool)
+	for _, finding := range b.Findings {
+		accepted[finding] = false
+	}
+
+	filter := func(findings []ValidationError) []ValidationError {
+		kept := findings[:0]
+		for _, finding := range findings {
+			key := baselineFinding(finding)
+			if _, ok := accepted[key]; ok && finding.Rule != "" {
+				accepted[key] = true
+				continue
+			}
+			kept = append(kept, finding)
+		}
+		return kept
+	}
+
+	total := len(result.Errors) + len(result.Warnings)
+	result.Errors = filter(result.Errors)
+	result.Warnings = filter(result.Warnings)
+
+	var stale []BaselineFinding
+	for _, finding := range b.Findings {
+		if !accepted[finding] {
+			stale = append(stale, finding)
+		}
+	}
+	return total - len(result.Errors) - len(result.Warnings), stale
+}
+
+// baselineFinding identifies a finding in a baseline
+func baselineFinding(finding ValidationError) BaselineFinding {
+	return BaselineFinding{DTO: finding.DTO, Source: finding.Source, Field: finding.Field, Rule: finding.Rule}
+}
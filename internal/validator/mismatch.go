@@ -0,0 +1,109 @@
+package validator
+
+import (
+	"fmt"
+
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
+)
+
+// typeMismatch describes a DTO field that can't hold its source field without a converter. Numeric
+// types are told apart by whether the DTO type is wider, type literals by what differs in them: the
+// kind, the key or value of a map, the element or direction of a channel, the length or element of
+// an array, a parameter or result of a func.
+func typeMismatch(field types.FieldInfo, sourceField types.FieldTypeInfo) string {
+	switch {
+	case types.NumericWidening(field, sourceField):
+		return fmt.Sprintf("Numeric widening: %s <- %s (cast only with allowNumericWidening)", field.Type, sourceField.Type)
+	case types.NumericConversion(field, sourceField):
+		return fmt.Sprintf("Numeric narrowing: %s <- %s (the value may not fit, convert it explicitly)", field.Type, sourceField.Type)
+	}
+	if dtoField, ok := parser.ParseTypeString(field.Type); ok {
+		if detail := literalMismatch(dtoField, sourceField); detail != "" {
+			return fmt.Sprintf("Type mismatch: %s <- %s (%s)", field.Type, sourceField.Type, detail)
+		}
+	}
+	return fmt.Sprintf("Type mismatch: %s <- %s (cannot convert without converter)", field.Type, sourceField.Type)
+}
+
+// literalMismatch tells what sets apart two types of which one at least is a type literal, empty
+// when nothing does as far as their structure goes
+func literalMismatch(dst, src types.FieldTypeInfo) string {
+	dstKind, srcKind := kindName(dst), kindName(src)
+	if dstKind != srcKind {
+		if dstKind == "" || srcKind == "" {
+			return ""
+		}
+		return fmt.Sprintf("kinds differ: %s <- %s", dstKind, srcKind)
+	}
+	if dst.Kind != src.Kind {
+		if dst.Kind == "" || src.Kind == "" {
+			return ""
+		}
+		return fmt.Sprintf("slice element kinds differ: %s <- %s", dst.Kind, src.Kind)
+	}
+
+	switch dst.Kind {
+	case types.KindMap:
+		if !sameNested(dst.Key, src.Key) {
+			return fmt.Sprintf("map keys differ: %s <- %s", dst.Key.Type, src.Key.Type)
+		}
+		if !sameNested(dst.Elem, src.Elem) {
+			return fmt.Sprintf("map values differ: %s <- %s", dst.Elem.Type, src.Elem.Type)
+		}
+	case types.KindChan:
+		if dst.Dir != src.Dir {
+			return fmt.Sprintf("channel directions differ: %s <- %s", dst.Dir, src.Dir)
+		}
+		if !sameNested(dst.Elem, src.Elem) {
+			return fmt.Sprintf("channel elements differ: %s <- %s", dst.Elem.Type, src.Elem.Type)
+		}
+	case types.KindArray:
+		if dst.Len != src.Len {
+			return fmt.Sprintf("array lengths differ: %s <- %s", dst.Len, src.Len)
+		}
+		if !sameNested(dst.Elem, src.Elem) {
+			return fmt.Sprintf("array elements differ: %s <- %s", dst.Elem.Type, src.Elem.Type)
+		}
+	case types.KindFunc:
+		if len(dst.Params) != len(src.Params) || len(dst.Results) != len(src.Results) {
+			return fmt.Sprintf("func signatures differ: %d parameters and %d results <- %d and %d",
+				len(dst.Params), len(dst.Results), len(src.Params), len(src.Results))
+		}
+		for i := range dst.Params {
+			if !sameType(dst.Params[i], src.Params[i]) {
+				return fmt.Sprintf("parameter %d differs: %s <- %s", i+1, dst.Params[i].Type, src.Params[i].Type)
+			}
+		}
+		for i := range dst.Results {
+			if !sameType(dst.Results[i], src.Results[i]) {
+				return fmt.Sprintf("result %d differs: %s <- %s", i+1, dst.Results[i].Type, src.Results[i].Type)
+			}
+		}
+	}
+	return ""
+}
+
+// kindName names the structure of a type for diagnostics, empty for named and basic types
+func kindName(t types.FieldTypeInfo) string {
+	if t.IsSlice {
+		return "slice"
+	}
+	return string(t.Kind)
+}
+
+// sameType reports whether two types nested in type literals are the same as far as their names go
+func sameType(a, b types.FieldTypeInfo) bool {
+	if a.IsPointer != b.IsPointer || a.IsSlice != b.IsSlice || a.Kind != b.Kind {
+		return false
+	}
+	if a.Kind != "" {
+		return literalMismatch(a, b) == "" && typeNamesMatch(a.BaseType, b.BaseType)
+	}
+	return typeNamesMatch(a.BaseType, b.BaseType)
+}
+
+// sameNested compares the key or element types of two type literals, when both were broken down
+func sameNested(a, b *types.FieldTypeInfo) bool {
+	return a == nil || b == nil || sameType(*a, *b)
+}
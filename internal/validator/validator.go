@@ -2,10 +2,13 @@ package validator
 
 import (
 	"fmt"
+	"go/ast"
+	"slices"
 	"strings"
 
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/config"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/logger"
+	"git.weirdcat.su/weirdcat/automapper-gen/internal/matcher"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/parser"
 	"git.weirdcat.su/weirdcat/automapper-gen/internal/types"
 )
@@ -15,23 +18,29 @@ type Severity string
 const (
 	SeverityError   = "error"
 	SeverityWarning = "warning"
+	SeverityInfo    = "info"
 )
 
 // ValidationError represents a validation error
 type ValidationError struct {
-	DTO        string
-	Source     string
-	Field      string
-	Message    string
-	Severity   Severity
-	Fixable    bool
-	Suggestion string
+	DTO        string          `json:"dto,omitempty"`
+	Source     string          `json:"source,omitempty"`
+	Field      string          `json:"field,omitempty"`
+	Message    string          `json:"message"`
+	Severity   Severity        `json:"severity"`
+	Fixable    bool            `json:"fixable"`
+	Suggestion string          `json:"suggestion,omitempty"`
+	Position   *types.Position `json:"position,omitempty"` // declaration of the field, or of the DTO
+	Rule       string          `json:"rule,omitempty"`     // check of a finding, e.g. pointer-conversion, see config.Rules
 }
 
 func (e ValidationError) Error() string {
 	severityPrefix := "[ERROR]"
-	if e.Severity == SeverityWarning {
+	switch e.Severity {
+	case SeverityWarning:
 		severityPrefix = "[WARN] "
+	case SeverityInfo:
+		severityPrefix = "[INFO] "
 	}
 
 	msg := fmt.Sprintf("%s %s.%s -> %s.%s: %s",
@@ -46,9 +55,10 @@ func (e ValidationError) Error() string {
 
 // ValidationResult holds the results of validation
 type ValidationResult struct {
-	Errors   []ValidationError
-	Warnings []ValidationError
-	Stats    map[string]int
+	Errors   []ValidationError `json:"errors"`
+	Warnings []ValidationError `json:"warnings"`
+	Info     []ValidationError `json:"info,omitempty"` // findings of the rules severities makes info
+	Stats    map[string]int    `json:"stats"`
 }
 
 // IsValid returns true if there are no errors
@@ -63,6 +73,7 @@ type Validator struct {
 	dtos      map[string]types.DTOMapping
 	functions map[string]types.FunctionInfo
 	visited   map[string]bool
+	baseline  *Baseline // findings left out of the result, none when nil
 }
 
 // NewValidator creates a new validator
@@ -86,6 +97,11 @@ func NewValidator(
 	}
 }
 
+// SetBaseline leaves the findings a baseline accepts out of the validation result
+func (v *Validator) SetBaseline(baseline *Baseline) {
+	v.baseline = baseline
+}
+
 // Validate performs validation
 func (v *Validator) Validate() *ValidationResult {
 	logger.Section("Validation")
@@ -101,22 +117,64 @@ func (v *Validator) Validate() *ValidationResult {
 
 	// Validate converter functions exist
 	v.validateConverterFunctions(result)
+	v.validateMapperInterfaces(result)
 
 	totalFields := 0
 	for _, dto := range v.dtos {
 		totalFields += len(dto.Fields)
 		logger.Verbose("Validating DTO: %s (sources: %v)", dto.Name, dto.Sources)
 
+		v.validateModes(dto, result)
+
+		if dto.Transform != "" && !config.IsKnownTransform(dto.Transform) {
+			result.Errors = append(result.Errors, ValidationError{
+				DTO:      dto.Name,
+				Message:  fmt.Sprintf("Unknown field name transform '%s'", dto.Transform),
+				Severity: SeverityError,
+				Suggestion: fmt.Sprintf("Use one of %s, or %sFunction calling a Go function",
+					strings.Join(config.TransformNames(), ", "), config.TransformFuncPrefix),
+			})
+		}
+
+		v.validateStyle(dto, result)
+
+		if len(dto.PrioritySources) > 0 {
+			v.validatePrioritySources(dto, result)
+		}
+
+		if dto.Via != "" {
+			v.validateVia(dto, result)
+		}
+
 		for _, sourceName := range dto.Sources {
 			v.validateDTOMapping(dto, sourceName, result)
 		}
 	}
 
+	v.attachPositions(result.Errors)
+	v.attachPositions(result.Warnings)
+	v.applySeverities(result)
+
+	if v.baseline != nil {
+		accepted, stale := v.baseline.Filter(result)
+		result.Stats["baselined"] = accepted
+		if accepted > 0 {
+			logger.Info("%d findings accepted by the baseline", accepted)
+		}
+		if len(stale) > 0 {
+			logger.Verbose("%d findings of the baseline are gone, -update-baseline drops them", len(stale))
+		}
+	}
+
 	result.Stats["total_fields"] = totalFields
 	result.Stats["errors"] = len(result.Errors)
 	result.Stats["warnings"] = len(result.Warnings)
+	result.Stats["info"] = len(result.Info)
 
 	// Print summary
+	for _, i := range result.Info {
+		logger.Info("%s", i.Error())
+	}
 	if len(result.Warnings) > 0 {
 		logger.Warning("Found %d warnings", len(result.Warnings))
 		for _, w := range result.Warnings {
@@ -144,6 +202,27 @@ func (v *Validator) Validate() *ValidationResult {
 	return result
 }
 
+// attachPositions locates the problems in the sources, at the field they concern or else at their DTO
+func (v *Validator) attachPositions(problems []ValidationError) {
+	for i, problem := range problems {
+		dto, ok := v.dtos[problem.DTO]
+		if !ok {
+			continue
+		}
+
+		position := dto.Position
+		for _, field := range dto.Fields {
+			if field.Name == problem.Field && field.Position.IsValid() {
+				position = field.Position
+				break
+			}
+		}
+		if position.IsValid() {
+			problems[i].Position = &position
+		}
+	}
+}
+
 // validateConverterFunctions validates that all converter functions exist
 func (v *Validator) validateConverterFunctions(result *ValidationResult) {
 	logger.Verbose("Validating converter functions...")
@@ -164,27 +243,242 @@ func (v *Validator) validateConverterFunctions(result *ValidationResult) {
 		}
 
 		// Validate function signature and automatically detect type
-		isSafe := parser.IsSafeConverterSignature(fn)
-		isErrorReturning := parser.IsErrorReturningConverterSignature(fn)
+		v.validateConverterSignature(conv, fn, "converter", result)
 
-		if isSafe {
-			logger.Debug("  Safe converter '%s' (%s) - func(T) U", conv.Name, conv.Function)
-		} else if isErrorReturning {
-			logger.Debug("  Regular converter '%s' (%s) - func(T) (U, error)", conv.Name, conv.Function)
-		} else {
-			// Invalid signature
+		if conv.Inverter == "" {
+			continue
+		}
+
+		inverter, exists := v.functions[conv.Inverter]
+		if !exists {
 			result.Errors = append(result.Errors, ValidationError{
-				Message: fmt.Sprintf("Converter function '%s' has invalid signature, got: %d params, %d returns)",
-					conv.Function, len(fn.ParamTypes), len(fn.ReturnTypes)),
+				Message:    fmt.Sprintf("Inverter function '%s' (for converter '%s') not found in package", conv.Inverter, conv.Name),
 				Severity:   SeverityError,
-				Suggestion: "Change signature to either func(T) U (for safe converters) or func(T) (U, error)",
+				Suggestion: fmt.Sprintf("Add function '%s' to your package or fix the inverter name in automapper.json", conv.Inverter),
 			})
+			continue
 		}
+
+		v.validateConverterSignature(conv, inverter, "inverter", result)
 	}
 
 	logger.Verbose("Converter functions validated: %d", len(v.cfg.Converters))
 }
 
+// validateMapperInterfaces validates that the DTOs listed for mapper interface assertions exist
+func (v *Validator) validateMapperInterfaces(result *ValidationResult) {
+	for _, iface := range v.cfg.MapperInterfaces {
+		for _, dtoName := range iface.DTOs {
+			if _, exists := v.dtos[dtoName]; !exists {
+				result.Errors = append(result.Errors, ValidationError{
+					Message:    fmt.Sprintf("DTO '%s' listed for mapper interface '%s' not found", dtoName, iface.Name),
+					Severity:   SeverityError,
+					Suggestion: "Fix the DTO name in the dtos list of mapperInterfaces in automapper.json",
+				})
+			}
+		}
+	}
+}
+
+// validateConverterSignature validates that a converter or inverter function has a supported signature
+func (v *Validator) validateConverterSignature(
+	conv config.ConverterDef, fn types.FunctionInfo, role string, result *ValidationResult,
+) {
+	isSafe := parser.IsSafeConverterSignature(fn)
+	isErrorReturning := parser.IsErrorReturningConverterSignature(fn)
+
+	if isSafe {
+		logger.Debug("  Safe %s '%s' (%s) - func(T) U", role, conv.Name, fn.Name)
+	} else if isErrorReturning {
+		logger.Debug("  Regular %s '%s' (%s) - func(T) (U, error)", role, conv.Name, fn.Name)
+	} else {
+		// Invalid signature
+		result.Errors = append(result.Errors, ValidationError{
+			Message: fmt.Sprintf("Converter function '%s' has invalid signature, got: %d params, %d returns)",
+				fn.Name, len(fn.ParamTypes), len(fn.ReturnTypes)),
+			Severity:   SeverityError,
+			Suggestion: "Change signature to either func(T) U (for safe converters) or func(T) (U, error)",
+		})
+	}
+}
+
+// validateModes validates the mapping modes requested by a DTO annotation
+func (v *Validator) validateModes(dto types.DTOMapping, result *ValidationResult) {
+	for _, mode := range dto.Modes {
+		switch mode {
+		case types.ModeUpdate, types.ModeStream, types.ModeDiff, types.ModeParallel:
+		case types.ModeClone:
+			v.validateCloneFields(dto, result)
+		case types.ModePatch:
+			v.validatePatchFields(dto, result)
+		case types.ModeBidirectional:
+			v.validateBidirectionalFields(dto, result)
+		case types.ModeMerge:
+			// Merging maps back like MapTo does
+			if !dto.HasMode(types.ModeBidirectional) {
+				v.validateBidirectionalFields(dto, result)
+			}
+		case types.ModeMapper:
+			if len(dto.Sources) != 1 {
+				result.Errors = append(result.Errors, ValidationError{
+					DTO:        dto.Name,
+					Message:    fmt.Sprintf("Mapper mode needs exactly one source, got %d", len(dto.Sources)),
+					Severity:   SeverityError,
+					Suggestion: "Split the DTO or drop the mapper mode",
+				})
+			}
+		default:
+			result.Errors = append(result.Errors, ValidationError{
+				DTO:      dto.Name,
+				Message:  fmt.Sprintf("Unknown mapping mode '%s'", mode),
+				Severity: SeverityError,
+				Suggestion: fmt.Sprintf("Use one of: %s", strings.Join([]string{
+					types.ModeUpdate, types.ModePatch, types.ModeStream, types.ModeBidirectional,
+					types.ModeMapper, types.ModeClone, types.ModeDiff, types.ModeParallel, types.ModeMerge,
+				}, ", ")),
+			})
+		}
+	}
+}
+
+// validateBidirectionalFields validates that every mapped field of a DTO can be mapped back with MapTo
+func (v *Validator) validateBidirectionalFields(dto types.DTOMapping, result *ValidationResult) {
+	for _, field := range dto.Fields {
+		if field.Ignore {
+			continue
+		}
+
+		_, isGetter := matcher.GetterName(field.FieldTag)
+
+		switch {
+		case len(field.TypeCases) > 0, field.Redact != "", isGetter:
+			// Each source has a MapTo of its own
+			for _, sourceName := range dto.Sources {
+				result.Warnings = append(result.Warnings, ValidationError{
+					DTO:        dto.Name,
+					Source:     sourceName,
+					Field:      field.Name,
+					Message:    "Interface, redacted and getter fields are not mapped back by MapTo",
+					Severity:   SeverityWarning,
+					Suggestion: "Ignore the field or set it on the destination separately",
+					Rule:       config.RuleNotMappedBack,
+				})
+			}
+		case field.NestedDTO != "":
+			if nested, exists := v.dtos[field.NestedDTO]; exists && !nested.HasMode(types.ModeBidirectional) {
+				result.Errors = append(result.Errors, ValidationError{
+					DTO:        dto.Name,
+					Field:      field.Name,
+					Message:    fmt.Sprintf("Nested DTO '%s' is not bidirectional", field.NestedDTO),
+					Severity:   SeverityError,
+					Suggestion: fmt.Sprintf("Add automapper:mode=%s to %s", types.ModeBidirectional, field.NestedDTO),
+				})
+			}
+		case field.ConverterTag != "":
+			for _, conv := range v.cfg.Converters {
+				if conv.Name == field.ConverterTag && conv.Inverter == "" {
+					result.Errors = append(result.Errors, ValidationError{
+						DTO:        dto.Name,
+						Field:      field.Name,
+						Message:    fmt.Sprintf("Converter '%s' has no inverter, field cannot be mapped back", conv.Name),
+						Severity:   SeverityError,
+						Suggestion: "Add an \"inverter\" function to the converter in automapper.json",
+					})
+				}
+			}
+		}
+	}
+}
+
+// validateCloneFields validates that the nested DTOs of a cloneable DTO can be cloned as well
+func (v *Validator) validateCloneFields(dto types.DTOMapping, result *ValidationResult) {
+	for _, field := range dto.Fields {
+		if field.NestedDTO == "" {
+			continue
+		}
+
+		if nested, exists := v.dtos[field.NestedDTO]; exists && !nested.HasMode(types.ModeClone) {
+			result.Errors = append(result.Errors, ValidationError{
+				DTO:        dto.Name,
+				Field:      field.Name,
+				Message:    fmt.Sprintf("Nested DTO '%s' has no Clone method, the copy would share its memory", field.NestedDTO),
+				Severity:   SeverityError,
+				Suggestion: fmt.Sprintf("Add automapper:mode=%s to %s", types.ModeClone, field.NestedDTO),
+			})
+		}
+	}
+}
+
+// validatePatchFields validates that a patch DTO can be applied back to its sources
+func (v *Validator) validatePatchFields(dto types.DTOMapping, result *ValidationResult) {
+	for _, field := range dto.Fields {
+		if field.Ignore {
+			continue
+		}
+
+		if !strings.HasPrefix(field.Type, "*") {
+			result.Errors = append(result.Errors, ValidationError{
+				DTO:        dto.Name,
+				Field:      field.Name,
+				Message:    fmt.Sprintf("Patch DTO field must be a pointer, got %s", field.Type),
+				Severity:   SeverityError,
+				Suggestion: fmt.Sprintf("Change the type to *%s or ignore the field with `automapper:\"-\"`", field.Type),
+			})
+			continue
+		}
+
+		if _, isGetter := matcher.GetterName(field.FieldTag); isGetter {
+			result.Warnings = append(result.Warnings, ValidationError{
+				DTO:        dto.Name,
+				Field:      field.Name,
+				Message:    fmt.Sprintf("Field is read through getter %s and is not applied by ApplyTo", field.FieldTag),
+				Severity:   SeverityWarning,
+				Suggestion: "Ignore the field or map it from an exported source field",
+				Rule:       config.RuleGetterNotApplied,
+			})
+			continue
+		}
+
+		if field.Redact != "" {
+			result.Warnings = append(result.Warnings, ValidationError{
+				DTO:        dto.Name,
+				Field:      field.Name,
+				Message:    "Redacted fields are not applied by ApplyTo",
+				Severity:   SeverityWarning,
+				Suggestion: "Ignore the field or drop the redaction",
+				Rule:       config.RuleRedactedNotApplied,
+			})
+			continue
+		}
+
+		if field.NestedDTO != "" || len(field.TypeCases) > 0 {
+			result.Warnings = append(result.Warnings, ValidationError{
+				DTO:        dto.Name,
+				Field:      field.Name,
+				Message:    "Nested DTOs are not applied by ApplyTo",
+				Severity:   SeverityWarning,
+				Suggestion: "Apply the nested DTO separately or ignore the field",
+				Rule:       config.RuleNestedNotApplied,
+			})
+			continue
+		}
+
+		if field.ConverterTag != "" {
+			for _, conv := range v.cfg.Converters {
+				if conv.Name == field.ConverterTag && conv.Inverter == "" {
+					result.Errors = append(result.Errors, ValidationError{
+						DTO:        dto.Name,
+						Field:      field.Name,
+						Message:    fmt.Sprintf("Converter '%s' has no inverter, field cannot be applied", conv.Name),
+						Severity:   SeverityError,
+						Suggestion: "Add an \"inverter\" function to the converter in automapper.json",
+					})
+				}
+			}
+		}
+	}
+}
+
 // validateDTOMapping validates a single DTO to source mapping
 func (v *Validator) validateDTOMapping(
 	dto types.DTOMapping, sourceName string, result *ValidationResult,
@@ -201,6 +495,17 @@ func (v *Validator) validateDTOMapping(
 		return
 	}
 
+	if sourceName == dto.Name {
+		result.Errors = append(result.Errors, ValidationError{
+			DTO:        dto.Name,
+			Source:     sourceName,
+			Message:    "DTO cannot map from itself",
+			Severity:   SeverityError,
+			Suggestion: "Reference another DTO or a source struct in automapper:from",
+		})
+		return
+	}
+
 	logger.Debug("Validating %s <- %s (%d fields)", dto.Name, sourceName, len(dto.Fields))
 
 	for _, field := range dto.Fields {
@@ -213,6 +518,167 @@ func (v *Validator) validateDTOMapping(
 	}
 }
 
+// validatePrioritySources validates a priority-ordered multi-source mapping.
+// Every field has to be found in at least one of the sources.
+func (v *Validator) validatePrioritySources(dto types.DTOMapping, result *ValidationResult) {
+	priorityName := strings.Join(dto.PrioritySources, ">")
+
+	if len(dto.PrioritySources) < 2 {
+		result.Errors = append(result.Errors, ValidationError{
+			DTO:        dto.Name,
+			Source:     priorityName,
+			Message:    "Priority source list needs at least two sources",
+			Severity:   SeverityError,
+			Suggestion: "Use automapper:sources=Primary>Fallback or automapper:from= for a single source",
+		})
+		return
+	}
+
+	prioritySources := []types.SourceStruct{}
+	for _, sourceName := range dto.PrioritySources {
+		source, exists := v.sources[sourceName]
+		if !exists {
+			result.Errors = append(result.Errors, ValidationError{
+				DTO:        dto.Name,
+				Source:     sourceName,
+				Message:    "Source struct not found",
+				Severity:   SeverityError,
+				Suggestion: fmt.Sprintf("Ensure %s is defined in the package or included in external packages", sourceName),
+			})
+			return
+		}
+		prioritySources = append(prioritySources, source)
+	}
+
+	logger.Debug("Validating %s <- %s (%d fields)", dto.Name, priorityName, len(dto.Fields))
+
+	m := matcher.New(v.cfg, dto)
+	for _, field := range dto.Fields {
+		if field.Ignore {
+			continue
+		}
+
+		found := false
+		for i, source := range prioritySources {
+			if len(m.Candidates(field, source)) > 0 {
+				found = true
+				v.validateField(dto, source, dto.PrioritySources[i], field, result)
+			}
+		}
+
+		if !found {
+			// Reports the missing field the same way a single source would
+			v.validateField(dto, types.SourceStruct{}, priorityName, field, result)
+		}
+	}
+}
+
+// validateStyle validates the output style of a DTO and the features it supports
+func (v *Validator) validateStyle(dto types.DTOMapping, result *ValidationResult) {
+	if dto.Style != "" && !config.IsKnownStyle(dto.Style) {
+		result.Errors = append(result.Errors, ValidationError{
+			DTO:        dto.Name,
+			Message:    fmt.Sprintf("Unknown output style '%s'", dto.Style),
+			Severity:   SeverityError,
+			Suggestion: fmt.Sprintf("Use automapper:style=%s or automapper:style=%s", config.StyleMethod, config.StyleFunction),
+		})
+		return
+	}
+
+	if !v.isFunctionStyle(dto) {
+		return
+	}
+
+	// These features generate methods on the DTO
+	var unsupported []string
+	for _, mode := range dto.Modes {
+		if !slices.Contains([]string{types.ModeStream, types.ModeMapper, types.ModeDiff, types.ModeParallel}, mode) {
+			unsupported = append(unsupported, "automapper:mode="+mode)
+		}
+	}
+	if len(dto.PrioritySources) > 0 {
+		unsupported = append(unsupported, "automapper:sources")
+	}
+	if dto.Via != "" {
+		unsupported = append(unsupported, "automapper:via")
+	}
+	if v.cfg.MaxDepth > 0 && v.isRecursive(dto) {
+		unsupported = append(unsupported, "maxDepth for recursive DTOs")
+	}
+
+	if len(unsupported) > 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			DTO:        dto.Name,
+			Message:    fmt.Sprintf("Function-style output does not support %s", strings.Join(unsupported, ", ")),
+			Severity:   SeverityError,
+			Suggestion: fmt.Sprintf("Use automapper:style=%s for this DTO", config.StyleMethod),
+		})
+	}
+}
+
+// isFunctionStyle reports whether a DTO is generated as package-level functions
+func (v *Validator) isFunctionStyle(dto types.DTOMapping) bool {
+	style := dto.Style
+	if style == "" {
+		style = v.cfg.Style
+	}
+	return style == config.StyleFunction
+}
+
+// isRecursive reports whether a DTO can reach itself through nested DTO fields
+func (v *Validator) isRecursive(dto types.DTOMapping) bool {
+	for _, field := range dto.Fields {
+		if field.NestedDTO != "" && !field.Ignore && v.detectCircularDependency(dto.Name, field.NestedDTO) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateVia validates a mapping through an intermediate DTO
+func (v *Validator) validateVia(dto types.DTOMapping, result *ValidationResult) {
+	viaDTO, exists := v.dtos[dto.Via]
+	if !exists || dto.Via == dto.Name {
+		result.Errors = append(result.Errors, ValidationError{
+			DTO:        dto.Name,
+			Source:     dto.Via,
+			Message:    fmt.Sprintf("Intermediate DTO '%s' not found", dto.Via),
+			Severity:   SeverityError,
+			Suggestion: "automapper:via must reference another DTO of this package",
+		})
+		return
+	}
+
+	if v.isFunctionStyle(viaDTO) {
+		result.Errors = append(result.Errors, ValidationError{
+			DTO:        dto.Name,
+			Source:     dto.Via,
+			Message:    fmt.Sprintf("Intermediate DTO '%s' uses function-style output", dto.Via),
+			Severity:   SeverityError,
+			Suggestion: "Map through a DTO generated with methods",
+		})
+		return
+	}
+
+	// Via methods are named after the sources of the intermediate DTO
+	for _, viaSource := range viaDTO.Sources {
+		for _, sourceName := range dto.Sources {
+			if typeNameWithoutPackage(viaSource) != typeNameWithoutPackage(sourceName) {
+				continue
+			}
+			result.Errors = append(result.Errors, ValidationError{
+				DTO:        dto.Name,
+				Source:     viaSource,
+				Message:    fmt.Sprintf("Mapping from %s via %s clashes with the mapping from %s", viaSource, dto.Via, sourceName),
+				Severity:   SeverityError,
+				Suggestion: fmt.Sprintf("Remove %s from automapper:from or drop automapper:via", sourceName),
+			})
+		}
+	}
+
+	logger.Debug("Validating %s <- %s <- %v", dto.Name, dto.Via, viaDTO.Sources)
+}
+
 // validateField validates a single field mapping
 func (v *Validator) validateField(
 	dto types.DTOMapping,
@@ -221,8 +687,38 @@ func (v *Validator) validateField(
 	field types.FieldInfo,
 	result *ValidationResult,
 ) {
-	sourceFieldName := v.resolveSourceFieldName(field)
-	sourceField, exists := source.Fields[sourceFieldName]
+	m := matcher.New(v.cfg, dto)
+	sourceFieldName, sourceField, exists := m.Resolve(field, source)
+
+	if candidates := m.Candidates(field, source); len(candidates) > 1 {
+		result.Errors = append(result.Errors, ValidationError{
+			DTO:        dto.Name,
+			Source:     sourceName,
+			Field:      field.Name,
+			Message:    fmt.Sprintf("Ambiguous source field, matches %s", strings.Join(candidates, ", ")),
+			Severity:   SeverityError,
+			Suggestion: fmt.Sprintf("Select one explicitly: `automapper:\"field=%s\"`", candidates[0]),
+		})
+		return
+	}
+
+	if getter, isGetter := matcher.GetterName(sourceFieldName); isGetter && !exists {
+		message := fmt.Sprintf("Getter method '%s' not found", getter)
+		suggestion := fmt.Sprintf("Declare func (s %s) %s() T on the source struct", source.Name, getter)
+		if source.IsExternal && !ast.IsExported(getter) {
+			message = fmt.Sprintf("Getter method '%s' is unexported and %s is declared in another package", getter, source.Name)
+			suggestion = "Map the field from an exported getter or field"
+		}
+		result.Errors = append(result.Errors, ValidationError{
+			DTO:        dto.Name,
+			Source:     sourceName,
+			Field:      field.Name,
+			Message:    message,
+			Severity:   SeverityError,
+			Suggestion: suggestion,
+		})
+		return
+	}
 
 	if !exists {
 		// Check if it's intentionally unmapped
@@ -236,14 +732,18 @@ func (v *Validator) validateField(
 				Suggestion: "Check if field name is correct or remove mapping configuration",
 			})
 		} else {
-			result.Warnings = append(result.Warnings, ValidationError{
+			message := fmt.Sprintf("Source field '%s' not found, will use zero value", sourceFieldName)
+			if _, overridden := v.cfg.Severities[config.RuleMissingSource]; v.cfg.Strict && !overridden {
+				message = fmt.Sprintf("Source field '%s' not found (strict mode)", sourceFieldName)
+			}
+			v.addStrictWarning(result, ValidationError{
 				DTO:        dto.Name,
 				Source:     sourceName,
 				Field:      field.Name,
-				Message:    fmt.Sprintf("Source field '%s' not found, will use zero value", sourceFieldName),
-				Severity:   SeverityWarning,
+				Message:    message,
 				Fixable:    true,
 				Suggestion: "Add 'automapper:\"-\"' tag to explicitly ignore, or add source field",
+				Rule:       config.RuleMissingSource,
 			})
 		}
 		return
@@ -251,6 +751,32 @@ func (v *Validator) validateField(
 
 	logger.Debug("  Field %s: %s <- %s: %s", field.Name, field.Type, sourceFieldName, sourceField.Type)
 
+	// Nested value mappings take the address of the source field, which a getter result does not have
+	if _, isGetter := matcher.GetterName(sourceFieldName); isGetter && field.NestedDTO != "" &&
+		!sourceField.IsPointer && !sourceField.IsSlice {
+		result.Errors = append(result.Errors, ValidationError{
+			DTO:        dto.Name,
+			Source:     sourceName,
+			Field:      field.Name,
+			Message:    fmt.Sprintf("Getter %s returns a value and cannot be used for a nested DTO", sourceFieldName),
+			Severity:   SeverityError,
+			Suggestion: "Return a pointer from the getter or map the field with a converter",
+		})
+		return
+	}
+
+	// Validate redacted mapping
+	if field.Redact != "" {
+		v.validateRedaction(dto, sourceName, field, sourceField, result)
+		return
+	}
+
+	// Validate interface type switch mapping
+	if len(field.TypeCases) > 0 {
+		v.validateTypeCases(dto, source, sourceName, field, sourceField, result)
+		return
+	}
+
 	// Validate nested DTO mapping
 	if field.NestedDTO != "" {
 		v.validateNestedDTO(dto, sourceName, field, sourceField, result)
@@ -290,17 +816,21 @@ func (v *Validator) validateNestedDTO(
 		return
 	}
 
-	// Check for circular dependencies
+	// Check for circular dependencies, recursion that follows a self-referential source is allowed
 	if v.detectCircularDependency(dto.Name, nestedDTOName) {
-		result.Errors = append(result.Errors, ValidationError{
-			DTO:        dto.Name,
-			Source:     sourceName,
-			Field:      field.Name,
-			Message:    fmt.Sprintf("Circular dependency detected with %s", nestedDTOName),
-			Severity:   SeverityError,
-			Suggestion: "Remove circular references or use a converter instead",
-		})
-		return
+		if v.isLegalRecursion(sourceName, field, sourceField) {
+			logger.Debug("    Recursive nested DTO mapping: %s (max depth: %d)", nestedDTOName, v.cfg.MaxDepth)
+		} else {
+			result.Errors = append(result.Errors, ValidationError{
+				DTO:        dto.Name,
+				Source:     sourceName,
+				Field:      field.Name,
+				Message:    fmt.Sprintf("Circular dependency detected with %s", nestedDTOName),
+				Severity:   SeverityError,
+				Suggestion: "Recursive DTOs must mirror a self-referential source through pointer or slice fields",
+			})
+			return
+		}
 	}
 
 	// Validate slice compatibility
@@ -321,6 +851,71 @@ func (v *Validator) validateNestedDTO(
 	logger.Debug("    OK: Nested DTO mapping valid: %s", nestedDTOName)
 }
 
+// validateTypeCases validates type switch mappings of interface-typed source fields
+func (v *Validator) validateTypeCases(
+	dto types.DTOMapping,
+	source types.SourceStruct,
+	sourceName string,
+	field types.FieldInfo,
+	sourceField types.FieldTypeInfo,
+	result *ValidationResult,
+) {
+	if sourceField.IsPointer || sourceField.IsSlice || isBuiltinType(sourceField.Type) {
+		result.Errors = append(result.Errors, ValidationError{
+			DTO:        dto.Name,
+			Source:     sourceName,
+			Field:      field.Name,
+			Message:    fmt.Sprintf("Type cases require an interface-typed source field, got %s", sourceField.Type),
+			Severity:   SeverityError,
+			Suggestion: "Remove the types tag or map the field with a nested DTO",
+		})
+		return
+	}
+
+	for _, typeCase := range field.TypeCases {
+		if typeCase.Source == "" || typeCase.DTO == "" {
+			result.Errors = append(result.Errors, ValidationError{
+				DTO:        dto.Name,
+				Source:     sourceName,
+				Field:      field.Name,
+				Message:    "Malformed type case, expected SourceType:DTOType",
+				Severity:   SeverityError,
+				Suggestion: "Use `automapper:\"types=AdminDB:AdminDTO,GuestDB:GuestDTO\"`",
+			})
+			continue
+		}
+
+		caseSource := strings.TrimPrefix(typeCase.Source, "*")
+		if source.IsExternal && !strings.Contains(caseSource, ".") {
+			caseSource = source.Alias + "." + caseSource
+		}
+		if _, exists := v.sources[caseSource]; !exists {
+			result.Errors = append(result.Errors, ValidationError{
+				DTO:        dto.Name,
+				Source:     sourceName,
+				Field:      field.Name,
+				Message:    fmt.Sprintf("Type case source struct '%s' not found", caseSource),
+				Severity:   SeverityError,
+				Suggestion: fmt.Sprintf("Ensure %s is defined in the package or included in external packages", caseSource),
+			})
+		}
+
+		caseDTO := strings.TrimPrefix(typeCase.DTO, "*")
+		if _, exists := v.dtos[caseDTO]; !exists {
+			result.Errors = append(result.Errors, ValidationError{
+				DTO:        dto.Name,
+				Source:     sourceName,
+				Field:      field.Name,
+				Message:    fmt.Sprintf("Type case DTO '%s' not found", caseDTO),
+				Severity:   SeverityError,
+				Suggestion: fmt.Sprintf("Ensure %s is defined with automapper:from=%s", caseDTO, caseSource),
+			})
+		}
+	}
+
+	logger.Debug("    OK: Type switch mapping with %d cases", len(field.TypeCases))
+}
+
 // validateConverter validates converter-based mappings
 func (v *Validator) validateConverter(
 	dto types.DTOMapping,
@@ -337,6 +932,7 @@ func (v *Validator) validateConverter(
 		if conv.Name == converterName {
 			found = true
 			logger.Debug("    OK: Using registered converter: %s", converterName)
+			v.validateConverterTypeArgs(dto, sourceName, field, sourceField, conv, result)
 			break
 		}
 	}
@@ -356,9 +952,14 @@ func (v *Validator) validateConverter(
 	// Validate that types are compatible for conversion
 	srcBaseType := extractBaseType(sourceField.Type)
 	dstBaseType := extractBaseType(field.Type)
+	identical := srcBaseType == dstBaseType
+	if field.BaseIdentity.IsKnown() && sourceField.BaseIdentity.IsKnown() {
+		// Types of the same name may come from different packages
+		identical = field.BaseIdentity.Identical(sourceField.BaseIdentity)
+	}
 
 	// Warn if types are identical
-	if srcBaseType == dstBaseType {
+	if identical {
 		result.Warnings = append(result.Warnings, ValidationError{
 			DTO:        dto.Name,
 			Source:     sourceName,
@@ -367,8 +968,87 @@ func (v *Validator) validateConverter(
 			Severity:   SeverityWarning,
 			Fixable:    true,
 			Suggestion: "Remove converter tag for direct assignment or verify this is intentional",
+			Rule:       config.RuleRedundantConverter,
+		})
+	}
+}
+
+// validateConverterTypeArgs validates that the type parameters of a generic converter the call can't
+// infer are bound by the types of the fields
+func (v *Validator) validateConverterTypeArgs(
+	dto types.DTOMapping,
+	sourceName string,
+	field types.FieldInfo,
+	sourceField types.FieldTypeInfo,
+	conv config.ConverterDef,
+	result *ValidationResult,
+) {
+	fn, exists := v.functions[conv.Function]
+	if !exists || len(fn.TypeParams) == 0 {
+		return
+	}
+	argType := strings.TrimPrefix(sourceField.Type, "*")
+	resultType := strings.TrimPrefix(field.Type, "*")
+	if _, ok := parser.ConverterTypeArgs(fn, argType, resultType, false); !ok {
+		result.Errors = append(result.Errors, ValidationError{
+			DTO:      dto.Name,
+			Source:   sourceName,
+			Field:    field.Name,
+			Message:  fmt.Sprintf("Type parameters of generic converter '%s' can't be inferred for %s -> %s", conv.Name, argType, resultType),
+			Severity: SeverityError,
+			Suggestion: "Declare the type parameters the argument determines last, or wrap the converter in a " +
+				"non-generic function",
+		})
+	}
+}
+
+// validateRedaction validates that a redacted field maps a string with a known strategy
+func (v *Validator) validateRedaction(
+	dto types.DTOMapping,
+	sourceName string,
+	field types.FieldInfo,
+	sourceField types.FieldTypeInfo,
+	result *ValidationResult,
+) {
+	switch field.Redact {
+	case types.RedactFull, types.RedactMask4, types.RedactHash:
+	default:
+		result.Errors = append(result.Errors, ValidationError{
+			DTO:        dto.Name,
+			Source:     sourceName,
+			Field:      field.Name,
+			Message:    fmt.Sprintf("Unknown redaction strategy '%s'", field.Redact),
+			Severity:   SeverityError,
+			Suggestion: fmt.Sprintf("Use one of: %s, %s, %s", types.RedactFull, types.RedactMask4, types.RedactHash),
+		})
+		return
+	}
+
+	if field.ConverterTag != "" || field.NestedDTO != "" || len(field.TypeCases) > 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			DTO:        dto.Name,
+			Source:     sourceName,
+			Field:      field.Name,
+			Message:    "Redaction cannot be combined with converters or nested DTOs",
+			Severity:   SeverityError,
+			Suggestion: "Redact the value inside the converter instead",
+		})
+		return
+	}
+
+	if extractBaseType(field.Type) != "string" || sourceField.BaseType != "string" {
+		result.Errors = append(result.Errors, ValidationError{
+			DTO:        dto.Name,
+			Source:     sourceName,
+			Field:      field.Name,
+			Message:    fmt.Sprintf("Redaction requires string fields: %s <- %s", field.Type, sourceField.Type),
+			Severity:   SeverityError,
+			Suggestion: "Use a converter to redact non-string values",
 		})
+		return
 	}
+
+	logger.Debug("    OK: Redacted mapping valid: %s", field.Redact)
 }
 
 // validateDirectMapping validates direct field-to-field mappings
@@ -379,56 +1059,120 @@ func (v *Validator) validateDirectMapping(
 	sourceField types.FieldTypeInfo,
 	result *ValidationResult,
 ) {
-	// Extract base types
-	dtoBaseType := extractBaseType(field.Type)
-	srcBaseType := sourceField.BaseType
-
-	// Check if types are compatible
-	if !v.areTypesCompatible(dtoBaseType, srcBaseType) {
+	// Check if types are compatible, numeric types widening to the DTO type being cast when allowed
+	widening := types.NumericWidening(field, sourceField)
+	if !(widening && v.cfg.NumericWidening) && !v.areTypesCompatible(field, sourceField) {
+		suggestion := "Add converter tag: `automapper:\"converter=YourConverter\"`"
+		if widening {
+			suggestion = "Set \"allowNumericWidening\": true in automapper.json, or add converter tag: `automapper:\"converter=YourConverter\"`"
+		}
 		result.Errors = append(result.Errors, ValidationError{
 			DTO:        dto.Name,
 			Source:     sourceName,
 			Field:      field.Name,
-			Message:    fmt.Sprintf("Type mismatch: %s <- %s (cannot convert without converter)", field.Type, sourceField.Type),
+			Message:    typeMismatch(field, sourceField),
 			Severity:   SeverityError,
 			Fixable:    true,
-			Suggestion: "Add converter tag: `automapper:\"converter=YourConverter\"`",
+			Suggestion: suggestion,
 		})
 		return
 	}
 
-	// Warn about pointer conversions
+	// Widened fields aren't narrowed back by MapTo and ApplyTo, the values may not fit
+	if widening && (dto.HasMode(types.ModeBidirectional) || dto.HasMode(types.ModePatch)) {
+		v.addStrictWarning(result, ValidationError{
+			DTO:        dto.Name,
+			Source:     sourceName,
+			Field:      field.Name,
+			Message:    fmt.Sprintf("Widened field is not mapped back: %s <- %s", field.Type, sourceField.Type),
+			Suggestion: "Add a converter with an inverter to narrow the value back",
+			Rule:       config.RuleNotMappedBack,
+		})
+	}
+
+	// Report pointer conversions as the configured policy says
 	dtoIsPointer := strings.HasPrefix(field.Type, "*")
 	srcIsPointer := sourceField.IsPointer
 
 	if dtoIsPointer != srcIsPointer {
-		result.Warnings = append(result.Warnings, ValidationError{
+		policy := v.cfg.PointerConversions.FromPointer
+		if dtoIsPointer {
+			policy = v.cfg.PointerConversions.ToPointer
+		}
+		problem := ValidationError{
 			DTO:        dto.Name,
 			Source:     sourceName,
 			Field:      field.Name,
 			Message:    fmt.Sprintf("Pointer conversion: %s <- %s", field.Type, sourceField.Type),
-			Severity:   SeverityWarning,
-			Suggestion: "Verify this pointer conversion is intentional",
-		})
+			Suggestion: "Verify this pointer conversion is intentional, or allow it in pointerConversions",
+			Rule:       config.RulePointerConversion,
+		}
+		switch policy {
+		case config.PointerWarn:
+			v.addStrictWarning(result, problem)
+		case config.PointerError:
+			problem.Severity = SeverityError
+			result.Errors = append(result.Errors, problem)
+		}
 	}
 
 	logger.Debug("    OK: Direct mapping valid")
 }
 
-// resolveSourceFieldName determines the source field name
-func (v *Validator) resolveSourceFieldName(field types.FieldInfo) string {
-	if field.FieldTag != "" {
-		return field.FieldTag
+// applySeverities gives the findings of the rules in severities the configured severity, which
+// overrides strict mode and pointerConversions
+func (v *Validator) applySeverities(result *ValidationResult) {
+	if len(v.cfg.Severities) == 0 {
+		return
 	}
 
-	return field.Name
+	errs, warnings := []ValidationError{}, []ValidationError{}
+	for _, finding := range slices.Concat(result.Errors, result.Warnings) {
+		severity, ok := v.cfg.Severities[finding.Rule]
+		if !ok {
+			severity = string(finding.Severity)
+		}
+		switch severity {
+		case config.SeverityError:
+			finding.Severity = SeverityError
+			errs = append(errs, finding)
+		case config.SeverityWarning:
+			finding.Severity = SeverityWarning
+			warnings = append(warnings, finding)
+		case config.SeverityInfo:
+			finding.Severity = SeverityInfo
+			result.Info = append(result.Info, finding)
+		}
+	}
+	result.Errors, result.Warnings = errs, warnings
 }
 
-// areTypesCompatible checks if two types can be directly assigned
-func (v *Validator) areTypesCompatible(type1, type2 string) bool {
-	base1 := extractBaseType(type1)
-	base2 := extractBaseType(type2)
+// addStrictWarning records a problem the generated code can live with: a warning, or an error in
+// strict mode, where the generation fails fast instead of leaving zero values behind
+func (v *Validator) addStrictWarning(result *ValidationResult, problem ValidationError) {
+	if v.cfg.Strict {
+		problem.Severity = SeverityError
+		result.Errors = append(result.Errors, problem)
+		return
+	}
+	problem.Severity = SeverityWarning
+	result.Warnings = append(result.Warnings, problem)
+}
+
+// areTypesCompatible checks if the base types of a DTO field and its source field can be assigned,
+// or converted when they are distinct types of the same underlying type
+func (v *Validator) areTypesCompatible(field types.FieldInfo, sourceField types.FieldTypeInfo) bool {
+	// Type-checked fields compare the types themselves, seeing through aliases and package names
+	if field.BaseIdentity.IsKnown() && sourceField.BaseIdentity.IsKnown() {
+		return field.BaseIdentity.Identical(sourceField.BaseIdentity) || types.NeedsConversion(field, sourceField)
+	}
 
+	return typeNamesMatch(extractBaseType(field.Type), extractBaseType(sourceField.BaseType))
+}
+
+// typeNamesMatch reports whether two type names name the same type, one of them possibly qualified
+// by a package the other is written in
+func typeNamesMatch(base1, base2 string) bool {
 	if base1 == base2 {
 		return true
 	}
@@ -482,9 +1226,46 @@ func (v *Validator) canReach(from, to string, visited map[string]bool) bool {
 	return false
 }
 
+// isLegalRecursion reports whether a circular nested DTO reference follows the source structure,
+// i.e. the DTO field can be nil or empty and the source field refers to a source of the nested DTO
+func (v *Validator) isLegalRecursion(sourceName string, field types.FieldInfo, sourceField types.FieldTypeInfo) bool {
+	if !strings.HasPrefix(field.Type, "*") && !strings.HasPrefix(field.Type, "[]") {
+		return false
+	}
+
+	// Source field types are relative to the package of the source struct
+	nestedSource := strings.TrimPrefix(sourceField.BaseType, "*")
+	if !strings.Contains(nestedSource, ".") {
+		if idx := strings.LastIndex(sourceName, "."); idx != -1 {
+			nestedSource = sourceName[:idx+1] + nestedSource
+		}
+	}
+
+	return slices.Contains(v.dtos[field.NestedDTO].Sources, nestedSource)
+}
+
 // extractBaseType removes pointer and slice prefixes
 func extractBaseType(typeStr string) string {
 	typeStr = strings.TrimPrefix(typeStr, "*")
 	typeStr = strings.TrimPrefix(typeStr, "[]")
 	return typeStr
 }
+
+// typeNameWithoutPackage strips the package qualifier from a type name
+func typeNameWithoutPackage(typeName string) string {
+	if idx := strings.LastIndex(typeName, "."); idx != -1 {
+		return typeName[idx+1:]
+	}
+	return typeName
+}
+
+// isBuiltinType reports whether a type is a predeclared non-interface type
+func isBuiltinType(typeStr string) bool {
+	switch typeStr {
+	case "bool", "string", "byte", "rune", "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"float32", "float64", "complex64", "complex128":
+		return true
+	}
+	return false
+}